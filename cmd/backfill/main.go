@@ -0,0 +1,178 @@
+// backfill encrypt the email and name of the existing users and compute their blind index email_bidx,
+// it also encrypt the email of the oidc identities and remove the emails the audit logs of the deleted users kept.
+// it's also run after a key of APPLICATION.ENCRYPTION or APPLICATION.ENCRYPTION.BLIND_INDEX is rotated,
+// the rows of the previous key are re-encrypted and reindexed. the previous keys can be removed once it's done.
+//
+//	go run ./cmd/backfill -dry-run
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"golang-starter/infrastructures/db"
+	"golang-starter/internal/utils/encryption"
+	"golang-starter/src/modules/user/entities"
+	"golang-starter/src/modules/user/repositories"
+	"log"
+)
+
+// the detail the deleted users were audited with before their email was left out of it
+const auditEmailDetail = "email: %"
+
+type backfill struct {
+	mysql     *db.MysqlImpl
+	cipher    *repositories.UsersCipher
+	dryRun    bool
+	batchSize int
+}
+
+func main() {
+	dryRun := flag.Bool("dry-run", false, "only count the rows that need to be backfilled")
+	batchSize := flag.Int("batch-size", 500, "the rows read at once")
+	flag.Parse()
+
+	encrypter, err := encryption.LoadEncrypter()
+	if err != nil {
+		log.Fatalf("cannot load the encryption keys: %v", err)
+	}
+	blindIndexer, err := encryption.LoadBlindIndexer()
+	if err != nil {
+		log.Fatalf("cannot load the blind index keys: %v", err)
+	}
+
+	b := backfill{
+		mysql:     db.NewMysqlClient(),
+		cipher:    repositories.NewUsersCipher(encrypter, blindIndexer),
+		dryRun:    *dryRun,
+		batchSize: *batchSize,
+	}
+	ctx := context.Background()
+	b.users(ctx)
+	b.identities(ctx)
+	b.auditLogs(ctx)
+}
+
+func (b backfill) users(ctx context.Context) {
+	// the stored users are read as is, the encrypted command encrypts them with the active keys
+	query := repositories.NewRepoUsersQuery(b.mysql.DB)
+	command := repositories.NewRepoUsersEncryptedCommand(repositories.NewRepoUsersCommand(b.mysql.DB), b.cipher)
+	fields := repositories.NewUsersSelectFields()
+
+	var total, backfilled, failed int
+	var lastUserId int32
+	for {
+		usersList, err := query.
+			FilterUsers(repositories.NewUsersFilter("AND").SetFilterByUserId(lastUserId, ">")).
+			OrderByUsers([]repositories.Order{repositories.NewUsersUserIdOrder().SetDirection("ASC")}).
+			PaginationUsers(repositories.PaginationData{Page: 1, Size: b.batchSize}).
+			GetUsersList(ctx)
+		if err != nil {
+			log.Fatalf("cannot read the users after %d: %v", lastUserId, err)
+		}
+		if len(usersList) == 0 {
+			break
+		}
+
+		for _, user := range usersList {
+			total++
+			lastUserId = user.UserId
+			if !b.cipher.NeedsBackfill(user) {
+				continue
+			}
+			if b.dryRun {
+				backfilled++
+				continue
+			}
+
+			if err := b.cipher.DecryptUsers(user); err != nil {
+				log.Println(err)
+				failed++
+				continue
+			}
+			if err := command.UpdateUsers(ctx, user, user.UserId, fields.Email(), fields.Name()); err != nil {
+				log.Fatalf("cannot backfill user %d: %v", user.UserId, err)
+			}
+			backfilled++
+		}
+	}
+
+	if b.dryRun {
+		fmt.Printf("%d of %d users need to be backfilled\n", backfilled, total)
+		return
+	}
+	fmt.Printf("%d of %d users are backfilled, %d cannot be decrypted\n", backfilled, total, failed)
+}
+
+func (b backfill) identities(ctx context.Context) {
+	query := repositories.NewRepoUserIdentitiesQuery(b.mysql.DB)
+	command := repositories.NewRepoUserIdentitiesEncryptedCommand(repositories.NewRepoUserIdentitiesCommand(b.mysql.DB), b.cipher)
+	fields := repositories.NewUserIdentitiesSelectFields()
+
+	var total, backfilled, failed int
+	var lastIdentityId int32
+	for {
+		identities, err := query.
+			FilterUserIdentities(repositories.NewUserIdentitiesFilter("AND").SetFilterByIdentityId(lastIdentityId, ">")).
+			OrderByUserIdentities([]repositories.Order{repositories.NewUserIdentitiesIdentityIdOrder().SetDirection("ASC")}).
+			PaginationUserIdentities(repositories.PaginationData{Page: 1, Size: b.batchSize}).
+			GetUserIdentitiesList(ctx)
+		if err != nil {
+			log.Fatalf("cannot read the identities after %d: %v", lastIdentityId, err)
+		}
+		if len(identities) == 0 {
+			break
+		}
+
+		for _, identity := range identities {
+			total++
+			lastIdentityId = identity.IdentityId
+			if !b.cipher.NeedsIdentityBackfill(identity) {
+				continue
+			}
+			if b.dryRun {
+				backfilled++
+				continue
+			}
+
+			if err := b.cipher.DecryptUserIdentities(identity); err != nil {
+				log.Println(err)
+				failed++
+				continue
+			}
+			if err := command.UpdateUserIdentities(ctx, identity, identity.IdentityId, fields.Email()); err != nil {
+				log.Fatalf("cannot backfill identity %d: %v", identity.IdentityId, err)
+			}
+			backfilled++
+		}
+	}
+
+	if b.dryRun {
+		fmt.Printf("%d of %d identities need to be backfilled\n", backfilled, total)
+		return
+	}
+	fmt.Printf("%d of %d identities are backfilled, %d cannot be decrypted\n", backfilled, total, failed)
+}
+
+// auditLogs clear the detail of the deleted users, it was their email in plaintext
+func (b backfill) auditLogs(ctx context.Context) {
+	filter := repositories.NewUserAuditLogsFilter("AND").
+		SetFilterByAction(entities.AuditActionUserDelete, "=").
+		SetFilterByDetail(auditEmailDetail, "LIKE")
+
+	count, err := repositories.NewRepoUserAuditLogsQuery(b.mysql.DB).FilterUserAuditLogs(filter).GetUserAuditLogsCount(ctx)
+	if err != nil {
+		log.Fatalf("cannot count the audit logs: %v", err)
+	}
+	if b.dryRun {
+		fmt.Printf("%d audit logs have an email to remove\n", count)
+		return
+	}
+
+	err = repositories.NewRepoUserAuditLogsCommand(b.mysql.DB).
+		UpdateUserAuditLogsByFilter(ctx, &entities.UserAuditLogs{}, filter, repositories.NewUserAuditLogsSelectFields().Detail())
+	if err != nil {
+		log.Fatalf("cannot remove the emails of the audit logs: %v", err)
+	}
+	fmt.Printf("the email of %d audit logs is removed\n", count)
+}
@@ -0,0 +1,120 @@
+// createadmin create an admin or promote the existing user of the email to admin, the schema doesn't seed any admin.
+// the password of a new admin is ADMIN_PASSWORD, a random one is printed when it's empty and it must be changed
+// on the first login. the password of an existing user isn't changed.
+//
+//	ADMIN_PASSWORD=... go run ./cmd/createadmin -email admin@example.com
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"flag"
+	"fmt"
+	"golang-starter/infrastructures/db"
+	"golang-starter/internal/utils/encryption"
+	"golang-starter/internal/utils/password"
+	"golang-starter/src/modules/user/entities"
+	"golang-starter/src/modules/user/repositories"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/guregu/null"
+)
+
+func main() {
+	email := flag.String("email", "", "the email of the admin")
+	username := flag.String("username", "", "the username of a new admin, default is the local part of the email")
+	name := flag.String("name", "", "the name of a new admin, default is the username")
+	flag.Parse()
+
+	if *email == "" {
+		log.Fatalln("-email is required")
+	}
+
+	encrypter, err := encryption.LoadEncrypter()
+	if err != nil {
+		log.Fatalf("cannot load the encryption keys: %v", err)
+	}
+	blindIndexer, err := encryption.LoadBlindIndexer()
+	if err != nil {
+		log.Fatalf("cannot load the blind index keys: %v", err)
+	}
+	repository := repositories.NewRepository(db.NewMysqlClient(), encrypter, blindIndexer)
+
+	ctx := context.Background()
+	now := time.Now().UnixNano() / int64(time.Millisecond)
+	fields := repositories.NewUsersSelectFields()
+
+	user, err := repository.
+		FilterUsers(repositories.NewUsersFilter("AND").SetFilterByEmail(*email, "=")).
+		GetUsers(ctx)
+	if err == nil {
+		user.Role = entities.RoleAdmin
+		user.UpdatedAt = null.IntFrom(now)
+		if err := repository.UpdateUsers(ctx, user, user.UserId, fields.Role(), fields.UpdatedAt()); err != nil {
+			log.Fatalf("cannot promote user %d: %v", user.UserId, err)
+		}
+		fmt.Printf("user %d is promoted to admin\n", user.UserId)
+		return
+	}
+	if !errors.Is(err, repositories.ErrNotFound) {
+		log.Fatalf("cannot read the user: %v", err)
+	}
+
+	if *username == "" {
+		*username = strings.SplitN(*email, "@", 2)[0]
+	}
+	if *name == "" {
+		*name = *username
+	}
+
+	plainPassword := os.Getenv("ADMIN_PASSWORD")
+	generated := plainPassword == ""
+	if generated {
+		if plainPassword, err = randomPassword(); err != nil {
+			log.Fatalf("cannot generate the password: %v", err)
+		}
+	}
+	hashedPassword, err := password.NewHasher().Hash(plainPassword)
+	if err != nil {
+		log.Fatalf("cannot hash the password: %v", err)
+	}
+
+	user = &entities.Users{
+		Email:     *email,
+		Username:  *username,
+		Name:      *name,
+		Password:  hashedPassword,
+		Role:      entities.RoleAdmin,
+		CreatedAt: now,
+	}
+	if generated {
+		// the printed password is only temporary
+		user.PasswordResetAt = null.IntFrom(now)
+	}
+	res, err := repository.InsertUsers(ctx, user)
+	if err != nil {
+		log.Fatalf("cannot create the admin: %v", err)
+	}
+	userId, err := res.LastInsertId()
+	if err != nil {
+		log.Fatalf("cannot read the id of the admin: %v", err)
+	}
+
+	fmt.Printf("admin %d is created\n", userId)
+	if generated {
+		fmt.Printf("its temporary password is %s\n", plainPassword)
+	}
+}
+
+func randomPassword() (string, error) {
+	b := make([]byte, 18)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
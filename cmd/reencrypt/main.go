@@ -0,0 +1,92 @@
+// reencrypt re-encrypt the stored refresh tokens with the active key of APPLICATION.ENCRYPTION.
+// the legacy AES-CFB ciphertext is decrypted with APPLICATION.KEY.DEFAULT, the envelope of a rotated key
+// with its own key. run it after a new key is made active, the old key can be removed once it's done.
+//
+//	go run ./cmd/reencrypt -dry-run
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"golang-starter/config"
+	"golang-starter/infrastructures/cached"
+	"golang-starter/infrastructures/db"
+	"golang-starter/infrastructures/localdb"
+	"golang-starter/internal/utils/auth"
+	"golang-starter/internal/utils/auth/dto"
+	"golang-starter/internal/utils/encryption"
+	"log"
+)
+
+func main() {
+	dryRun := flag.Bool("dry-run", false, "only count the refresh tokens that need to be re-encrypted")
+	flag.Parse()
+
+	cfg := config.Get()
+	encrypter, err := encryption.LoadEncrypter()
+	if err != nil {
+		log.Fatalf("cannot load the encryption keys: %v", err)
+	}
+
+	var store auth.TokenStore
+	switch cfg.Auth.RefreshToken.Store {
+	case "redis":
+		redis := cached.NewRedisClient()
+		if !redis.Enabled() {
+			log.Fatalln("refresh token store is redis but redis is disabled")
+		}
+		defer redis.Close()
+		store = auth.NewRedisTokenStore(redis)
+	case "mysql":
+		store = auth.NewMysqlTokenStore(db.NewMysqlClient())
+	default:
+		store = auth.NewScribbleTokenStore(localdb.NewScribleClient())
+	}
+
+	ctx := context.Background()
+	var total, reencrypted, failed int
+	err = store.EachRefreshToken(ctx, func(userId, sessionId string, refreshToken dto.RefreshToken) error {
+		total++
+		if !encrypter.NeedsReencrypt(refreshToken.RefreshToken) {
+			return nil
+		}
+		if *dryRun {
+			reencrypted++
+			return nil
+		}
+
+		var plaintext string
+		var err error
+		if encryption.IsEnvelope(refreshToken.RefreshToken) {
+			plaintext, err = encrypter.Decrypt(refreshToken.RefreshToken)
+		} else {
+			plaintext, err = encryption.DecryptLegacyCFB(refreshToken.RefreshToken, cfg.Application.Key.Default)
+		}
+		if err != nil {
+			// the session can't be refreshed anyway, it's left for the user to login again
+			log.Printf("cannot decrypt the refresh token of user %s session %s: %v", userId, sessionId, err)
+			failed++
+			return nil
+		}
+
+		refreshToken.RefreshToken, err = encrypter.Encrypt(plaintext)
+		if err != nil {
+			return err
+		}
+		if err := store.SaveRefreshToken(ctx, userId, sessionId, refreshToken); err != nil {
+			return err
+		}
+		reencrypted++
+		return nil
+	})
+	if err != nil {
+		log.Fatalf("cannot re-encrypt the refresh tokens: %v", err)
+	}
+
+	if *dryRun {
+		fmt.Printf("%d of %d refresh tokens need to be re-encrypted\n", reencrypted, total)
+		return
+	}
+	fmt.Printf("%d of %d refresh tokens are re-encrypted, %d cannot be decrypted\n", reencrypted, total, failed)
+}
@@ -0,0 +1,60 @@
+// rotatekey add a new jwt signing key of AUTH.JWT_TOKEN.ALGORITHM to the keyring of APPLICATION.KEY.KEYRING.
+// the current active key is retired, it keeps verifying the tokens that it signed until it's pruned
+// by a later rotation, after the retain duration. send SIGHUP to the server to load the new keyring.
+//
+//	go run ./cmd/rotatekey -retain 720h
+package main
+
+import (
+	"flag"
+	"fmt"
+	"golang-starter/config"
+	"golang-starter/internal/utils/keyring"
+	"log"
+	"os"
+	"time"
+)
+
+func main() {
+	retain := flag.Duration("retain", 0, "how long a retired key keeps verifying, default is AUTH.JWT_TOKEN.REFRESH_EXPIRED")
+	flag.Parse()
+
+	cfg := config.Get()
+	path := cfg.Application.Key.Keyring
+	if path == "" {
+		log.Fatalln("APPLICATION.KEY.KEYRING is not configured")
+	}
+
+	if *retain == 0 {
+		refreshExpired, err := time.ParseDuration(cfg.Auth.JwtToken.RefreshExpired)
+		if err != nil {
+			log.Fatalf("invalid AUTH.JWT_TOKEN.REFRESH_EXPIRED: %v", err)
+		}
+		*retain = refreshExpired
+	}
+
+	alg := keyring.SigningAlgorithm()
+	ring, err := keyring.ReadKeyringFile(path)
+	if os.IsNotExist(err) {
+		// the first rotation start from the configured key, so the tokens it signed stay valid
+		ring, err = keyring.LoadKeyring()
+		if err != nil {
+			log.Printf("the configured key is not loaded, the keyring start empty: %v", err)
+			ring, err = &keyring.Keyring{}, nil
+		}
+	}
+	if err != nil {
+		log.Fatalf("cannot load the keyring: %v", err)
+	}
+
+	key, err := ring.Rotate(alg, time.Now(), *retain)
+	if err != nil {
+		log.Fatalf("cannot generate a new key: %v", err)
+	}
+
+	if err := ring.WriteFile(path); err != nil {
+		log.Fatalf("cannot write the keyring: %v", err)
+	}
+
+	fmt.Printf("new %s signing key %s is added to %s, the keyring has %d keys\n", alg, key.Kid, path, len(ring.Keys))
+}
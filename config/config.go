@@ -9,24 +9,86 @@ import (
 	"github.com/spf13/viper"
 )
 
-var cfg Config
-var doOnce sync.Once
+var (
+	cfg    Config
+	mu     sync.RWMutex
+	doOnce sync.Once
+
+	listenersMu sync.Mutex
+	listeners   []func(Config)
+)
 
 type Config struct {
 	Application struct {
 		Port int `mapstructure:"PORT"`
-		Log  struct {
+		// Env is development or production, production hides the internal details of the errors
+		Env string `mapstructure:"ENV"`
+		// Debug render the stack of a recovered panic in the problem response, it's ignored in production
+		Debug bool `mapstructure:"DEBUG"`
+		// ProblemTypeUrl prefix the type of the problem responses, the type is about:blank without it
+		ProblemTypeUrl string `mapstructure:"PROBLEM_TYPE_URL"`
+		// TrustedProxies is the ip or CIDR of the load balancers and proxies in front of the application,
+		// the client ip is only read from X-Forwarded-For and X-Real-IP when the request comes from one
+		TrustedProxies []string `mapstructure:"TRUSTED_PROXIES"`
+		Log            struct {
 			Path string `mapstructure:"PATH"`
 		}
 		Key struct {
 			Default string `mapstructure:"DEFAULT"`
+			// Keyring is the path of the keyring file that is written by cmd/rotatekey
+			Keyring string `mapstructure:"KEYRING"`
+			Hmac    string `mapstructure:"HMAC"`
 			Rsa     struct {
 				Public  string `mapstructure:"PUBLIC"`
 				Private string `mapstructure:"PRIVATE"`
 			}
+			Ecdsa struct {
+				Private string `mapstructure:"PRIVATE"`
+			} `mapstructure:"ECDSA"`
+			Ed25519 struct {
+				Private string `mapstructure:"PRIVATE"`
+			} `mapstructure:"ED25519"`
 		}
+		// Encryption encrypt the stored secrets, ACTIVE is the key id that encrypts and every key of KEYS decrypts.
+		// the key ids are lowercase since the map keys are
+		Encryption struct {
+			Active string            `mapstructure:"ACTIVE"`
+			Keys   map[string]string `mapstructure:"KEYS"`
+			// BlindIndex is the HMAC keys of the blind index of the encrypted columns that are looked up
+			BlindIndex struct {
+				Active string            `mapstructure:"ACTIVE"`
+				Keys   map[string]string `mapstructure:"KEYS"`
+			} `mapstructure:"BLIND_INDEX"`
+		} `mapstructure:"ENCRYPTION"`
+		// Server is the timeouts of the http server, a timeout of 0 is no timeout
+		Server struct {
+			ReadTimeout       time.Duration `mapstructure:"READ_TIMEOUT"`
+			ReadHeaderTimeout time.Duration `mapstructure:"READ_HEADER_TIMEOUT"`
+			WriteTimeout      time.Duration `mapstructure:"WRITE_TIMEOUT"`
+			IdleTimeout       time.Duration `mapstructure:"IDLE_TIMEOUT"`
+		} `mapstructure:"SERVER"`
+		// Tls serve https with the certificate of CERT_FILE, it's read again when its files change.
+		// MIN_VERSION is 1.2 or 1.3 and CIPHER_SUITES only apply to 1.2
+		Tls struct {
+			Enabled        bool          `mapstructure:"ENABLED"`
+			CertFile       string        `mapstructure:"CERT_FILE"`
+			KeyFile        string        `mapstructure:"KEY_FILE"`
+			ReloadInterval time.Duration `mapstructure:"RELOAD_INTERVAL"`
+			MinVersion     string        `mapstructure:"MIN_VERSION"`
+			CipherSuites   []string      `mapstructure:"CIPHER_SUITES"`
+			DisableHttp2   bool          `mapstructure:"DISABLE_HTTP2"`
+			// ClientAuth verify the client certificates against the CAs of CA_FILE, the routes under
+			// the prefixes of ROUTES require one
+			ClientAuth struct {
+				CaFile string   `mapstructure:"CA_FILE"`
+				Routes []string `mapstructure:"ROUTES"`
+			} `mapstructure:"CLIENT_AUTH"`
+		} `mapstructure:"TLS"`
 		Graceful struct {
 			MaxSecond time.Duration `mapstructure:"MAX_SECOND"`
+			// DrainDelay is how long the readiness fails before the server shuts down,
+			// so the load balancers stop sending requests first. it must be shorter than MAX_SECOND
+			DrainDelay time.Duration `mapstructure:"DRAIN_DELAY"`
 		} `mapstructure:"GRACEFUL"`
 	} `mapstructure:"APPLICATION"`
 
@@ -35,9 +97,97 @@ type Config struct {
 			Type           string `mapstructure:"TYPE"`
 			Expired        string `mapstructure:"EXPIRED"`
 			RefreshExpired string `mapstructure:"REFRESH_EXPIRED"`
+			// Algorithm sign the new tokens, AcceptedAlgorithms are verified and default to Algorithm
+			Algorithm          string   `mapstructure:"ALGORITHM"`
+			AcceptedAlgorithms []string `mapstructure:"ACCEPTED_ALGORITHMS"`
+			// Issuer and Audience are set on the new tokens and required on the verified ones
+			Issuer   string        `mapstructure:"ISSUER"`
+			Audience []string      `mapstructure:"AUDIENCE"`
+			Leeway   time.Duration `mapstructure:"LEEWAY"`
 		} `mapstructure:"JWT_TOKEN"`
+		// RefreshToken keep the refresh tokens, STORE is scribble, redis or mysql
+		RefreshToken struct {
+			Store string `mapstructure:"STORE"`
+		} `mapstructure:"REFRESH_TOKEN"`
+		// TokenDenylist keep the revoked tokens, STORE is memory or redis
+		TokenDenylist struct {
+			Store string `mapstructure:"STORE"`
+		} `mapstructure:"TOKEN_DENYLIST"`
+		LoginAttempt struct {
+			Store         string        `mapstructure:"STORE"`
+			MaxFailures   int64         `mapstructure:"MAX_FAILURES"`
+			FailureWindow time.Duration `mapstructure:"FAILURE_WINDOW"`
+			Lockout       time.Duration `mapstructure:"LOCKOUT"`
+			Delay         time.Duration `mapstructure:"DELAY"`
+			MaxDelay      time.Duration `mapstructure:"MAX_DELAY"`
+			IpMaxAttempts int64         `mapstructure:"IP_MAX_ATTEMPTS"`
+			IpWindow      time.Duration `mapstructure:"IP_WINDOW"`
+		} `mapstructure:"LOGIN_ATTEMPT"`
+		Oidc struct {
+			StateExpired time.Duration                 `mapstructure:"STATE_EXPIRED"`
+			StateStore   string                        `mapstructure:"STATE_STORE"`
+			Providers    map[string]OidcProviderConfig `mapstructure:"PROVIDERS"`
+		} `mapstructure:"OIDC"`
+		Password struct {
+			Algorithm string `mapstructure:"ALGORITHM"`
+			Bcrypt    struct {
+				Cost int `mapstructure:"COST"`
+			} `mapstructure:"BCRYPT"`
+			Argon2id struct {
+				Memory      uint32 `mapstructure:"MEMORY"`
+				Iterations  uint32 `mapstructure:"ITERATIONS"`
+				Parallelism uint8  `mapstructure:"PARALLELISM"`
+				SaltLength  uint32 `mapstructure:"SALT_LENGTH"`
+				KeyLength   uint32 `mapstructure:"KEY_LENGTH"`
+			} `mapstructure:"ARGON2ID"`
+		} `mapstructure:"PASSWORD"`
 	} `mapstructure:"AUTH"`
 
+	// Cors is the policy of the cross origin requests of the browsers, each group of GROUPS replace it
+	// for the routes under its PREFIX
+	Cors struct {
+		CorsPolicy `mapstructure:",squash"`
+		Groups     []CorsGroup `mapstructure:"GROUPS"`
+	} `mapstructure:"CORS"`
+
+	// Health is the /healthz and /readyz probes, TIMEOUT limit each check of the readiness
+	Health struct {
+		Timeout time.Duration `mapstructure:"TIMEOUT"`
+	} `mapstructure:"HEALTH"`
+
+	// Metrics serve the prometheus metrics on PATH, PORT serve them on an admin port instead of the application one
+	Metrics struct {
+		Enabled bool   `mapstructure:"ENABLED"`
+		Path    string `mapstructure:"PATH"`
+		Port    int    `mapstructure:"PORT"`
+	} `mapstructure:"METRICS"`
+
+	// Tracing export the spans of the requests, the services and the sql statements, EXPORTER is otlp, stdout or none.
+	// SAMPLE_RATIO is the share of the new traces that are exported, they are all exported when it is unset
+	Tracing struct {
+		Exporter    string  `mapstructure:"EXPORTER"`
+		ServiceName string  `mapstructure:"SERVICE_NAME"`
+		SampleRatio float64 `mapstructure:"SAMPLE_RATIO"`
+		// Otlp post the spans to ENDPOINT/v1/traces with the json encoding, HEADERS are added to every request
+		Otlp struct {
+			Endpoint string            `mapstructure:"ENDPOINT"`
+			Headers  map[string]string `mapstructure:"HEADERS"`
+			Timeout  time.Duration     `mapstructure:"TIMEOUT"`
+		} `mapstructure:"OTLP"`
+	} `mapstructure:"TRACING"`
+
+	// RateLimit limit the requests of a client with a token bucket, STORE is memory or redis.
+	// the policy is the default one of every route, ROUTES override it for a route.
+	// AUTH limit the requests of an ip before they're authenticated, so the rejected tokens and api keys
+	// are limited too. its KEY is always ip
+	RateLimit struct {
+		Enabled         bool   `mapstructure:"ENABLED"`
+		Store           string `mapstructure:"STORE"`
+		RateLimitPolicy `mapstructure:",squash"`
+		Routes          []RateLimitRoute `mapstructure:"ROUTES"`
+		Auth            RateLimitPolicy  `mapstructure:"AUTH"`
+	} `mapstructure:"RATE_LIMIT"`
+
 	DB struct {
 		Mysql struct {
 			Host string `mapstructure:"HOST"`
@@ -50,28 +200,123 @@ type Config struct {
 
 	Cache struct {
 		Redis struct {
-			Host string `mapstructure:"HOST"`
-			Port int    `mapstructure:"PORT"`
-			DB   int    `mapstructure:"DB"`
-			Pass string `mapstructure:"PASS"`
+			Enabled bool   `mapstructure:"ENABLED"`
+			Host    string `mapstructure:"HOST"`
+			Port    int    `mapstructure:"PORT"`
+			DB      int    `mapstructure:"DB"`
+			Pass    string `mapstructure:"PASS"`
 		}
 	}
 }
 
-func Get() Config {
-	viper.AddConfigPath(".")
-	viper.AutomaticEnv()
-	err := viper.ReadInConfig()
-	if err != nil {
-		log.Fatalln(fmt.Sprintf("cannot read .env file: %v", err))
-	}
+type OidcProviderConfig struct {
+	Issuer       string   `mapstructure:"ISSUER"`
+	ClientID     string   `mapstructure:"CLIENT_ID"`
+	ClientSecret string   `mapstructure:"CLIENT_SECRET"`
+	RedirectURL  string   `mapstructure:"REDIRECT_URL"`
+	Scopes       []string `mapstructure:"SCOPES"`
+}
+
+// CorsPolicy allow the origins of ALLOWED_ORIGINS, an origin like https://*.example.com allow the subdomains
+// of example.com and * allow every origin. no origin is allowed when it's empty
+type CorsPolicy struct {
+	AllowedOrigins   []string      `mapstructure:"ALLOWED_ORIGINS"`
+	AllowedMethods   []string      `mapstructure:"ALLOWED_METHODS"`
+	AllowedHeaders   []string      `mapstructure:"ALLOWED_HEADERS"`
+	ExposedHeaders   []string      `mapstructure:"EXPOSED_HEADERS"`
+	AllowCredentials bool          `mapstructure:"ALLOW_CREDENTIALS"`
+	MaxAge           time.Duration `mapstructure:"MAX_AGE"`
+}
+
+// CorsGroup is the policy of the routes under PREFIX like /admin, it doesn't inherit from the default policy
+type CorsGroup struct {
+	Prefix     string `mapstructure:"PREFIX"`
+	CorsPolicy `mapstructure:",squash"`
+}
+
+// RateLimitPolicy is REQUESTS every PERIOD per client with bursts of up to BURST requests, BURST default to REQUESTS.
+// KEY is ip, user or api_key, user and api_key fall back to the ip of an anonymous request
+type RateLimitPolicy struct {
+	Key      string        `mapstructure:"KEY"`
+	Requests int           `mapstructure:"REQUESTS"`
+	Period   time.Duration `mapstructure:"PERIOD"`
+	Burst    int           `mapstructure:"BURST"`
+}
 
+// RateLimitRoute is the policy of a route, PATTERN is the chi pattern like /users/{userId}
+type RateLimitRoute struct {
+	Method          string `mapstructure:"METHOD"`
+	Pattern         string `mapstructure:"PATTERN"`
+	RateLimitPolicy `mapstructure:",squash"`
+}
+
+// Get return the config, the file is only read on the first call
+func Get() Config {
 	doOnce.Do(func() {
-		err := viper.Unmarshal(&cfg)
-		if err != nil {
-			log.Fatalln("cannot unmarshaling config")
+		if err := load(); err != nil {
+			log.Fatalln(err)
 		}
 	})
 
+	mu.RLock()
+	defer mu.RUnlock()
 	return cfg
 }
+
+// Reload read the config file again, the current config is kept when the file is not valid
+func Reload() error {
+	doOnce.Do(func() {})
+	if err := load(); err != nil {
+		return err
+	}
+	notifyReload()
+	return nil
+}
+
+// Set replace the config without reading the file, the tests use it instead of a config file
+func Set(newCfg Config) {
+	doOnce.Do(func() {})
+
+	mu.Lock()
+	cfg = newCfg
+	mu.Unlock()
+	notifyReload()
+}
+
+// OnReload call fn with the new config whenever it's reloaded or set, what's built from the config at startup
+// is built again there instead of comparing the config on every use
+func OnReload(fn func(Config)) {
+	listenersMu.Lock()
+	defer listenersMu.Unlock()
+	listeners = append(listeners, fn)
+}
+
+func notifyReload() {
+	listenersMu.Lock()
+	fns := append([]func(Config){}, listeners...)
+	listenersMu.Unlock()
+
+	newCfg := Get()
+	for _, fn := range fns {
+		fn(newCfg)
+	}
+}
+
+func load() error {
+	v := viper.New()
+	v.AddConfigPath(".")
+	v.AutomaticEnv()
+	if err := v.ReadInConfig(); err != nil {
+		return fmt.Errorf("cannot read config file: %v", err)
+	}
+
+	var newCfg Config
+	if err := v.Unmarshal(&newCfg); err != nil {
+		return fmt.Errorf("cannot unmarshaling config: %v", err)
+	}
+
+	mu.Lock()
+	cfg = newCfg
+	mu.Unlock()
+	return nil
+}
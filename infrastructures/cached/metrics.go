@@ -0,0 +1,76 @@
+package cached
+
+import (
+	"context"
+	"golang-starter/internal/utils/metrics"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+var (
+	redisCommands = metrics.NewCounter("redis_commands_total", "the redis commands by their result", "command", "result")
+	redisDuration = metrics.NewHistogram("redis_command_duration_seconds", "the latency of the redis commands", nil, "command")
+)
+
+type redisStartKey struct{}
+
+// metricsHook record every command of the client, a pipeline is recorded as a single pipeline command
+type metricsHook struct{}
+
+func (metricsHook) BeforeProcess(ctx context.Context, cmd redis.Cmder) (context.Context, error) {
+	return context.WithValue(ctx, redisStartKey{}, time.Now()), nil
+}
+
+func (metricsHook) AfterProcess(ctx context.Context, cmd redis.Cmder) error {
+	observeRedis(ctx, cmd.Name(), cmd.Err())
+	return nil
+}
+
+func (metricsHook) BeforeProcessPipeline(ctx context.Context, cmds []redis.Cmder) (context.Context, error) {
+	return context.WithValue(ctx, redisStartKey{}, time.Now()), nil
+}
+
+func (metricsHook) AfterProcessPipeline(ctx context.Context, cmds []redis.Cmder) error {
+	var err error
+	for _, cmd := range cmds {
+		if cmd.Err() != nil && cmd.Err() != redis.Nil {
+			err = cmd.Err()
+			break
+		}
+	}
+	observeRedis(ctx, "pipeline", err)
+	return nil
+}
+
+// observeRedis record a command, a missing key is not an error
+func observeRedis(ctx context.Context, command string, err error) {
+	result := "success"
+	if err != nil && err != redis.Nil {
+		result = "error"
+	}
+	redisCommands.Inc(command, result)
+
+	if start, ok := ctx.Value(redisStartKey{}).(time.Time); ok {
+		redisDuration.ObserveSince(start, command)
+	}
+}
+
+// registerPoolStats expose the stats of the connection pool, they're read when the metrics are collected
+func registerPoolStats(rdb *redis.Client) {
+	metrics.NewCounterFunc("redis_pool_hits_total", "the free connections found in the pool", func() float64 {
+		return float64(rdb.PoolStats().Hits)
+	})
+	metrics.NewCounterFunc("redis_pool_misses_total", "the free connections not found in the pool", func() float64 {
+		return float64(rdb.PoolStats().Misses)
+	})
+	metrics.NewCounterFunc("redis_pool_timeouts_total", "the waits for a connection that timed out", func() float64 {
+		return float64(rdb.PoolStats().Timeouts)
+	})
+	metrics.NewGaugeFunc("redis_pool_total_connections", "the connections of the pool", func() float64 {
+		return float64(rdb.PoolStats().TotalConns)
+	})
+	metrics.NewGaugeFunc("redis_pool_idle_connections", "the idle connections of the pool", func() float64 {
+		return float64(rdb.PoolStats().IdleConns)
+	})
+}
@@ -1,6 +1,7 @@
 package cached
 
 import (
+	"context"
 	"fmt"
 	"golang-starter/config"
 	"log"
@@ -19,14 +20,22 @@ type RedisImpl struct {
 }
 
 func NewRedisClient() *RedisImpl {
+	if !config.Get().Cache.Redis.Enabled {
+		log.Println("Redis is disabled")
+		return &RedisImpl{}
+	}
+
 	log.Println("Initialize Redis connection")
-	host := fmt.Sprintf("%s:%s", config.Get().Cache.Redis.Host, config.Get().Cache.Redis.Port)
+	host := fmt.Sprintf("%s:%d", config.Get().Cache.Redis.Host, config.Get().Cache.Redis.Port)
 	rdb := redis.NewClient(&redis.Options{
 		Addr:     host,
 		Password: config.Get().Cache.Redis.Pass, // no password set
 		DB:       config.Get().Cache.Redis.DB,   // use default DB
 	})
 
+	rdb.AddHook(metricsHook{})
+	registerPoolStats(rdb)
+
 	ctx := rdb.Context()
 	ping, err := rdb.Ping(ctx).Result()
 	if err != nil {
@@ -45,6 +54,19 @@ func NewRedisClient() *RedisImpl {
 	}
 }
 
+// Enabled reports whether redis is configured, when it's false DB and Cache return nil
+func (c RedisImpl) Enabled() bool {
+	return c.db != nil
+}
+
+// Ping check the connection to redis, it's always healthy when redis is disabled
+func (c RedisImpl) Ping(ctx context.Context) error {
+	if c.db == nil {
+		return nil
+	}
+	return c.db.Ping(ctx).Err()
+}
+
 func (c RedisImpl) DB() *redis.Client {
 	return c.db
 }
@@ -54,5 +76,8 @@ func (c RedisImpl) Cache() *cache.Cache {
 }
 
 func (c RedisImpl) Close() error {
+	if c.db == nil {
+		return nil
+	}
 	return c.db.Close()
 }
@@ -0,0 +1,56 @@
+package db
+
+import (
+	"database/sql"
+	"golang-starter/internal/utils/metrics"
+	"strings"
+	"time"
+)
+
+var queryDuration = metrics.NewHistogram("db_query_duration_seconds", "the latency of the statements by operation and table", nil, "operation", "table")
+
+// observeStatement record the latency of a statement, the table is the one it reads from or writes to
+func observeStatement(start time.Time, operation, query string) {
+	queryDuration.ObserveSince(start, operation, statementTable(query))
+}
+
+// statementTable return the table after the first FROM, INTO or UPDATE of query, it's empty for a statement
+// without one like SELECT 1
+func statementTable(query string) string {
+	words := strings.Fields(query)
+	for i := 0; i+1 < len(words); i++ {
+		switch strings.ToUpper(words[i]) {
+		case "FROM", "INTO", "UPDATE":
+			return strings.Trim(strings.SplitN(words[i+1], "(", 2)[0], "`,;")
+		}
+	}
+	return ""
+}
+
+// registerPoolStats expose the stats of the connection pool, they're read when the metrics are collected
+func registerPoolStats(db *sql.DB) {
+	metrics.NewGaugeFunc("db_max_open_connections", "the maximum open connections of the pool", func() float64 {
+		return float64(db.Stats().MaxOpenConnections)
+	})
+	metrics.NewGaugeFunc("db_open_connections", "the established connections both in use and idle", func() float64 {
+		return float64(db.Stats().OpenConnections)
+	})
+	metrics.NewGaugeFunc("db_in_use_connections", "the connections currently in use", func() float64 {
+		return float64(db.Stats().InUse)
+	})
+	metrics.NewGaugeFunc("db_idle_connections", "the idle connections", func() float64 {
+		return float64(db.Stats().Idle)
+	})
+	metrics.NewCounterFunc("db_wait_count_total", "the connections waited for", func() float64 {
+		return float64(db.Stats().WaitCount)
+	})
+	metrics.NewCounterFunc("db_wait_duration_seconds_total", "the time blocked waiting for a new connection", func() float64 {
+		return db.Stats().WaitDuration.Seconds()
+	})
+	metrics.NewCounterFunc("db_max_idle_closed_total", "the connections closed due to the maximum idle connections", func() float64 {
+		return float64(db.Stats().MaxIdleClosed)
+	})
+	metrics.NewCounterFunc("db_max_lifetime_closed_total", "the connections closed due to the maximum lifetime", func() float64 {
+		return float64(db.Stats().MaxLifetimeClosed)
+	})
+}
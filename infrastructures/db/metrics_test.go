@@ -0,0 +1,26 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatementTable(t *testing.T) {
+	tests := []struct {
+		query string
+		table string
+	}{
+		{"SELECT user_id,email FROM users WHERE user_id = ?", "users"},
+		{"SELECT count(1) FROM api_keys", "api_keys"},
+		{"INSERT INTO user_audit_logs (user_fkid,\n\taction) VALUES (?, ?)", "user_audit_logs"},
+		{"INSERT INTO products(name) VALUES (?)", "products"},
+		{"UPDATE users \n\t\t\tSET name = ? \n\t\t\tWHERE user_id = ?", "users"},
+		{"delete from `refresh_tokens` where session_id = ?", "refresh_tokens"},
+		{"SELECT 1", ""},
+		{"FROM", ""},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.table, statementTable(tt.query), tt.query)
+	}
+}
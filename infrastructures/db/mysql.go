@@ -1,10 +1,12 @@
 package db
 
 import (
+	"context"
+	"database/sql"
 	"fmt"
 	"golang-starter/config"
 
-	_ "github.com/go-sql-driver/mysql"
+	"github.com/go-sql-driver/mysql"
 	"github.com/jmoiron/sqlx"
 	"github.com/nurcahyaari/sqlabst"
 	"github.com/rs/zerolog/log"
@@ -26,7 +28,7 @@ func NewMysqlClient() *MysqlImpl {
 
 	sHost := fmt.Sprintf("%s:%s@tcp(%s:%d)/%s", dbUser, dbPass, dbHost, dbPort, dbName)
 
-	db, err := sqlx.Connect("mysql", sHost)
+	db, err := connect(sHost, dbName)
 
 	if err != nil {
 		log.Err(err).Msgf("Error to loading Database %s", err)
@@ -34,7 +36,32 @@ func NewMysqlClient() *MysqlImpl {
 	}
 
 	log.Info().Str("Name", dbName).Msg("Success connect to DB")
+	registerPoolStats(db.DB)
 	return &MysqlImpl{
 		DB: sqlabst.NewSqlAbst(db),
 	}
 }
+
+// connect open the database with the statements traced, sqlx still binds them like mysql
+func connect(dsn, dbName string) (*sqlx.DB, error) {
+	mysqlConfig, err := mysql.ParseDSN(dsn)
+	if err != nil {
+		return nil, err
+	}
+	connector, err := mysql.NewConnector(mysqlConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	db := sqlx.NewDb(sql.OpenDB(tracedConnector{Connector: connector, dbName: dbName}), "mysql")
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// Ping check the connection to the database
+func (m MysqlImpl) Ping(ctx context.Context) error {
+	return m.DB.GetDB().PingContext(ctx)
+}
@@ -0,0 +1,183 @@
+package db
+
+import (
+	"context"
+	"database/sql/driver"
+	"golang-starter/internal/utils/tracing"
+	"strings"
+	"time"
+)
+
+// tracedConnector open the connections that trace their statements, a statement is a child span of the span
+// of its context and only its sql is recorded, never the arguments. a statement out of a trace isn't traced
+type tracedConnector struct {
+	driver.Connector
+	dbName string
+}
+
+func (c tracedConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &tracedConn{Conn: conn, dbName: c.dbName}, nil
+}
+
+func (c tracedConnector) Driver() driver.Driver {
+	return c.Connector.Driver()
+}
+
+// traceStatement record a statement once it's done, a skipped statement is run again prepared so it isn't recorded.
+// its latency is observed even out of a trace
+func traceStatement(ctx context.Context, dbName string, start time.Time, query string, err error) {
+	if err == driver.ErrSkip {
+		return
+	}
+
+	operation := strings.ToUpper(strings.SplitN(strings.TrimSpace(query), " ", 2)[0])
+	observeStatement(start, operation, query)
+	if tracing.SpanFromContext(ctx) == nil {
+		return
+	}
+
+	_, span := tracing.Start(ctx, "mysql "+operation, tracing.WithKind(tracing.KindClient), tracing.WithStart(start))
+	span.SetAttributes(
+		tracing.String("db.system", "mysql"),
+		tracing.String("db.name", dbName),
+		tracing.String("db.operation", operation),
+		tracing.String("db.statement", query),
+	)
+	span.RecordError(err)
+	span.End()
+}
+
+// tracedConn forward to the mysql connection, it has to implement every optional interface of the driver
+// so database/sql doesn't fall back to a slower path
+type tracedConn struct {
+	driver.Conn
+	dbName string
+}
+
+func (c *tracedConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	var (
+		stmt driver.Stmt
+		err  error
+	)
+	if preparer, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		stmt, err = preparer.PrepareContext(ctx, query)
+	} else {
+		stmt, err = c.Conn.Prepare(query)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &tracedStmt{Stmt: stmt, query: query, dbName: c.dbName}, nil
+}
+
+func (c *tracedConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	execer, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	start := time.Now()
+	res, err := execer.ExecContext(ctx, query, args)
+	traceStatement(ctx, c.dbName, start, query, err)
+	return res, err
+}
+
+func (c *tracedConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	queryer, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	start := time.Now()
+	rows, err := queryer.QueryContext(ctx, query, args)
+	traceStatement(ctx, c.dbName, start, query, err)
+	return rows, err
+}
+
+func (c *tracedConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if beginner, ok := c.Conn.(driver.ConnBeginTx); ok {
+		return beginner.BeginTx(ctx, opts)
+	}
+	return c.Conn.Begin()
+}
+
+func (c *tracedConn) Ping(ctx context.Context) error {
+	if pinger, ok := c.Conn.(driver.Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	return nil
+}
+
+func (c *tracedConn) ResetSession(ctx context.Context) error {
+	if resetter, ok := c.Conn.(driver.SessionResetter); ok {
+		return resetter.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (c *tracedConn) IsValid() bool {
+	if validator, ok := c.Conn.(driver.Validator); ok {
+		return validator.IsValid()
+	}
+	return true
+}
+
+func (c *tracedConn) CheckNamedValue(nv *driver.NamedValue) error {
+	if checker, ok := c.Conn.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+type tracedStmt struct {
+	driver.Stmt
+	query  string
+	dbName string
+}
+
+func (s *tracedStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	start := time.Now()
+	var (
+		res driver.Result
+		err error
+	)
+	if execer, ok := s.Stmt.(driver.StmtExecContext); ok {
+		res, err = execer.ExecContext(ctx, args)
+	} else {
+		res, err = s.Stmt.Exec(namedValues(args))
+	}
+	traceStatement(ctx, s.dbName, start, s.query, err)
+	return res, err
+}
+
+func (s *tracedStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	start := time.Now()
+	var (
+		rows driver.Rows
+		err  error
+	)
+	if queryer, ok := s.Stmt.(driver.StmtQueryContext); ok {
+		rows, err = queryer.QueryContext(ctx, args)
+	} else {
+		rows, err = s.Stmt.Query(namedValues(args))
+	}
+	traceStatement(ctx, s.dbName, start, s.query, err)
+	return rows, err
+}
+
+func (s *tracedStmt) CheckNamedValue(nv *driver.NamedValue) error {
+	if checker, ok := s.Stmt.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+func namedValues(args []driver.NamedValue) []driver.Value {
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		values[i] = arg.Value
+	}
+	return values
+}
@@ -1,26 +1,51 @@
 package localdb
 
 import (
+	"io/ioutil"
 	"log"
+	"os"
 
 	scribble "github.com/nanobox-io/golang-scribble"
 )
 
+const scribleDir = "tmp/db"
+
 type ScribleImpl struct {
-	db *scribble.Driver
+	db  *scribble.Driver
+	dir string
 }
 
 func NewScribleClient() *ScribleImpl {
-	db, err := scribble.New("tmp/db", nil)
+	db, err := scribble.New(scribleDir, nil)
 	if err != nil {
 		log.Println("Error", err)
 	}
 
 	return &ScribleImpl{
-		db: db,
+		db:  db,
+		dir: scribleDir,
 	}
 }
 
+// Dir return the directory of the database, scribble doesn't expose a way to list the resources of a collection
+func (db ScribleImpl) Dir() string {
+	return db.dir
+}
+
 func (db ScribleImpl) DB() *scribble.Driver {
 	return db.db
 }
+
+// Writable check that a file can be written in the directory of the database, the file is removed right after
+func (db ScribleImpl) Writable() error {
+	if err := os.MkdirAll(db.dir, 0755); err != nil {
+		return err
+	}
+
+	file, err := ioutil.TempFile(db.dir, ".writable-")
+	if err != nil {
+		return err
+	}
+	file.Close()
+	return os.Remove(file.Name())
+}
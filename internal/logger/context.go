@@ -0,0 +1,30 @@
+package logger
+
+import (
+	"context"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+// Ctx return the request logger of ctx, it's the global logger outside of a request
+func Ctx(ctx context.Context) *zerolog.Logger {
+	// a logger is only stored in the context when it's enabled, so a disabled one is the missing one
+	l := zerolog.Ctx(ctx)
+	if l.GetLevel() == zerolog.Disabled {
+		return &log.Logger
+	}
+	return l
+}
+
+// SetUserId add the user id to the request logger of ctx, so every line after the authentication
+// including the access log has it. it does nothing outside of a request
+func SetUserId(ctx context.Context, userId uint) {
+	l := zerolog.Ctx(ctx)
+	if l.GetLevel() == zerolog.Disabled {
+		return
+	}
+	l.UpdateContext(func(c zerolog.Context) zerolog.Context {
+		return c.Uint("user_id", userId)
+	})
+}
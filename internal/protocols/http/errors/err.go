@@ -1,53 +1,152 @@
 package errors
 
 import (
-	"fmt"
+	stderrors "errors"
 	"net/http"
-	"regexp"
+	"time"
 )
 
+// RespError is the error of a response, it's rendered as the problem details of RFC 7807.
+// Code is the http status and ErrorCode is the stable code the clients match on, like PRODUCT_NOT_FOUND
 type RespError struct {
-	Code    int
-	Message string
+	Code      int
+	ErrorCode string
+	Message   string
+	// RetryAfter is sent as the Retry-After header when it's set
+	RetryAfter time.Duration
+	// Err is the cause, it's logged but never rendered in production
+	Err error
+	// InvalidParams is the fields of the request that aren't valid
+	InvalidParams []InvalidParam
+	// Stack is the stack of a recovered panic, it's logged and only rendered in debug mode
+	Stack string
 }
 
-func (r *RespError) Error() string {
-	return fmt.Sprintf("%d: %d", r.Code, r.Message)
+// InvalidParam is a field of the request that isn't valid, Rule is the validation rule it breaks
+type InvalidParam struct {
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+	Rule   string `json:"rule,omitempty"`
 }
 
-func InternalServerError(msg string) error {
+// the generic errors, a domain declares its own with NewRespError
+var (
+	ErrBadRequest      = NewRespError(http.StatusBadRequest, "BAD_REQUEST", "the request is not valid")
+	ErrUnauthorized    = NewRespError(http.StatusUnauthorized, "UNAUTHORIZED", "authentication is required")
+	ErrForbidden       = NewRespError(http.StatusForbidden, "FORBIDDEN", "you don't have permission to access this resource")
+	ErrNotFound        = NewRespError(http.StatusNotFound, "NOT_FOUND", "the resource is not found")
+	ErrConflict        = NewRespError(http.StatusConflict, "CONFLICT", "the resource already exists")
+	ErrValidation      = NewRespError(http.StatusUnprocessableEntity, "VALIDATION_FAILED", "the request has invalid fields")
+	ErrTooManyRequests = NewRespError(http.StatusTooManyRequests, "TOO_MANY_REQUESTS", "too many requests, try again later")
+	ErrInternal        = NewRespError(http.StatusInternalServerError, "INTERNAL_ERROR", "internal server error")
+)
+
+// the errors of the bearer token, they're shared by the middlewares and the services
+var (
+	ErrTokenInvalid = NewRespError(http.StatusUnauthorized, "TOKEN_INVALID", "token is not valid")
+	ErrTokenExpired = NewRespError(http.StatusUnauthorized, "TOKEN_EXPIRED", "token is expired")
+	ErrTokenRevoked = NewRespError(http.StatusUnauthorized, "TOKEN_REVOKED", "token is revoked")
+)
+
+// ErrClientCertRequired is the error of an internal route that is called without a verified client certificate
+var ErrClientCertRequired = NewRespError(http.StatusForbidden, "CLIENT_CERT_REQUIRED", "a verified client certificate is required")
+
+// NewRespError declare the error of an http status and a stable error code
+func NewRespError(code int, errorCode, message string) *RespError {
 	return &RespError{
-		Code:    http.StatusInternalServerError,
-		Message: msg,
+		Code:      code,
+		ErrorCode: errorCode,
+		Message:   message,
 	}
 }
 
-func BadRequest(msg string) error {
-	return &RespError{
-		Code:    http.StatusBadRequest,
-		Message: msg,
+func (r *RespError) Error() string {
+	if r.Err != nil {
+		return r.Message + ": " + r.Err.Error()
 	}
+	return r.Message
+}
+
+func (r *RespError) Unwrap() error {
+	return r.Err
+}
+
+// Is match the errors of the same error code, so errors.Is(err, ErrNotFound) holds for
+// the copies made by WithMessage and Wrap
+func (r *RespError) Is(target error) bool {
+	t, ok := target.(*RespError)
+	return ok && r.ErrorCode != "" && t.ErrorCode == r.ErrorCode
+}
+
+// WithMessage return a copy of r with the message of this occurrence
+func (r *RespError) WithMessage(msg string) *RespError {
+	copied := *r
+	copied.Message = msg
+	return &copied
+}
+
+// Wrap return a copy of r that is caused by err
+func (r *RespError) Wrap(err error) *RespError {
+	copied := *r
+	copied.Err = err
+	return &copied
+}
+
+// WithInvalidParams return a copy of r with the fields that aren't valid
+func (r *RespError) WithInvalidParams(params []InvalidParam) *RespError {
+	copied := *r
+	copied.InvalidParams = params
+	return &copied
+}
+
+// WithStack return a copy of r with the stack of the panic it's recovered from
+func (r *RespError) WithStack(stack string) *RespError {
+	copied := *r
+	copied.Stack = stack
+	return &copied
+}
+
+// WithRetryAfter return a copy of r that tells the client when to retry
+func (r *RespError) WithRetryAfter(retryAfter time.Duration) *RespError {
+	copied := *r
+	copied.RetryAfter = retryAfter
+	return &copied
+}
+
+func InternalServerError(msg string) error {
+	return ErrInternal.WithMessage(msg)
+}
+
+// Internal wrap an unexpected error, its message is only logged and rendered outside of production
+func Internal(err error) error {
+	return ErrInternal.Wrap(err)
+}
+
+func BadRequest(msg string) error {
+	return ErrBadRequest.WithMessage(msg)
 }
 
 func NotFound(msg string) error {
-	return &RespError{
-		Code:    http.StatusNotFound,
-		Message: msg,
-	}
+	return ErrNotFound.WithMessage(msg)
 }
 
 func Unauthorization(msg string) error {
-	return &RespError{
-		Code:    http.StatusUnauthorized,
-		Message: msg,
-	}
+	return ErrUnauthorized.WithMessage(msg)
 }
 
-func FindErrorType(err error) error {
-	re := regexp.MustCompile(`not found.?`)
-	if re.FindString(err.Error()) != "" {
-		return NotFound(err.Error())
-	}
+func Forbidden(msg string) error {
+	return ErrForbidden.WithMessage(msg)
+}
+
+func TooManyRequests(msg string, retryAfter time.Duration) error {
+	return ErrTooManyRequests.WithMessage(msg).WithRetryAfter(retryAfter)
+}
+
+// Is and As are the ones of the standard errors, this package shadows its name
+func Is(err, target error) bool {
+	return stderrors.Is(err, target)
+}
 
-	return InternalServerError(err.Error())
+func As(err error, target interface{}) bool {
+	return stderrors.As(err, target)
 }
@@ -2,10 +2,15 @@ package http
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 	"golang-starter/config"
 	"golang-starter/internal/protocols/http/router"
+	"golang-starter/internal/utils/health"
+	"golang-starter/internal/utils/metrics"
+	"golang-starter/internal/utils/tlsconfig"
 	"net/http"
+	"time"
 
 	"github.com/rs/zerolog/log"
 
@@ -13,15 +18,20 @@ import (
 )
 
 type HttpImpl struct {
-	HttpRouter *router.HttpRouterImpl
-	httpServer *http.Server
+	HttpRouter  *router.HttpRouterImpl
+	checker     *health.Checker
+	httpServer  *http.Server
+	adminServer *http.Server
+	certs       *tlsconfig.Reloader
 }
 
 func NewHttpProtocol(
 	HttpRouter *router.HttpRouterImpl,
+	checker *health.Checker,
 ) *HttpImpl {
 	return &HttpImpl{
 		HttpRouter: HttpRouter,
+		checker:    checker,
 	}
 }
 
@@ -34,19 +44,131 @@ func (p *HttpImpl) Listen() {
 
 	p.setupRouter(app)
 
+	if err := p.setupTls(); err != nil {
+		log.Fatal().Err(err).Msg("cannot setup tls")
+	}
+
 	serverPort := fmt.Sprintf(":%d", config.Get().Application.Port)
-	p.httpServer = &http.Server{
-		Addr:    serverPort,
-		Handler: app,
+	p.httpServer = p.newServer(serverPort, app)
+
+	p.listenAdmin()
+
+	log.Info().Bool("tls", p.certs != nil).Msgf("Server started on Port %s ", serverPort)
+	if err := p.serve(p.httpServer); err != nil && err != http.ErrServerClosed {
+		log.Fatal().Err(err).Msg("server stopped")
 	}
+}
 
-	log.Info().Msgf("Server started on Port %s ", serverPort)
-	p.httpServer.ListenAndServe()
+// setupTls load the certificate of APPLICATION.TLS, the server is plain http when it's disabled
+func (p *HttpImpl) setupTls() error {
+	cfg := config.Get().Application.Tls
+	if !cfg.Enabled {
+		return nil
+	}
+
+	reloadInterval := cfg.ReloadInterval
+	if reloadInterval <= 0 {
+		reloadInterval = time.Minute
+	}
+	certs, err := tlsconfig.NewReloader(tlsconfig.Options{
+		CertFile:     cfg.CertFile,
+		KeyFile:      cfg.KeyFile,
+		ClientCaFile: cfg.ClientAuth.CaFile,
+		MinVersion:   cfg.MinVersion,
+		CipherSuites: cfg.CipherSuites,
+		Http2:        !cfg.DisableHttp2,
+	}, reloadInterval)
+	if err != nil {
+		return err
+	}
+	if _, err := certs.ServerConfig(); err != nil {
+		certs.Close()
+		return err
+	}
+	p.certs = certs
+	return nil
+}
+
+// newServer apply the timeouts of APPLICATION.SERVER and the tls, if any
+func (p *HttpImpl) newServer(addr string, handler http.Handler) *http.Server {
+	cfg := config.Get().Application.Server
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadTimeout:       cfg.ReadTimeout,
+		ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+		WriteTimeout:      cfg.WriteTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
+	}
+	if p.certs == nil {
+		return server
+	}
+
+	// the options are checked by setupTls
+	server.TLSConfig, _ = p.certs.ServerConfig()
+	if config.Get().Application.Tls.DisableHttp2 {
+		// a non nil map stops net/http from setting up http/2
+		server.TLSNextProto = map[string]func(*http.Server, *tls.Conn, http.Handler){}
+	}
+	return server
+}
+
+func (p *HttpImpl) serve(server *http.Server) error {
+	if p.certs != nil {
+		// the certificate comes from the tls config, so the files aren't given here
+		return server.ListenAndServeTLS("", "")
+	}
+	return server.ListenAndServe()
+}
+
+// listenAdmin serve the metrics on their own port, so they aren't exposed with the application
+func (p *HttpImpl) listenAdmin() {
+	cfg := config.Get().Metrics
+	if !cfg.Enabled || cfg.Port == 0 {
+		return
+	}
+
+	admin := chi.NewRouter()
+	admin.Get(router.MetricsPath(), metrics.Handler().ServeHTTP)
+
+	adminPort := fmt.Sprintf(":%d", cfg.Port)
+	p.adminServer = p.newServer(adminPort, admin)
+
+	go func() {
+		log.Info().Msgf("Admin server started on Port %s ", adminPort)
+		if err := p.serve(p.adminServer); err != nil && err != http.ErrServerClosed {
+			log.Err(err).Msg("admin server stopped")
+		}
+	}()
+}
+
+// Drain fail the readiness then wait for APPLICATION.GRACEFUL.DRAIN_DELAY, so the load balancers
+// stop sending requests before Shutdown closes the listener
+func (p *HttpImpl) Drain(ctx context.Context) {
+	p.checker.Drain()
+
+	delay := config.Get().Application.Graceful.DrainDelay
+	if delay <= 0 {
+		return
+	}
+	log.Info().Msgf("Draining for %s", delay)
+	select {
+	case <-time.After(delay):
+	case <-ctx.Done():
+	}
 }
 
 func (p *HttpImpl) Shutdown(ctx context.Context) error {
+	if p.adminServer != nil {
+		if err := p.adminServer.Shutdown(ctx); err != nil {
+			return err
+		}
+	}
 	if err := p.httpServer.Shutdown(ctx); err != nil {
 		return err
 	}
+	if p.certs != nil {
+		p.certs.Close()
+	}
 	return nil
 }
@@ -0,0 +1,79 @@
+package middleware
+
+import (
+	"net/http"
+
+	"golang-starter/internal/logger"
+	"golang-starter/internal/protocols/http/errors"
+	httpresponse "golang-starter/internal/protocols/http/response"
+	"golang-starter/internal/utils/auth"
+)
+
+// ApiKeyHeader is the header that machine to machine clients send their api key in
+const ApiKeyHeader = "X-API-Key"
+
+// JwtOrApiKey verify the api key when X-API-Key is sent, otherwise it falls back to JwtVerifyToken.
+// both set the principal of the caller, hence the handlers don't need to know how the caller is authenticated
+func JwtOrApiKey(verifier auth.TokenVerifier, authenticator auth.ApiKeyAuthenticator) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		jwtNext := jwtVerifyToken(verifier, next)
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			apiKey := r.Header.Get(ApiKeyHeader)
+			if apiKey == "" {
+				jwtNext.ServeHTTP(w, r)
+				return
+			}
+
+			identity, err := authenticator.AuthenticateApiKey(r.Context(), apiKey)
+			if err != nil {
+				httpresponse.Err(w, r, err)
+				return
+			}
+
+			principal := auth.NewApiKeyPrincipal(identity)
+			logger.SetUserId(r.Context(), principal.UserId)
+			next.ServeHTTP(w, r.WithContext(auth.ContextWithPrincipal(r.Context(), principal)))
+		})
+	}
+}
+
+// RequireScope only let an api key through when it's granted one of scopes, a jwt caller isn't limited by scopes.
+// it must be chained after JwtOrApiKey
+func RequireScope(scopes ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal, ok := auth.PrincipalFromContext(r.Context())
+			if ok && principal.HasScope(scopes...) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			httpresponse.Err(w, r, errors.ErrForbidden.WithMessage("the api key doesn't have the scope to access this resource"))
+		})
+	}
+}
+
+// RequireApiKeyScopeOrRole let an api key through when it's granted scope and a jwt caller when it has one of roles,
+// it's for the routes that are meant for machine to machine clients and the operators. it must be chained after JwtOrApiKey
+func RequireApiKeyScopeOrRole(scope string, roles ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal, ok := auth.PrincipalFromContext(r.Context())
+			if !ok {
+				httpresponse.Err(w, r, errors.ErrForbidden)
+				return
+			}
+
+			allowed := principal.HasRole(roles...)
+			if principal.Method == auth.AuthMethodApiKey {
+				allowed = principal.HasScope(scope)
+			}
+			if !allowed {
+				httpresponse.Err(w, r, errors.ErrForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"context"
+	"golang-starter/internal/protocols/http/errors"
+	"golang-starter/internal/utils/auth"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeVerifier accept the access tokens named after the role of their user
+type fakeVerifier struct{}
+
+func (fakeVerifier) Verify(_ context.Context, tokenString, tokenType string) (*auth.Claims, error) {
+	if tokenType != auth.TokenTypeAccess {
+		return nil, auth.ErrTokenType
+	}
+	return &auth.Claims{Subject: "1", Role: tokenString, SessionID: "session", TokenType: tokenType}, nil
+}
+
+func (fakeVerifier) RevokeToken(context.Context, *auth.Claims) error { return nil }
+
+func (fakeVerifier) RevokeSessions(context.Context, ...string) error { return nil }
+
+// fakeAuthenticator accept the api keys named after their scope, their owner is an admin
+type fakeAuthenticator struct{}
+
+func (fakeAuthenticator) AuthenticateApiKey(_ context.Context, apiKey string) (*auth.ApiKeyIdentity, error) {
+	if apiKey == "invalid" {
+		return nil, errors.ErrUnauthorized
+	}
+	return &auth.ApiKeyIdentity{ApiKeyId: 1, UserId: 1, Role: "admin", Scopes: []string{apiKey}}, nil
+}
+
+func TestRequireApiKeyScopeOrRole(t *testing.T) {
+	handler := JwtOrApiKey(fakeVerifier{}, fakeAuthenticator{})(
+		RequireApiKeyScopeOrRole("tokens:introspect", "admin")(okHandler))
+
+	tests := []struct {
+		name   string
+		header string
+		value  string
+		status int
+	}{
+		{name: "user jwt", header: "Authorization", value: "Bearer user", status: http.StatusForbidden},
+		{name: "admin jwt", header: "Authorization", value: "Bearer admin", status: http.StatusOK},
+		{name: "api key with the scope", header: ApiKeyHeader, value: "tokens:introspect", status: http.StatusOK},
+		{name: "api key of an admin without the scope", header: ApiKeyHeader, value: "users:read", status: http.StatusForbidden},
+		{name: "invalid api key", header: ApiKeyHeader, value: "invalid", status: http.StatusUnauthorized},
+		{name: "anonymous", status: http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/oauth/introspect", nil)
+			if tt.header != "" {
+				r.Header.Set(tt.header, tt.value)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+			assert.Equal(t, tt.status, w.Code)
+		})
+	}
+}
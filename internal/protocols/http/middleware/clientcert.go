@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"golang-starter/config"
+	"golang-starter/internal/protocols/http/errors"
+	httpresponse "golang-starter/internal/protocols/http/response"
+	"golang-starter/internal/utils/auth"
+)
+
+// ClientCert put the client of a verified certificate in the request context, it's read by auth.ClientIdentityFromContext.
+// the routes under the prefixes of APPLICATION.TLS.CLIENT_AUTH.ROUTES are rejected without one
+func ClientCert(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// the chains are only there when the certificate is verified against the client CAs
+		if r.TLS != nil && len(r.TLS.VerifiedChains) > 0 && len(r.TLS.VerifiedChains[0]) > 0 {
+			identity := auth.NewClientIdentity(r.TLS.VerifiedChains[0][0])
+			next.ServeHTTP(w, r.WithContext(auth.ContextWithClientIdentity(r.Context(), identity)))
+			return
+		}
+
+		if requiresClientCert(config.Get().Application.Tls.ClientAuth.Routes, r.URL.Path) {
+			httpresponse.Err(w, r, errors.ErrClientCertRequired)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func requiresClientCert(prefixes []string, path string) bool {
+	for _, prefix := range prefixes {
+		prefix = strings.TrimRight(prefix, "/")
+		if prefix == "" || path == prefix || strings.HasPrefix(path, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
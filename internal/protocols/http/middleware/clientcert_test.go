@@ -0,0 +1,84 @@
+package middleware
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"golang-starter/config"
+	"golang-starter/internal/utils/auth"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func setClientCertRoutes(routes ...string) {
+	var cfg config.Config
+	cfg.Application.Tls.ClientAuth.Routes = routes
+	config.Set(cfg)
+}
+
+func TestClientCertRequired(t *testing.T) {
+	setClientCertRoutes("/internal/")
+	defer config.Set(config.Config{})
+
+	tests := []struct {
+		name   string
+		path   string
+		tls    *tls.ConnectionState
+		status int
+	}{
+		{"no tls on a protected prefix", "/internal/jobs", nil, http.StatusForbidden},
+		{"protected prefix itself", "/internal", nil, http.StatusForbidden},
+		// the certificate is only in the chains once it's verified against the client CAs
+		{"unverified certificate", "/internal/jobs", &tls.ConnectionState{
+			PeerCertificates: []*x509.Certificate{{SerialNumber: big.NewInt(1)}},
+		}, http.StatusForbidden},
+		{"unprotected prefix", "/internalx", nil, http.StatusOK},
+		{"unprotected route", "/users", nil, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			r.TLS = tt.tls
+			w := httptest.NewRecorder()
+
+			ClientCert(okHandler).ServeHTTP(w, r)
+			assert.Equal(t, tt.status, w.Code)
+		})
+	}
+}
+
+func TestClientCertIdentity(t *testing.T) {
+	setClientCertRoutes("/internal")
+	defer config.Set(config.Config{})
+
+	cert := &x509.Certificate{
+		Raw:          []byte("certificate"),
+		SerialNumber: big.NewInt(42),
+		Subject:      pkix.Name{CommonName: "billing", Organization: []string{"acme"}},
+		DNSNames:     []string{"billing.internal"},
+	}
+	r := httptest.NewRequest(http.MethodGet, "/internal/jobs", nil)
+	r.TLS = &tls.ConnectionState{
+		PeerCertificates: []*x509.Certificate{cert},
+		VerifiedChains:   [][]*x509.Certificate{{cert}},
+	}
+	w := httptest.NewRecorder()
+
+	var identity *auth.ClientIdentity
+	ClientCert(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		identity, _ = auth.ClientIdentityFromContext(r.Context())
+		w.WriteHeader(http.StatusOK)
+	})).ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	if assert.NotNil(t, identity) {
+		assert.Equal(t, "billing", identity.CommonName)
+		assert.Equal(t, []string{"acme"}, identity.Organization)
+		assert.Equal(t, []string{"billing.internal"}, identity.DNSNames)
+		assert.Equal(t, "42", identity.SerialNumber)
+	}
+}
@@ -0,0 +1,184 @@
+package middleware
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"golang-starter/config"
+	"golang-starter/internal/logger"
+	"golang-starter/internal/utils/metrics"
+
+	"github.com/go-chi/cors"
+	"github.com/rs/zerolog/log"
+)
+
+var corsPreflightRejectedTotal = metrics.NewCounter("http_cors_preflight_rejected_total",
+	"the preflights rejected by the cors policy of their group", "group")
+
+// the group of the routes that aren't under the prefix of a group
+const defaultCorsGroup = "default"
+
+type corsPolicy struct {
+	group   string
+	prefix  string
+	handler func(next http.Handler) http.Handler
+}
+
+// Cors apply the CORS policy of the group of the route, a group is chosen by the longest PREFIX the path is under.
+// the policies are built again once the config is reloaded, so they change without a restart.
+// a rejected preflight is logged and answered with a 403
+func Cors() func(http.Handler) http.Handler {
+	var mu sync.RWMutex
+	cfg := config.Get().Cors
+	policies := newCorsPolicies(cfg.CorsPolicy, cfg.Groups)
+
+	config.OnReload(func(newCfg config.Config) {
+		newPolicies := newCorsPolicies(newCfg.Cors.CorsPolicy, newCfg.Cors.Groups)
+		mu.Lock()
+		policies = newPolicies
+		mu.Unlock()
+	})
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.RLock()
+			policy := corsPolicyOf(policies, r.URL.Path)
+			mu.RUnlock()
+			policy.handler(next).ServeHTTP(w, r)
+		})
+	}
+}
+
+// newCorsPolicies sort the groups by their prefix, the longest first, and end with the default policy
+func newCorsPolicies(defaultPolicy config.CorsPolicy, groups []config.CorsGroup) []corsPolicy {
+	policies := make([]corsPolicy, 0, len(groups)+1)
+	for _, group := range groups {
+		prefix := strings.TrimRight(group.Prefix, "/")
+		if prefix == "" {
+			log.Warn().Msg("a cors group without prefix is ignored, the default policy applies to every route")
+			continue
+		}
+		policies = append(policies, corsPolicy{
+			group:   prefix,
+			prefix:  prefix,
+			handler: newCorsHandler(prefix, group.CorsPolicy),
+		})
+	}
+	sort.SliceStable(policies, func(i, j int) bool {
+		return len(policies[i].prefix) > len(policies[j].prefix)
+	})
+
+	return append(policies, corsPolicy{
+		group:   defaultCorsGroup,
+		handler: newCorsHandler(defaultCorsGroup, defaultPolicy),
+	})
+}
+
+func corsPolicyOf(policies []corsPolicy, path string) corsPolicy {
+	for _, policy := range policies {
+		if policy.prefix == "" || path == policy.prefix || strings.HasPrefix(path, policy.prefix+"/") {
+			return policy
+		}
+	}
+	return policies[len(policies)-1]
+}
+
+func newCorsHandler(group string, policy config.CorsPolicy) func(next http.Handler) http.Handler {
+	allowCredentials := policy.AllowCredentials
+	if allowCredentials && matchesEveryOrigin(policy.AllowedOrigins) {
+		// every origin would be sent back with the credentials allowed, so any site could use the session of a user
+		log.Error().Str("group", group).Msg("cors can't allow the credentials of every origin, they aren't allowed")
+		allowCredentials = false
+	}
+
+	c := cors.New(cors.Options{
+		AllowOriginFunc: func(_ *http.Request, origin string) bool {
+			return originAllowed(policy.AllowedOrigins, origin)
+		},
+		AllowedMethods:     policy.AllowedMethods,
+		AllowedHeaders:     policy.AllowedHeaders,
+		ExposedHeaders:     policy.ExposedHeaders,
+		AllowCredentials:   allowCredentials,
+		MaxAge:             int(policy.MaxAge.Seconds()),
+		OptionsPassthrough: true,
+	})
+
+	// the preflight goes through to this handler, the headers are only set when the policy allows it
+	preflight := c.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if w.Header().Get("Access-Control-Allow-Origin") != "" {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		corsPreflightRejectedTotal.Inc(group)
+		logger.Ctx(r.Context()).Warn().
+			Str("group", group).
+			Str("origin", r.Header.Get("Origin")).
+			Str("request_method", r.Header.Get("Access-Control-Request-Method")).
+			Str("request_headers", r.Header.Get("Access-Control-Request-Headers")).
+			Msg("cors preflight is rejected")
+		w.WriteHeader(http.StatusForbidden)
+	}))
+
+	return func(next http.Handler) http.Handler {
+		actual := c.Handler(next)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+				preflight.ServeHTTP(w, r)
+				return
+			}
+			actual.ServeHTTP(w, r)
+		})
+	}
+}
+
+func matchesEveryOrigin(origins []string) bool {
+	for _, origin := range origins {
+		if origin == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// originAllowed match the origin of a request with the allowed ones, a wildcard like https://*.example.com
+// match one or more subdomains of example.com but not example.com itself
+func originAllowed(allowed []string, origin string) bool {
+	origin = strings.ToLower(origin)
+	for _, pattern := range allowed {
+		pattern = strings.ToLower(strings.TrimRight(pattern, "/"))
+		if pattern == "*" || pattern == origin {
+			return true
+		}
+
+		i := strings.Index(pattern, "*.")
+		if i < 0 {
+			continue
+		}
+		prefix, suffix := pattern[:i], pattern[i+1:]
+		if !strings.HasPrefix(origin, prefix) || !strings.HasSuffix(origin, suffix) || len(origin) <= len(prefix)+len(suffix) {
+			continue
+		}
+		if isSubdomain(origin[len(prefix) : len(origin)-len(suffix)]) {
+			return true
+		}
+	}
+	return false
+}
+
+// isSubdomain only accept the labels of a host, so the wildcard can't match a port, a path or a user
+func isSubdomain(s string) bool {
+	for _, label := range strings.Split(s, ".") {
+		if label == "" {
+			return false
+		}
+		for _, c := range label {
+			if !(c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '-') {
+				return false
+			}
+		}
+	}
+	return true
+}
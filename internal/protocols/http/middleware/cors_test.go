@@ -0,0 +1,144 @@
+package middleware
+
+import (
+	"golang-starter/config"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOriginAllowed(t *testing.T) {
+	allowed := []string{"https://*.example.com", "https://app.example.org", "http://localhost:3000"}
+
+	tests := []struct {
+		origin string
+		want   bool
+	}{
+		{"https://app.example.com", true},
+		{"https://a.b.example.com", true},
+		{"HTTPS://App.Example.com", true},
+		{"https://app.example.org", true},
+		{"http://localhost:3000", true},
+		// the wildcard doesn't match the domain itself
+		{"https://example.com", false},
+		{"https://evil.example.com.attacker.io", false},
+		{"https://evilexample.com", false},
+		{"http://app.example.com", false},
+		// the port of the origin must be allowed too
+		{"https://app.example.com:8443", false},
+		{"http://localhost:4000", false},
+		{"https://user@app.example.com", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, originAllowed(allowed, tt.origin), tt.origin)
+	}
+
+	assert.True(t, originAllowed([]string{"*"}, "https://anything.io"))
+	assert.False(t, originAllowed(nil, "https://app.example.com"))
+}
+
+func TestIsSubdomain(t *testing.T) {
+	tests := []struct {
+		s    string
+		want bool
+	}{
+		{"app", true},
+		{"a.b-c.d1", true},
+		{"", false},
+		{"app.", false},
+		{".app", false},
+		{"app:8443", false},
+		{"app/path", false},
+		{"user@app", false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, isSubdomain(tt.s), tt.s)
+	}
+}
+
+func TestCorsPolicyOf(t *testing.T) {
+	policies := newCorsPolicies(config.CorsPolicy{}, []config.CorsGroup{
+		{Prefix: "/admin"},
+		{Prefix: "/admin/reports/"},
+	})
+
+	tests := []struct {
+		path  string
+		group string
+	}{
+		{"/admin", "/admin"},
+		{"/admin/users", "/admin"},
+		{"/admin/reports", "/admin/reports"},
+		{"/admin/reports/1", "/admin/reports"},
+		{"/adminx", defaultCorsGroup},
+		{"/users", defaultCorsGroup},
+		{"/", defaultCorsGroup},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.group, corsPolicyOf(policies, tt.path).group, tt.path)
+	}
+}
+
+func TestCorsPreflight(t *testing.T) {
+	policies := newCorsPolicies(config.CorsPolicy{
+		AllowedOrigins: []string{"https://*.example.com"},
+		AllowedMethods: []string{http.MethodGet},
+	}, []config.CorsGroup{{
+		Prefix:     "/admin",
+		CorsPolicy: config.CorsPolicy{AllowedOrigins: []string{"https://admin.example.com"}},
+	}})
+
+	tests := []struct {
+		name   string
+		path   string
+		origin string
+		method string
+		status int
+	}{
+		{"allowed", "/users", "https://app.example.com", http.MethodGet, http.StatusNoContent},
+		{"origin of another domain", "/users", "https://evil.example.com.attacker.io", http.MethodGet, http.StatusForbidden},
+		{"method not allowed", "/users", "https://app.example.com", http.MethodDelete, http.StatusForbidden},
+		{"origin of the default policy on a group", "/admin/users", "https://app.example.com", http.MethodGet, http.StatusForbidden},
+		{"origin of the group", "/admin/users", "https://admin.example.com", http.MethodGet, http.StatusNoContent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodOptions, tt.path, nil)
+			r.Header.Set("Origin", tt.origin)
+			r.Header.Set("Access-Control-Request-Method", tt.method)
+			w := httptest.NewRecorder()
+
+			corsPolicyOf(policies, tt.path).handler(okHandler).ServeHTTP(w, r)
+			assert.Equal(t, tt.status, w.Code)
+			if tt.status == http.StatusForbidden {
+				assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+			} else {
+				assert.Equal(t, tt.origin, w.Header().Get("Access-Control-Allow-Origin"))
+			}
+		})
+	}
+}
+
+func TestCorsReloadsPolicies(t *testing.T) {
+	defer config.Set(config.Config{})
+	handler := Cors()(okHandler)
+
+	preflight := func() int {
+		r := httptest.NewRequest(http.MethodOptions, "/users", nil)
+		r.Header.Set("Origin", "https://app.example.com")
+		r.Header.Set("Access-Control-Request-Method", http.MethodGet)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w.Code
+	}
+	assert.Equal(t, http.StatusForbidden, preflight())
+
+	var cfg config.Config
+	cfg.Cors.AllowedOrigins = []string{"https://app.example.com"}
+	cfg.Cors.AllowedMethods = []string{http.MethodGet}
+	config.Set(cfg)
+	assert.Equal(t, http.StatusNoContent, preflight())
+}
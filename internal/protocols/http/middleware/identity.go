@@ -0,0 +1,19 @@
+package middleware
+
+import (
+	"net/http"
+)
+
+// identityHeaders carried the caller to the handlers before the principal was in the request context
+var identityHeaders = []string{"id", "role", "sid", "api_key_id", "scopes"}
+
+// StripIdentityHeaders drop the inbound identity headers, so a client can't impersonate anyone
+// on a route that still trusts them. the caller is only read from auth.PrincipalFromContext
+func StripIdentityHeaders(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for _, header := range identityHeaders {
+			r.Header.Del(header)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
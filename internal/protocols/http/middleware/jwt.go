@@ -1,18 +1,17 @@
 package middleware
 
 import (
+	"context"
 	"fmt"
-	"golang-starter/config"
 	"net/http"
 	"strings"
-	"time"
 
+	"golang-starter/internal/logger"
+	"golang-starter/internal/protocols/http/errors"
 	httpresponse "golang-starter/internal/protocols/http/response"
-	"golang-starter/internal/utils/rsa"
+	"golang-starter/internal/utils/auth"
 
-	"github.com/golang-jwt/jwt"
 	"github.com/golang-jwt/jwt/request"
-	"github.com/rs/zerolog/log"
 )
 
 /*
@@ -24,107 +23,106 @@ import (
  */
 // JwtVerifyToken usefull for middleware for verify the jwt token from the Authorization
 // this function will serve to middleware and usefull for the idiomatic framework like gorm or chi or just net/http
-func JwtVerifyToken(next http.Handler) http.Handler {
+func JwtVerifyToken(verifier auth.TokenVerifier) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return jwtVerifyToken(verifier, next)
+	}
+}
+
+func jwtVerifyToken(verifier auth.TokenVerifier, next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		JwtToken := strings.Replace(r.Header.Get("Authorization"), fmt.Sprintf("%s ", "Bearer"), "", 1)
 
 		if JwtToken == "" {
-			httpresponse.Json(w, http.StatusUnauthorized, "", "token is empty")
-			return
-		}
-
-		token, err := request.ParseFromRequest(r, request.OAuth2Extractor, func(token *jwt.Token) (interface{}, error) {
-			tokenType := token.Claims.(jwt.MapClaims)["token_type"]
-
-			if _, ok := token.Method.(*jwt.SigningMethodRSA); !ok {
-				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-			} else if tokenType != "access_token" {
-				return nil, fmt.Errorf("unexpected token type: %v", tokenType)
-			} else {
-				publicRsa, err := rsa.ReadPublicKeyFromEnv(config.Get().Application.Key.Rsa.Public)
-				if err != nil {
-					log.Err(err).Msg("err read private key rsa from env")
-					return nil, nil
-				}
-				return publicRsa, nil
-			}
-		})
-
-		if err != nil || !token.Valid {
-			log.Err(err)
-			httpresponse.Json(w, http.StatusUnauthorized, "", "Token is not valid")
+			httpresponse.Err(w, r, errors.ErrTokenInvalid.WithMessage("token is empty"))
 			return
 		}
 
-		rawId := token.Claims.(jwt.MapClaims)["id"].(float64)
-		id := fmt.Sprintf("%d", int(rawId))
-		if id == "" {
-			httpresponse.Json(w, http.StatusUnauthorized, "", "Token not Found")
+		claims, err := parseToken(verifier, r, auth.TokenTypeAccess)
+		if err != nil {
+			unauthorizedToken(w, r, err)
 			return
 		}
 
-		rawExp := token.Claims.(jwt.MapClaims)["exp"].(float64)
-		exp := int64(rawExp)
-		if exp < time.Now().Unix() {
-			httpresponse.Json(w, http.StatusUnauthorized, "", "Token has expired")
+		ctx, err := contextWithClaims(r.Context(), claims)
+		if err != nil {
+			unauthorizedToken(w, r, err)
 			return
 		}
 
-		r.Header.Set("id", id)
-
-		next.ServeHTTP(w, r)
+		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
 
 // JwtVerifyRefreshToken usefull for middleware for verify the jwt refresh token from the Authorization
 // this function will serve to middleware and usefull for the idiomatic framework like gorm or chi or just net/http
-func JwtVerifyRefreshToken(next http.Handler) http.Handler {
+func JwtVerifyRefreshToken(verifier auth.TokenVerifier) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return jwtVerifyRefreshToken(verifier, next)
+	}
+}
+
+func jwtVerifyRefreshToken(verifier auth.TokenVerifier, next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		JwtToken := strings.Replace(r.Header.Get("Authorization"), fmt.Sprintf("%s ", "Bearer"), "", 1)
 
 		if JwtToken == "" {
-			httpresponse.Json(w, http.StatusUnauthorized, "", "Refresh token is empty")
+			httpresponse.Err(w, r, errors.ErrTokenInvalid.WithMessage("refresh token is empty"))
 			return
 		}
 
-		token, err := request.ParseFromRequest(r, request.OAuth2Extractor, func(token *jwt.Token) (interface{}, error) {
-			tokenType := token.Claims.(jwt.MapClaims)["token_type"]
-			if _, ok := token.Method.(*jwt.SigningMethodRSA); !ok {
-				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-			} else if tokenType != "refresh_token" {
-				return nil, fmt.Errorf("unexpected token type: %v", tokenType)
-			} else {
-				privateRsa, err := rsa.ReadPublicKeyFromEnv(config.Get().Application.Key.Rsa.Public)
-				if err != nil {
-					log.Err(err).Msg("err read private key rsa from env")
-					return nil, nil
-				}
-				return privateRsa, nil
-			}
-		})
-
-		if err != nil || !token.Valid {
-			log.Err(err).Msg("")
-			httpresponse.Json(w, http.StatusUnauthorized, "", "Refresh token is not valid")
+		claims, err := parseToken(verifier, r, auth.TokenTypeRefresh)
+		if err != nil {
+			unauthorizedToken(w, r, err)
 			return
 		}
 
-		rawId := token.Claims.(jwt.MapClaims)["id"].(float64)
-		id := fmt.Sprintf("%d", int(rawId))
-		if id == "" {
-			httpresponse.Json(w, http.StatusUnauthorized, "", "Refresh token not Found")
+		ctx, err := contextWithClaims(r.Context(), claims)
+		if err != nil {
+			unauthorizedToken(w, r, err)
 			return
 		}
 
-		rawExp := token.Claims.(jwt.MapClaims)["exp"].(float64)
-		exp := int64(rawExp)
-		if exp < time.Now().Unix() {
-			httpresponse.Json(w, http.StatusUnauthorized, "", "Refresh token has expired")
-			return
-		}
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
 
-		r.Header.Set("id", id)
+// parseToken verify the tokenType token of the Authorization header
+func parseToken(verifier auth.TokenVerifier, r *http.Request, tokenType string) (*auth.Claims, error) {
+	tokenString, err := request.OAuth2Extractor.ExtractToken(r)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", auth.ErrTokenMalformed, err)
+	}
 
-		next.ServeHTTP(w, r)
-	})
+	return verifier.Verify(r.Context(), tokenString, tokenType)
+}
+
+// contextWithClaims return a copy of ctx that carries the verified claims and the principal of them
+func contextWithClaims(ctx context.Context, claims *auth.Claims) (context.Context, error) {
+	principal, err := auth.NewJwtPrincipal(claims)
+	if err != nil {
+		return nil, err
+	}
+	logger.SetUserId(ctx, principal.UserId)
+	return auth.ContextWithPrincipal(auth.ContextWithClaims(ctx, claims), principal), nil
+}
+
+// unauthorizedToken respond the reason the token is rejected, the details of a signature error are only logged.
+// an error that isn't about the token, like the denylist being unreachable, is a server error
+func unauthorizedToken(w http.ResponseWriter, r *http.Request, err error) {
+	reason, ok := auth.TokenError(err)
+	if !ok {
+		httpresponse.Err(w, r, errors.ErrInternal.WithMessage("cannot verify the token").Wrap(err))
+		return
+	}
+
+	logger.Ctx(r.Context()).Debug().Err(err).Msg("token is rejected")
+	switch reason {
+	case auth.ErrTokenExpired:
+		httpresponse.Err(w, r, errors.ErrTokenExpired)
+	case auth.ErrTokenRevoked:
+		httpresponse.Err(w, r, errors.ErrTokenRevoked)
+	default:
+		httpresponse.Err(w, r, errors.ErrTokenInvalid.WithMessage(reason.Error()))
+	}
 }
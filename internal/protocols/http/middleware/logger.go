@@ -0,0 +1,100 @@
+package middleware
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"regexp"
+	"time"
+
+	"golang-starter/internal/utils/tracing"
+
+	"github.com/go-chi/chi/v5"
+	chimiddleware "github.com/go-chi/chi/v5/middleware"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+const RequestIdHeader = "X-Request-ID"
+
+// an inbound request id is only kept when it can't break the logs or the headers
+var requestIdPattern = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// RequestId accept the X-Request-ID of the caller or generate one, it's sent back in the response
+// and read by chimiddleware.GetReqID, so the problems and the logs carry the same id
+func RequestId(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestId := r.Header.Get(RequestIdHeader)
+		if !requestIdPattern.MatchString(requestId) {
+			requestId = newRequestId()
+		}
+
+		w.Header().Set(RequestIdHeader, requestId)
+		ctx := context.WithValue(r.Context(), chimiddleware.RequestIDKey, requestId)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func newRequestId() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return time.Now().UTC().Format("20060102150405.000000000")
+	}
+	return hex.EncodeToString(b)
+}
+
+// AccessLog attach a request logger to the context and write one line per request when it's done.
+// the request logger is read by logger.Ctx, it must be chained after RequestId and Tracing
+func AccessLog(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		logContext := log.With().
+			Str("request_id", chimiddleware.GetReqID(r.Context())).
+			Str("method", r.Method).
+			Str("path", r.URL.Path)
+		if sc := tracing.SpanContextFromContext(r.Context()); sc.IsValid() {
+			logContext = logContext.
+				Str("trace_id", sc.TraceId.String()).
+				Str("span_id", sc.SpanId.String())
+		}
+		requestLogger := logContext.Logger().Hook(routeHook(chi.RouteContext(r.Context())))
+
+		ww := chimiddleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		next.ServeHTTP(ww, r.WithContext(requestLogger.WithContext(r.Context())))
+
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+
+		event := requestLogger.Info()
+		switch {
+		case status >= http.StatusInternalServerError:
+			event = requestLogger.Error()
+		case status >= http.StatusBadRequest:
+			event = requestLogger.Warn()
+		}
+		event.
+			Int("status", status).
+			Int("bytes", ww.BytesWritten()).
+			Dur("latency", time.Since(start)).
+			Str("remote_addr", r.RemoteAddr).
+			Str("user_agent", r.UserAgent()).
+			Msg("request")
+	})
+}
+
+// routeHook add the route pattern like /users/{userId}, it's only known once the request is routed
+// so it's read when a line is written
+func routeHook(routeCtx *chi.Context) zerolog.HookFunc {
+	return func(e *zerolog.Event, _ zerolog.Level, _ string) {
+		if routeCtx == nil {
+			return
+		}
+		if pattern := routeCtx.RoutePattern(); pattern != "" {
+			e.Str("route", pattern)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"golang-starter/internal/utils/metrics"
+
+	chimiddleware "github.com/go-chi/chi/v5/middleware"
+)
+
+// the requests that don't match a route share a label, so a scan of random paths can't blow up the series
+const unmatchedRoute = "unmatched"
+
+var (
+	httpRequestsTotal = metrics.NewCounter("http_requests_total",
+		"the handled requests by their route and status", "method", "route", "status")
+	httpRequestDuration = metrics.NewHistogram("http_request_duration_seconds",
+		"the latency of the requests by their route", nil, "method", "route")
+	httpRequestsInFlight = metrics.NewGauge("http_requests_in_flight",
+		"the requests that are being handled by their route", "method", "route")
+)
+
+// Metrics record the requests by the chi pattern of their route, it must be chained before Recoverer
+// so a panic is recorded as a 500
+func Metrics(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		route := routePattern(r)
+		if route == "" {
+			route = unmatchedRoute
+		}
+
+		httpRequestsInFlight.Inc(r.Method, route)
+		defer httpRequestsInFlight.Dec(r.Method, route)
+
+		ww := chimiddleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		next.ServeHTTP(ww, r)
+
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+		httpRequestsTotal.Inc(r.Method, route, strconv.Itoa(status))
+		httpRequestDuration.ObserveSince(start, r.Method, route)
+	})
+}
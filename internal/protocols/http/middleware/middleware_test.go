@@ -0,0 +1,19 @@
+package middleware
+
+import (
+	"golang-starter/config"
+	"net/http"
+	"os"
+	"testing"
+)
+
+func TestMain(m *testing.M) {
+	// the error responses read the config, the tests don't have a config file
+	config.Set(config.Config{})
+	os.Exit(m.Run())
+}
+
+// okHandler respond 200, it's the handler behind the middleware under test
+var okHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+})
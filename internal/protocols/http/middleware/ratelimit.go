@@ -0,0 +1,100 @@
+package middleware
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"golang-starter/internal/logger"
+	"golang-starter/internal/protocols/http/errors"
+	httprequest "golang-starter/internal/protocols/http/request"
+	httpresponse "golang-starter/internal/protocols/http/response"
+	"golang-starter/internal/utils/auth"
+	"golang-starter/internal/utils/metrics"
+	"golang-starter/internal/utils/ratelimit"
+)
+
+var rateLimitedTotal = metrics.NewCounter("http_rate_limited_total", "the requests rejected by the rate limit", "policy")
+
+// RateLimit limit the requests of a client by the policy of the route, it sends the RateLimit headers
+// and a 429 with Retry-After once the bucket is empty. it must be chained after the authentication
+// so a client can be keyed by its user or api key. the requests go through when the store fails
+func RateLimit(limiter *ratelimit.Limiter) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !limiter.Enabled() {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			pattern := ""
+			if limiter.HasRoutes() {
+				pattern = routePattern(r)
+			}
+			policy := limiter.Policy(r.Method, pattern)
+			if takeRateLimit(w, r, limiter, policy, rateLimitClient(r, policy.Key)) {
+				next.ServeHTTP(w, r)
+			}
+		})
+	}
+}
+
+// RateLimitAuth limit the requests of an ip by RATE_LIMIT.AUTH, it must be chained before the authentication
+// so the requests with a token or api key that is rejected are limited as well
+func RateLimitAuth(limiter *ratelimit.Limiter) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !limiter.AuthEnabled() {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			policy := limiter.AuthPolicy()
+			if takeRateLimit(w, r, limiter, policy, "ip:"+httprequest.ClientIP(r)) {
+				next.ServeHTTP(w, r)
+			}
+		})
+	}
+}
+
+// takeRateLimit take a token of the bucket of client and send the RateLimit headers, it responds 429 and
+// returns false once the bucket is empty. the request is allowed when the store fails
+func takeRateLimit(w http.ResponseWriter, r *http.Request, limiter *ratelimit.Limiter, policy ratelimit.Policy, client string) bool {
+	result, err := limiter.Take(r.Context(), policy, client)
+	if err != nil {
+		logger.Ctx(r.Context()).Err(err).Str("policy", policy.Name).Msg("rate limit is skipped")
+		return true
+	}
+
+	header := w.Header()
+	header.Set("RateLimit-Limit", strconv.Itoa(result.Limit))
+	header.Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+	header.Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(result.Reset)))
+	header.Set("RateLimit-Policy", fmt.Sprintf("%d;w=%d;burst=%d",
+		policy.Limit.Requests, ceilSeconds(policy.Limit.Period), result.Limit))
+
+	if !result.Allowed {
+		rateLimitedTotal.Inc(policy.Name)
+		httpresponse.Err(w, r, errors.ErrTooManyRequests.WithRetryAfter(result.RetryAfter))
+		return false
+	}
+	return true
+}
+
+// rateLimitClient is the client the bucket is kept for, the anonymous requests are keyed by their ip
+func rateLimitClient(r *http.Request, key string) string {
+	principal, ok := auth.PrincipalFromContext(r.Context())
+	switch {
+	case ok && key == ratelimit.KeyApiKey && principal.Method == auth.AuthMethodApiKey:
+		return fmt.Sprintf("api_key:%d", principal.ApiKeyId)
+	case ok && (key == ratelimit.KeyUser || key == ratelimit.KeyApiKey):
+		return fmt.Sprintf("user:%d", principal.UserId)
+	}
+	return "ip:" + httprequest.ClientIP(r)
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,98 @@
+package middleware
+
+import (
+	"golang-starter/config"
+	"golang-starter/internal/utils/ratelimit"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestLimiter(requests int, period time.Duration) *ratelimit.Limiter {
+	var cfg config.Config
+	cfg.RateLimit.Enabled = true
+	cfg.RateLimit.Requests = requests
+	cfg.RateLimit.Period = period
+	config.Set(cfg)
+	defer config.Set(config.Config{})
+
+	return ratelimit.NewLimiter(ratelimit.NewMemoryStore())
+}
+
+func TestRateLimit(t *testing.T) {
+	handler := RateLimit(newTestLimiter(2, time.Minute))(okHandler)
+
+	serve := func(remoteAddr string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/users", nil)
+		r.RemoteAddr = remoteAddr
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w
+	}
+
+	w := serve("192.0.2.1:1234")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "2", w.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "1", w.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "2;w=60;burst=2", w.Header().Get("RateLimit-Policy"))
+	assert.Empty(t, w.Header().Get("Retry-After"))
+
+	assert.Equal(t, http.StatusOK, serve("192.0.2.1:1234").Code)
+
+	w = serve("192.0.2.1:1234")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "0", w.Header().Get("RateLimit-Remaining"))
+	assert.NotEmpty(t, w.Header().Get("RateLimit-Reset"))
+	retryAfter, err := strconv.Atoi(w.Header().Get("Retry-After"))
+	require.NoError(t, err)
+	assert.True(t, retryAfter > 0 && retryAfter <= 30, "Retry-After is %d", retryAfter)
+
+	// another client has its own bucket
+	assert.Equal(t, http.StatusOK, serve("192.0.2.2:1234").Code)
+}
+
+func TestRateLimitDisabled(t *testing.T) {
+	handler := RateLimit(newTestLimiter(0, time.Minute))(okHandler)
+
+	for i := 0; i < 3; i++ {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users", nil))
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, w.Header().Get("RateLimit-Limit"))
+	}
+}
+
+func TestRateLimitAuthLimitsRejectedApiKeys(t *testing.T) {
+	var cfg config.Config
+	cfg.RateLimit.Enabled = true
+	cfg.RateLimit.Requests = 100
+	cfg.RateLimit.Auth.Requests = 2
+	cfg.RateLimit.Auth.Period = time.Minute
+	config.Set(cfg)
+	defer config.Set(config.Config{})
+
+	limiter := ratelimit.NewLimiter(ratelimit.NewMemoryStore())
+	handler := RateLimitAuth(limiter)(JwtOrApiKey(fakeVerifier{}, fakeAuthenticator{})(RateLimit(limiter)(okHandler)))
+
+	serve := func() *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/users/me", nil)
+		r.RemoteAddr = "192.0.2.1:1234"
+		r.Header.Set(ApiKeyHeader, "invalid")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, serve().Code)
+	assert.Equal(t, http.StatusUnauthorized, serve().Code)
+
+	w := serve()
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.NotEmpty(t, w.Header().Get("Retry-After"))
+	assert.Equal(t, "2;w=60;burst=2", w.Header().Get("RateLimit-Policy"))
+}
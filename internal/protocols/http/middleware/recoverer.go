@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"runtime/debug"
+
+	"golang-starter/internal/logger"
+	"golang-starter/internal/protocols/http/errors"
+	httpresponse "golang-starter/internal/protocols/http/response"
+	"golang-starter/internal/utils/metrics"
+
+	"github.com/go-chi/chi/v5"
+	chimiddleware "github.com/go-chi/chi/v5/middleware"
+)
+
+var panicsTotal = metrics.NewCounter("http_panics_total", "the panics recovered from the http handlers", "route")
+
+// Recoverer turn a panic of the handlers into an internal error problem, the panic is logged with its stack
+// and the request logger. it must be chained after AccessLog so the request is still logged as a 500
+func Recoverer(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			rvr := recover()
+			if rvr == nil {
+				return
+			}
+			// the server abort the response on purpose, it must reach the server
+			if rvr == http.ErrAbortHandler {
+				panic(rvr)
+			}
+
+			route := ""
+			if routeCtx := chi.RouteContext(r.Context()); routeCtx != nil {
+				route = routeCtx.RoutePattern()
+			}
+			panicsTotal.Inc(route)
+
+			err := errors.ErrInternal.Wrap(fmt.Errorf("panic: %v", rvr)).WithStack(string(debug.Stack()))
+
+			// the status is already sent, the problem can't be written anymore
+			if ww, ok := w.(chimiddleware.WrapResponseWriter); ok && ww.Status() != 0 {
+				logger.Ctx(r.Context()).Err(err).Str("stack", err.Stack).Msg("panic after the response is written")
+				return
+			}
+			httpresponse.Err(w, r, err)
+		}()
+
+		next.ServeHTTP(w, r)
+	})
+}
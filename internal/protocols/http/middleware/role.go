@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"net/http"
+
+	"golang-starter/internal/protocols/http/errors"
+	httpresponse "golang-starter/internal/protocols/http/response"
+	"golang-starter/internal/utils/auth"
+)
+
+// RequireRole only let the request through when the principal has one of roles.
+// the principal is set by JwtVerifyToken or JwtOrApiKey, hence RequireRole must be chained after them
+func RequireRole(roles ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal, ok := auth.PrincipalFromContext(r.Context())
+			if ok && principal.HasRole(roles...) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			httpresponse.Err(w, r, errors.ErrForbidden)
+		})
+	}
+}
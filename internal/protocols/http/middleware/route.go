@@ -0,0 +1,22 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// routePattern resolve the whole pattern of the route like /users/{userId}, the pattern in the route context
+// is only complete once the request is routed. it's empty when no route matches
+func routePattern(r *http.Request) string {
+	routeCtx := chi.RouteContext(r.Context())
+	if routeCtx == nil || routeCtx.Routes == nil {
+		return ""
+	}
+
+	matched := chi.NewRouteContext()
+	if !routeCtx.Routes.Match(matched, r.Method, r.URL.Path) {
+		return ""
+	}
+	return matched.RoutePattern()
+}
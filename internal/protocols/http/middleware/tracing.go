@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+
+	"golang-starter/internal/utils/tracing"
+
+	chimiddleware "github.com/go-chi/chi/v5/middleware"
+)
+
+// TraceresponseHeader send the trace of the request back, so a caller can look it up
+const TraceresponseHeader = "traceresponse"
+
+// Tracing start the server span of the request, it continues the traceparent of the caller.
+// it must be chained before AccessLog so the request logger has the trace id
+func Tracing(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := tracing.Extract(r.Context(), r.Header)
+		ctx, span := tracing.Start(ctx, "HTTP "+r.Method, tracing.WithKind(tracing.KindServer))
+		defer span.End()
+
+		span.SetAttributes(
+			tracing.String("http.method", r.Method),
+			tracing.String("http.target", r.URL.Path),
+			tracing.String("http.user_agent", r.UserAgent()),
+			tracing.String("net.peer.addr", r.RemoteAddr),
+			tracing.String("http.request_id", chimiddleware.GetReqID(ctx)),
+		)
+		w.Header().Set(TraceresponseHeader, span.SpanContext().Traceparent())
+
+		ww := chimiddleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		r = r.WithContext(ctx)
+		next.ServeHTTP(ww, r)
+
+		// the pattern is complete once the request went through every router
+		if route := routePattern(r); route != "" {
+			span.SetName(r.Method + " " + route)
+			span.SetAttributes(tracing.String("http.route", route))
+		}
+
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+		span.SetAttributes(tracing.Int("http.status_code", status))
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(tracing.StatusError, "HTTP "+strconv.Itoa(status))
+		}
+	})
+}
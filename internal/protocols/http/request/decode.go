@@ -0,0 +1,69 @@
+package request
+
+import (
+	"encoding/json"
+	"fmt"
+	"golang-starter/internal/protocols/http/errors"
+	"golang-starter/internal/utils/validation"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// DecodeJSON strictly decode the json body into dst then validate it by its validate tags.
+// a malformed body, an unknown field or any data after the json value is a bad request,
+// a field that breaks its rules is a validation error with the list of the invalid fields
+func DecodeJSON(r *http.Request, dst interface{}) error {
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(dst); err != nil {
+		return errors.BadRequest(decodeErrorMessage(err))
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return errors.BadRequest("the body must only contain a single json value")
+	}
+
+	return Validate(dst)
+}
+
+// Validate check v by its validate tags, it's used for the requests that aren't decoded by DecodeJSON
+func Validate(v interface{}) error {
+	err := validation.Struct(v)
+	if err == nil {
+		return nil
+	}
+
+	fieldErrors, ok := err.(validation.Errors)
+	if !ok {
+		return errors.Internal(err)
+	}
+
+	params := make([]errors.InvalidParam, 0, len(fieldErrors))
+	for _, fieldError := range fieldErrors {
+		params = append(params, errors.InvalidParam{
+			Name:   fieldError.Field,
+			Reason: fieldError.Message,
+			Rule:   fieldError.Rule,
+		})
+	}
+	return errors.ErrValidation.WithInvalidParams(params)
+}
+
+func decodeErrorMessage(err error) string {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case err == io.EOF:
+		return "the body is empty"
+	case err == io.ErrUnexpectedEOF:
+		return "the body is not a complete json"
+	case errors.As(err, &syntaxErr):
+		return fmt.Sprintf("the body is not a valid json at offset %d", syntaxErr.Offset)
+	case errors.As(err, &typeErr) && typeErr.Field != "":
+		return fmt.Sprintf("%s must be a %s", typeErr.Field, typeErr.Type)
+	case errors.As(err, &typeErr):
+		return fmt.Sprintf("the body must be a %s", typeErr.Type)
+	}
+	// like json: unknown field "name"
+	return strings.TrimPrefix(err.Error(), "json: ")
+}
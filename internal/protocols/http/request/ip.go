@@ -0,0 +1,117 @@
+package request
+
+import (
+	"golang-starter/config"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	trustedProxiesOnce sync.Once
+	trustedProxiesMu   sync.RWMutex
+	trustedProxies     []*net.IPNet
+)
+
+// ClientIP return the ip address of the client without the port. behind a trusted proxy it's the last address of
+// X-Forwarded-For that isn't a trusted proxy, so a client can't choose its ip by sending the header itself.
+// X-Real-IP is read when there is no X-Forwarded-For
+func ClientIP(r *http.Request) string {
+	ip := remoteIP(r.RemoteAddr)
+	proxies := loadTrustedProxies()
+	if !isTrustedProxy(proxies, ip) {
+		return ip
+	}
+
+	forwarded := r.Header.Values("X-Forwarded-For")
+	if len(forwarded) == 0 {
+		if realIP := net.ParseIP(strings.TrimSpace(r.Header.Get("X-Real-IP"))); realIP != nil {
+			return realIP.String()
+		}
+		return ip
+	}
+
+	hops := strings.Split(strings.Join(forwarded, ","), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := net.ParseIP(strings.TrimSpace(hops[i]))
+		if hop == nil {
+			// the hops before an invalid one can't be trusted, the proxy that added it is the client
+			return ip
+		}
+		ip = hop.String()
+		if !isTrustedProxy(proxies, ip) {
+			return ip
+		}
+	}
+	return ip
+}
+
+func remoteIP(remoteAddr string) string {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		return remoteAddr
+	}
+	return host
+}
+
+func isTrustedProxy(proxies []*net.IPNet, ip string) bool {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+	for _, proxy := range proxies {
+		if proxy.Contains(parsed) {
+			return true
+		}
+	}
+	return false
+}
+
+// loadTrustedProxies parse APPLICATION.TRUSTED_PROXIES once, they're parsed again when the config is reloaded
+func loadTrustedProxies() []*net.IPNet {
+	trustedProxiesOnce.Do(func() {
+		setTrustedProxies(config.Get())
+		config.OnReload(setTrustedProxies)
+	})
+
+	trustedProxiesMu.RLock()
+	defer trustedProxiesMu.RUnlock()
+	return trustedProxies
+}
+
+func setTrustedProxies(cfg config.Config) {
+	proxies := parseTrustedProxies(cfg.Application.TrustedProxies)
+
+	trustedProxiesMu.Lock()
+	trustedProxies = proxies
+	trustedProxiesMu.Unlock()
+}
+
+// parseTrustedProxies accept a CIDR or a single ip, an invalid one is skipped
+func parseTrustedProxies(entries []string) []*net.IPNet {
+	var proxies []*net.IPNet
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if !strings.Contains(entry, "/") {
+			if ip := net.ParseIP(entry); ip != nil {
+				bits := 8 * net.IPv6len
+				if ip.To4() != nil {
+					ip, bits = ip.To4(), 8*net.IPv4len
+				}
+				proxies = append(proxies, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+				continue
+			}
+		}
+
+		_, proxy, err := net.ParseCIDR(entry)
+		if err != nil {
+			log.Warn().Str("proxy", entry).Msg("the trusted proxy is neither an ip nor a CIDR, it's ignored")
+			continue
+		}
+		proxies = append(proxies, proxy)
+	}
+	return proxies
+}
@@ -0,0 +1,49 @@
+package request
+
+import (
+	"golang-starter/config"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientIP(t *testing.T) {
+	var cfg config.Config
+	cfg.Application.TrustedProxies = []string{"10.0.0.0/8", "192.0.2.1", "not a proxy"}
+	config.Set(cfg)
+	defer config.Set(config.Config{})
+
+	tests := []struct {
+		name         string
+		remoteAddr   string
+		forwardedFor []string
+		realIP       string
+		want         string
+	}{
+		{"direct client", "203.0.113.9:1234", nil, "", "203.0.113.9"},
+		{"untrusted remote can't choose its ip", "203.0.113.9:1234", []string{"198.51.100.1"}, "198.51.100.1", "203.0.113.9"},
+		{"load balancer", "10.0.0.1:1234", []string{"198.51.100.1"}, "", "198.51.100.1"},
+		{"single trusted ip", "192.0.2.1:1234", []string{"198.51.100.1"}, "", "198.51.100.1"},
+		{"spoofed hop before the client", "10.0.0.1:1234", []string{"1.1.1.1, 198.51.100.1"}, "", "198.51.100.1"},
+		{"chain of proxies", "10.0.0.1:1234", []string{"198.51.100.1, 10.0.0.2", "10.0.0.3"}, "", "198.51.100.1"},
+		{"only proxies", "10.0.0.1:1234", []string{"10.0.0.2"}, "", "10.0.0.2"},
+		{"invalid hop", "10.0.0.1:1234", []string{"198.51.100.1, garbage"}, "", "10.0.0.1"},
+		{"real ip", "10.0.0.1:1234", nil, "198.51.100.1", "198.51.100.1"},
+		{"no header", "10.0.0.1:1234", nil, "", "10.0.0.1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.RemoteAddr = tt.remoteAddr
+			for _, forwarded := range tt.forwardedFor {
+				r.Header.Add("X-Forwarded-For", forwarded)
+			}
+			if tt.realIP != "" {
+				r.Header.Set("X-Real-IP", tt.realIP)
+			}
+			assert.Equal(t, tt.want, ClientIP(r))
+		})
+	}
+}
@@ -2,8 +2,15 @@ package response
 
 import (
 	"encoding/json"
+	"golang-starter/config"
+	"golang-starter/internal/logger"
 	"golang-starter/internal/protocols/http/errors"
+	"math"
 	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5/middleware"
 )
 
 type Response struct {
@@ -21,24 +28,80 @@ func Json(w http.ResponseWriter, httpCode int, message string, data interface{})
 	json.NewEncoder(w).Encode(res)
 }
 
+// Raw write data without the response envelope, it's used for the documents
+// that have their own format like the jwks
+func Raw(w http.ResponseWriter, httpCode int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(httpCode)
+	json.NewEncoder(w).Encode(data)
+}
+
 func Text(w http.ResponseWriter, httpCode int, message string) {
 	w.Header().Set("Content-Type", "text/plain")
 	w.WriteHeader(httpCode)
 	w.Write([]byte(message))
 }
 
-// TODO: implement response error
-func Err(w http.ResponseWriter, err error) {
-	_, ok := err.(*errors.RespError)
-	if !ok {
-		err = errors.InternalServerError(err.Error())
+// Problem is the problem details of RFC 7807, Code, RequestId and InvalidParams are its extension members
+type Problem struct {
+	Type          string                `json:"type"`
+	Title         string                `json:"title"`
+	Status        int                   `json:"status"`
+	Detail        string                `json:"detail,omitempty"`
+	Instance      string                `json:"instance,omitempty"`
+	Code          string                `json:"code"`
+	RequestId     string                `json:"request_id,omitempty"`
+	InvalidParams []errors.InvalidParam `json:"invalid_params,omitempty"`
+	Stack         string                `json:"stack,omitempty"`
+}
+
+// Err write err as an application/problem+json response. an error that isn't a RespError is an
+// internal error, in production its message and the cause of a RespError are only logged
+func Err(w http.ResponseWriter, r *http.Request, err error) {
+	var er *errors.RespError
+	if !errors.As(err, &er) {
+		er = errors.ErrInternal.Wrap(err)
 	}
 
-	er, _ := err.(*errors.RespError)
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(er.Code)
-	res := Response{
-		Message: &er.Message,
+	requestId := middleware.GetReqID(r.Context())
+	if er.Code >= http.StatusInternalServerError {
+		// the request logger already has the request id and the path
+		event := logger.Ctx(r.Context()).Err(err)
+		if er.Stack != "" {
+			event = event.Str("stack", er.Stack)
+		}
+		event.Msg("internal error")
 	}
-	json.NewEncoder(w).Encode(res)
+
+	cfg := config.Get().Application
+	detail := er.Message
+	stack := ""
+	if cfg.Env != "production" {
+		detail = er.Error()
+		if cfg.Debug {
+			stack = er.Stack
+		}
+	}
+
+	problemType := "about:blank"
+	if cfg.ProblemTypeUrl != "" {
+		problemType = cfg.ProblemTypeUrl + strings.ToLower(strings.ReplaceAll(er.ErrorCode, "_", "-"))
+	}
+
+	if er.RetryAfter > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(er.RetryAfter.Seconds()))))
+	}
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(er.Code)
+	json.NewEncoder(w).Encode(Problem{
+		Type:          problemType,
+		Title:         http.StatusText(er.Code),
+		Status:        er.Code,
+		Detail:        detail,
+		Instance:      r.URL.Path,
+		Code:          er.ErrorCode,
+		RequestId:     requestId,
+		InvalidParams: er.InvalidParams,
+		Stack:         stack,
+	})
 }
@@ -0,0 +1,39 @@
+package router
+
+import (
+	"golang-starter/config"
+	httpresponse "golang-starter/internal/protocols/http/response"
+	"golang-starter/internal/utils/health"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// the probes of the orchestrator, they're outside of the groups so neither the authentication nor the rate limit applies
+func (h *HttpRouterImpl) health(r *chi.Mux) {
+	r.Get("/healthz", h.liveness)
+	r.Get("/readyz", h.readiness)
+}
+
+// liveness only tell the process is serving, a failing dependency must not restart it
+func (h *HttpRouterImpl) liveness(w http.ResponseWriter, r *http.Request) {
+	httpresponse.Raw(w, http.StatusOK, health.Report{Status: health.StatusOk})
+}
+
+// readiness tell whether the instance can take requests, the errors of the checks are hidden in production
+func (h *HttpRouterImpl) readiness(w http.ResponseWriter, r *http.Request) {
+	report := h.checker.Ready(r.Context())
+	if config.Get().Application.Env == "production" {
+		for name, result := range report.Checks {
+			result.Error = ""
+			report.Checks[name] = result
+		}
+	}
+
+	status := http.StatusOK
+	if report.Status != health.StatusOk {
+		status = http.StatusServiceUnavailable
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	httpresponse.Raw(w, status, report)
+}
@@ -1,35 +1,88 @@
 package router
 
 import (
+	"golang-starter/config"
+	"golang-starter/internal/protocols/http/middleware"
+	"golang-starter/internal/utils/health"
+	"golang-starter/internal/utils/metrics"
 	"golang-starter/src/handlers/http"
 
 	_ "golang-starter/docs"
 
 	"github.com/go-chi/chi/v5"
-	"github.com/go-chi/cors"
+	"github.com/rs/zerolog/log"
 	httpswagger "github.com/swaggo/http-swagger"
 )
 
 type HttpRouterImpl struct {
 	handlers *http.HttpHandlerImpl
+	checker  *health.Checker
 }
 
 func NewHttpRoute(
 	handlers *http.HttpHandlerImpl,
+	checker *health.Checker,
 ) *HttpRouterImpl {
 	return &HttpRouterImpl{
 		handlers: handlers,
+		checker:  checker,
 	}
 }
 
-// setup cors
+// setup cors, the policy of a route is the one of its group in CORS.GROUPS
 func (h *HttpRouterImpl) cors(r *chi.Mux) {
-	r.Use(cors.AllowAll().Handler)
+	r.Use(middleware.Cors())
+}
+
+// every request has an id, a span and a request logger, they must be the first middlewares
+// so the others log with them. a panic is recovered right after, so it's logged as a 500
+func (h *HttpRouterImpl) requestLog(r *chi.Mux) {
+	r.Use(middleware.RequestId)
+	r.Use(middleware.Tracing)
+	r.Use(middleware.AccessLog)
+	r.Use(middleware.Metrics)
+	r.Use(middleware.Recoverer)
+}
+
+// the metrics are served here unless they're bound to an admin port
+func (h *HttpRouterImpl) metrics(r *chi.Mux) {
+	cfg := config.Get().Metrics
+	if !cfg.Enabled || cfg.Port != 0 {
+		return
+	}
+	r.Get(MetricsPath(), metrics.Handler().ServeHTTP)
+}
+
+// MetricsPath is the path of the metrics, it's /metrics by default
+func MetricsPath() string {
+	if path := config.Get().Metrics.Path; path != "" {
+		return path
+	}
+	return "/metrics"
+}
+
+// the internal routes require a verified client certificate, the others only read it when it's sent
+func (h *HttpRouterImpl) clientCert(r *chi.Mux) {
+	tlsConfig := config.Get().Application.Tls
+	if len(tlsConfig.ClientAuth.Routes) > 0 && (!tlsConfig.Enabled || tlsConfig.ClientAuth.CaFile == "") {
+		log.Warn().Strs("routes", tlsConfig.ClientAuth.Routes).Msg("the client certificates aren't verified without tls and a CA file, these routes reject every request")
+	}
+	r.Use(middleware.ClientCert)
+}
+
+// the caller is only trusted from the request context, never from the headers
+func (h *HttpRouterImpl) stripIdentityHeaders(r *chi.Mux) {
+	r.Use(middleware.StripIdentityHeaders)
 }
 
 func (h *HttpRouterImpl) Router(r *chi.Mux) {
+	h.requestLog(r)
+	h.stripIdentityHeaders(r)
 	h.cors(r)
+	h.clientCert(r)
+	h.health(r)
 	h.handlers.Router(r)
+	h.metrics(r)
 
 	// Setup swagger docs from autogenerated swagger
 	r.Mount("/swagger", httpswagger.WrapHandler)
@@ -0,0 +1,16 @@
+package auth
+
+import "context"
+
+// ApiKeyIdentity is the caller of an api key, it's the owner of the key limited to the key scopes
+type ApiKeyIdentity struct {
+	ApiKeyId int32
+	UserId   int32
+	Role     string
+	Scopes   []string
+}
+
+// ApiKeyAuthenticator return the identity of a valid api key, it's used by the api key middleware
+type ApiKeyAuthenticator interface {
+	AuthenticateApiKey(ctx context.Context, apiKey string) (*ApiKeyIdentity, error)
+}
@@ -0,0 +1,124 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+const (
+	TokenTypeAccess  = "access_token"
+	TokenTypeRefresh = "refresh_token"
+)
+
+// the reasons a token is rejected, the messages are sent to the client as they are
+var (
+	ErrTokenMalformed        = errors.New("token is malformed")
+	ErrTokenSignature        = errors.New("token signature is not valid")
+	ErrTokenExpired          = errors.New("token has expired")
+	ErrTokenNotValidYet      = errors.New("token is not valid yet")
+	ErrTokenUsedBeforeIssued = errors.New("token is used before it's issued")
+	ErrTokenIssuer           = errors.New("token issuer is not accepted")
+	ErrTokenAudience         = errors.New("token audience is not accepted")
+	ErrTokenType             = errors.New("token type is not accepted")
+	ErrTokenSubject          = errors.New("token has no subject")
+)
+
+// Claims is the payload of the access and refresh tokens, the times are in unix seconds
+type Claims struct {
+	Issuer    string   `json:"iss,omitempty"`
+	Audience  Audience `json:"aud,omitempty"`
+	Subject   string   `json:"sub"`
+	ID        string   `json:"jti"`
+	IssuedAt  int64    `json:"iat"`
+	NotBefore int64    `json:"nbf"`
+	ExpiresAt int64    `json:"exp"`
+	Role      string   `json:"role,omitempty"`
+	SessionID string   `json:"sid"`
+	TokenType string   `json:"token_type"`
+}
+
+// Audience is the aud claims, RFC 7519 allow it to be a single string or an array
+type Audience []string
+
+func (a *Audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = Audience{single}
+		return nil
+	}
+
+	var multiple []string
+	if err := json.Unmarshal(data, &multiple); err != nil {
+		return err
+	}
+	*a = multiple
+	return nil
+}
+
+// ClaimsValidation is what the registered claims are checked against, an empty Issuer or Audience isn't checked.
+// Leeway is the clock skew that is tolerated between the signer and the verifier
+type ClaimsValidation struct {
+	Issuer   string
+	Audience []string
+	Leeway   time.Duration
+}
+
+// Valid satisfy jwt.Claims, the signer skip it and call Validate with the configured validation
+func (c *Claims) Valid() error {
+	return c.Validate(time.Now(), c.TokenType, ClaimsValidation{})
+}
+
+// Validate check the claims of a tokenType token at now
+func (c *Claims) Validate(now time.Time, tokenType string, v ClaimsValidation) error {
+	leeway := int64(v.Leeway / time.Second)
+	unix := now.Unix()
+
+	if c.ExpiresAt == 0 || unix > c.ExpiresAt+leeway {
+		return ErrTokenExpired
+	}
+	if unix < c.NotBefore-leeway {
+		return ErrTokenNotValidYet
+	}
+	if unix < c.IssuedAt-leeway {
+		return ErrTokenUsedBeforeIssued
+	}
+	if v.Issuer != "" && c.Issuer != v.Issuer {
+		return ErrTokenIssuer
+	}
+	if len(v.Audience) > 0 && !c.Audience.containsAny(v.Audience) {
+		return ErrTokenAudience
+	}
+	if c.TokenType != tokenType {
+		return ErrTokenType
+	}
+	if c.Subject == "" {
+		return ErrTokenSubject
+	}
+	return nil
+}
+
+func (a Audience) containsAny(audience []string) bool {
+	for _, aud := range a {
+		for _, accepted := range audience {
+			if aud == accepted {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+type claimsContextKey struct{}
+
+// ContextWithClaims return a copy of ctx that carries the verified claims
+func ContextWithClaims(ctx context.Context, claims *Claims) context.Context {
+	return context.WithValue(ctx, claimsContextKey{}, claims)
+}
+
+// ClaimsFromContext return the claims that the jwt middleware verified, false when the request has no token
+func ClaimsFromContext(ctx context.Context) (*Claims, bool) {
+	claims, ok := ctx.Value(claimsContextKey{}).(*Claims)
+	return claims, ok
+}
@@ -0,0 +1,73 @@
+package auth
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClaimsValidate(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	validation := ClaimsValidation{Issuer: "golang-starter", Audience: []string{"golang-starter"}, Leeway: 30 * time.Second}
+	valid := func() Claims {
+		return Claims{
+			Issuer:    "golang-starter",
+			Audience:  Audience{"golang-starter"},
+			Subject:   "1",
+			ID:        "jti",
+			IssuedAt:  now.Unix(),
+			NotBefore: now.Unix(),
+			ExpiresAt: now.Add(time.Hour).Unix(),
+			TokenType: TokenTypeAccess,
+		}
+	}
+
+	tests := []struct {
+		name   string
+		modify func(c *Claims)
+		now    time.Time
+		err    error
+	}{
+		{name: "Valid", modify: func(c *Claims) {}, now: now},
+		{name: "ExpiredWithinLeeway", modify: func(c *Claims) { c.ExpiresAt = now.Add(-10 * time.Second).Unix() }, now: now},
+		{name: "Expired", modify: func(c *Claims) { c.ExpiresAt = now.Add(-time.Minute).Unix() }, now: now, err: ErrTokenExpired},
+		{name: "NoExpiry", modify: func(c *Claims) { c.ExpiresAt = 0 }, now: now, err: ErrTokenExpired},
+		{name: "NotBeforeWithinLeeway", modify: func(c *Claims) { c.NotBefore = now.Add(10 * time.Second).Unix() }, now: now},
+		{name: "NotValidYet", modify: func(c *Claims) { c.NotBefore = now.Add(time.Minute).Unix() }, now: now, err: ErrTokenNotValidYet},
+		{name: "IssuedInFuture", modify: func(c *Claims) { c.IssuedAt = now.Add(time.Minute).Unix() }, now: now, err: ErrTokenUsedBeforeIssued},
+		{name: "WrongIssuer", modify: func(c *Claims) { c.Issuer = "other" }, now: now, err: ErrTokenIssuer},
+		{name: "WrongAudience", modify: func(c *Claims) { c.Audience = Audience{"other"} }, now: now, err: ErrTokenAudience},
+		{name: "OneOfAudience", modify: func(c *Claims) { c.Audience = Audience{"other", "golang-starter"} }, now: now},
+		{name: "RefreshToken", modify: func(c *Claims) { c.TokenType = TokenTypeRefresh }, now: now, err: ErrTokenType},
+		{name: "NoSubject", modify: func(c *Claims) { c.Subject = "" }, now: now, err: ErrTokenSubject},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := valid()
+			tt.modify(&claims)
+			assert.Equal(t, tt.err, claims.Validate(tt.now, TokenTypeAccess, validation))
+		})
+	}
+
+	t.Run("NoIssuerOrAudienceConfigured", func(t *testing.T) {
+		claims := valid()
+		claims.Issuer = ""
+		claims.Audience = nil
+		assert.NoError(t, claims.Validate(now, TokenTypeAccess, ClaimsValidation{}))
+	})
+}
+
+func TestAudienceUnmarshal(t *testing.T) {
+	var claims Claims
+	require.NoError(t, json.Unmarshal([]byte(`{"aud":"api"}`), &claims))
+	assert.Equal(t, Audience{"api"}, claims.Audience)
+
+	require.NoError(t, json.Unmarshal([]byte(`{"aud":["api","web"]}`), &claims))
+	assert.Equal(t, Audience{"api", "web"}, claims.Audience)
+
+	assert.Error(t, json.Unmarshal([]byte(`{"aud":1}`), &claims))
+}
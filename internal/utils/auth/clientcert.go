@@ -0,0 +1,51 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+)
+
+// ClientIdentity is the client of a request that sent a certificate verified against the client CAs,
+// Fingerprint is the sha256 of the certificate in hex
+type ClientIdentity struct {
+	CommonName   string
+	Organization []string
+	DNSNames     []string
+	URIs         []string
+	SerialNumber string
+	Fingerprint  string
+	// NotAfter is when the certificate expires in millisecond
+	NotAfter int64
+}
+
+// NewClientIdentity return the identity of the leaf of a verified chain
+func NewClientIdentity(cert *x509.Certificate) *ClientIdentity {
+	fingerprint := sha256.Sum256(cert.Raw)
+	identity := &ClientIdentity{
+		CommonName:   cert.Subject.CommonName,
+		Organization: cert.Subject.Organization,
+		DNSNames:     cert.DNSNames,
+		SerialNumber: cert.SerialNumber.String(),
+		Fingerprint:  hex.EncodeToString(fingerprint[:]),
+		NotAfter:     cert.NotAfter.UnixNano() / 1e6,
+	}
+	for _, uri := range cert.URIs {
+		identity.URIs = append(identity.URIs, uri.String())
+	}
+	return identity
+}
+
+type clientIdentityContextKey struct{}
+
+// ContextWithClientIdentity return a copy of ctx that carries the client identity
+func ContextWithClientIdentity(ctx context.Context, identity *ClientIdentity) context.Context {
+	return context.WithValue(ctx, clientIdentityContextKey{}, identity)
+}
+
+// ClientIdentityFromContext return the client of the request, false when it didn't send a verified certificate
+func ClientIdentityFromContext(ctx context.Context) (*ClientIdentity, bool) {
+	identity, ok := ctx.Value(clientIdentityContextKey{}).(*ClientIdentity)
+	return identity, ok
+}
@@ -0,0 +1,87 @@
+package auth
+
+import (
+	"context"
+	"golang-starter/config"
+	"golang-starter/infrastructures/cached"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Denylist keep the revoked tokens and sessions until their tokens expire on their own
+type Denylist interface {
+	// Deny add key to the denylist for ttl
+	Deny(ctx context.Context, key string, ttl time.Duration) error
+	// IsDenied tell whether one of keys is in the denylist
+	IsDenied(ctx context.Context, keys ...string) (bool, error)
+}
+
+// NewDenylist choose the store based on AUTH.TOKEN_DENYLIST.STORE
+func NewDenylist(redis *cached.RedisImpl) Denylist {
+	switch config.Get().Auth.TokenDenylist.Store {
+	case "redis":
+		if !redis.Enabled() {
+			log.Fatal().Msg("token denylist store is redis but redis is disabled")
+		}
+		return NewRedisDenylist(redis)
+	default:
+		return NewMemoryDenylist()
+	}
+}
+
+// the memory denylist only works when the app run as a single instance
+type MemoryDenylist struct {
+	mu        sync.Mutex
+	entries   map[string]time.Time
+	lastSweep time.Time
+}
+
+func NewMemoryDenylist() *MemoryDenylist {
+	return &MemoryDenylist{
+		entries:   map[string]time.Time{},
+		lastSweep: time.Now(),
+	}
+}
+
+func (d *MemoryDenylist) Deny(ctx context.Context, key string, ttl time.Duration) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := time.Now()
+	d.sweep(now)
+
+	expiredAt := now.Add(ttl)
+	if expiredAt.After(d.entries[key]) {
+		d.entries[key] = expiredAt
+	}
+	return nil
+}
+
+func (d *MemoryDenylist) IsDenied(ctx context.Context, keys ...string) (bool, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := time.Now()
+	for _, key := range keys {
+		if expiredAt, ok := d.entries[key]; ok && expiredAt.After(now) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// sweep remove the expired entries at most once a minute, so the map only keep the live tokens
+func (d *MemoryDenylist) sweep(now time.Time) {
+	if now.Sub(d.lastSweep) < time.Minute {
+		return
+	}
+	d.lastSweep = now
+
+	for key, expiredAt := range d.entries {
+		if expiredAt.Before(now) {
+			delete(d.entries, key)
+		}
+	}
+}
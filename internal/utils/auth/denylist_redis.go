@@ -0,0 +1,51 @@
+package auth
+
+import (
+	"context"
+	"golang-starter/infrastructures/cached"
+	"time"
+)
+
+const denylistRedisPrefix = "token_denylist:"
+
+type RedisDenylist struct {
+	redis *cached.RedisImpl
+}
+
+func NewRedisDenylist(redis *cached.RedisImpl) *RedisDenylist {
+	return &RedisDenylist{
+		redis: redis,
+	}
+}
+
+func (d RedisDenylist) Deny(ctx context.Context, key string, ttl time.Duration) error {
+	key = denylistRedisPrefix + key
+
+	// a key that is already denied for longer keep its ttl
+	current, err := d.redis.DB().PTTL(ctx, key).Result()
+	if err != nil {
+		return err
+	}
+	if current >= ttl {
+		return nil
+	}
+
+	return d.redis.DB().Set(ctx, key, 1, ttl).Err()
+}
+
+func (d RedisDenylist) IsDenied(ctx context.Context, keys ...string) (bool, error) {
+	if len(keys) == 0 {
+		return false, nil
+	}
+
+	prefixed := make([]string, len(keys))
+	for i, key := range keys {
+		prefixed[i] = denylistRedisPrefix + key
+	}
+
+	count, err := d.redis.DB().Exists(ctx, prefixed...).Result()
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
@@ -0,0 +1,50 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryDenylist(t *testing.T) {
+	ctx := context.Background()
+	denylist := NewMemoryDenylist()
+
+	denied, err := denylist.IsDenied(ctx, "jti:a")
+	require.NoError(t, err)
+	assert.False(t, denied)
+
+	require.NoError(t, denylist.Deny(ctx, "jti:a", time.Hour))
+	denied, err = denylist.IsDenied(ctx, "jti:b", "jti:a")
+	require.NoError(t, err)
+	assert.True(t, denied)
+
+	// a shorter ttl doesn't shorten the entry
+	require.NoError(t, denylist.Deny(ctx, "jti:a", time.Nanosecond))
+	time.Sleep(time.Millisecond)
+	denied, _ = denylist.IsDenied(ctx, "jti:a")
+	assert.True(t, denied)
+
+	// the entry is gone once the token would have expired
+	require.NoError(t, denylist.Deny(ctx, "sid:s", time.Nanosecond))
+	time.Sleep(time.Millisecond)
+	denied, _ = denylist.IsDenied(ctx, "sid:s")
+	assert.False(t, denied)
+
+	denylist.lastSweep = time.Now().Add(-2 * time.Minute)
+	require.NoError(t, denylist.Deny(ctx, "jti:c", time.Hour))
+	assert.NotContains(t, denylist.entries, "sid:s")
+	assert.Contains(t, denylist.entries, "jti:a")
+}
+
+func TestTokenError(t *testing.T) {
+	reason, ok := TokenError(ErrTokenRevoked)
+	assert.True(t, ok)
+	assert.Equal(t, ErrTokenRevoked, reason)
+
+	_, ok = TokenError(context.DeadlineExceeded)
+	assert.False(t, ok)
+}
@@ -0,0 +1,51 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"errors"
+
+	"github.com/golang-jwt/jwt"
+)
+
+// SigningMethodEdDSA sign the token with ed25519 (RFC 8037), golang-jwt v3 doesn't have it
+var SigningMethodEdDSA = &signingMethodEdDSA{}
+
+var errEdDSAVerification = errors.New("eddsa: verification error")
+
+type signingMethodEdDSA struct{}
+
+func init() {
+	jwt.RegisterSigningMethod(SigningMethodEdDSA.Alg(), func() jwt.SigningMethod {
+		return SigningMethodEdDSA
+	})
+}
+
+func (m *signingMethodEdDSA) Alg() string {
+	return "EdDSA"
+}
+
+func (m *signingMethodEdDSA) Verify(signingString, signature string, key interface{}) error {
+	public, ok := key.(ed25519.PublicKey)
+	if !ok || len(public) != ed25519.PublicKeySize {
+		return jwt.ErrInvalidKeyType
+	}
+
+	sig, err := jwt.DecodeSegment(signature)
+	if err != nil {
+		return err
+	}
+
+	if !ed25519.Verify(public, []byte(signingString), sig) {
+		return errEdDSAVerification
+	}
+	return nil
+}
+
+func (m *signingMethodEdDSA) Sign(signingString string, key interface{}) (string, error) {
+	private, ok := key.(ed25519.PrivateKey)
+	if !ok || len(private) != ed25519.PrivateKeySize {
+		return "", jwt.ErrInvalidKeyType
+	}
+
+	return jwt.EncodeSegment(ed25519.Sign(private, []byte(signingString))), nil
+}
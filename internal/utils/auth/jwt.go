@@ -1,31 +1,41 @@
 package auth
 
 import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"golang-starter/config"
-	"golang-starter/infrastructures/localdb"
-
 	"golang-starter/internal/utils/auth/dto"
 	"golang-starter/internal/utils/encryption"
-	"golang-starter/internal/utils/rsa"
 	"time"
 
 	"github.com/golang-jwt/jwt"
 	"github.com/rs/zerolog/log"
 )
 
+// ErrRefreshTokenReused is returned when a refresh token that was already exchanged is presented again
+var ErrRefreshTokenReused = errors.New("refresh token is already used")
+
 type JwtToken interface {
-	Sign(claims jwt.MapClaims) dto.Token
-	SignRSA(claims jwt.MapClaims) dto.Token
+	Sign(ctx context.Context, claims Claims) (dto.Token, error)
+	// VerifyRefreshToken check that tokenId is the jti of the refresh token stored for the session
+	VerifyRefreshToken(ctx context.Context, userId, sessionId, tokenId string) error
+	// RefreshTokenActive report whether tokenId is the jti of the refresh token stored for the session
+	RefreshTokenActive(ctx context.Context, userId, sessionId, tokenId string) (bool, error)
 }
 
 type JwtTokenImpl struct {
 	jwtTokenTimeExp        time.Duration
 	jwtRefreshTokenTimeExp time.Duration
-	cached                 *localdb.ScribleImpl
+	tokenStore             TokenStore
+	signer                 *Signer
+	encrypter              *encryption.Encrypter
 }
 
-func NewJwt(cached *localdb.ScribleImpl) *JwtTokenImpl {
+func NewJwt(tokenStore TokenStore, signer *Signer, encrypter *encryption.Encrypter) *JwtTokenImpl {
 	jwtTokenDuration, err := time.ParseDuration(config.Get().Auth.JwtToken.Expired)
 	if err != nil {
 		log.Err(err).Msg(config.Get().Auth.JwtToken.Expired)
@@ -35,178 +45,152 @@ func NewJwt(cached *localdb.ScribleImpl) *JwtTokenImpl {
 		log.Err(err).Msg(config.Get().Auth.JwtToken.RefreshExpired)
 	}
 	return &JwtTokenImpl{
-		cached:                 cached,
+		tokenStore:             tokenStore,
+		signer:                 signer,
+		encrypter:              encrypter,
 		jwtTokenTimeExp:        jwtTokenDuration,
 		jwtRefreshTokenTimeExp: jwtRefreshDuration,
 	}
 }
 
-func (o JwtTokenImpl) Sign(claims jwt.MapClaims) dto.Token {
-	timeNow := time.Now()
-	tokenExpired := timeNow.Add(o.jwtTokenTimeExp).Unix()
-
-	if claims["id"] == nil {
-		return dto.Token{}
-	}
-
-	token := jwt.New(jwt.SigningMethodHS256)
-	// setup userdata
-	var _, checkExp = claims["exp"]
-	var _, checkIat = claims["exp"]
-
-	// if user didn't define claims expired
-	if !checkExp {
-		claims["exp"] = tokenExpired
-	}
-	// if user didn't define claims iat
-	if !checkIat {
-		claims["iat"] = timeNow.Unix()
+// Sign ins method to generate jwt token and refresh token
+// claims only need the Subject and Role, the registered claims are set here
+// a refresh keep the SessionID of the refreshed token, a login start a new session
+// the token is signed by the algorithm of AUTH.JWT_TOKEN.ALGORITHM, the refresh token is stored before it's returned.
+// it replace the stored refresh token of the session, so the previous one can't be used anymore
+func (o JwtTokenImpl) Sign(ctx context.Context, claims Claims) (dto.Token, error) {
+	if claims.Subject == "" {
+		return dto.Token{}, ErrTokenSubject
 	}
-	claims["token_type"] = "access_token"
-
-	token.Claims = claims
-
-	authToken := new(dto.Token)
-	tokenString, err := token.SignedString([]byte(config.Get().Application.Key.Default))
 
+	timeNow := time.Now()
+	cfg := config.Get().Auth.JwtToken
+	claims.Issuer = cfg.Issuer
+	claims.Audience = cfg.Audience
+	claims.IssuedAt = timeNow.Unix()
+	claims.NotBefore = timeNow.Unix()
+	if claims.SessionID == "" {
+		claims.SessionID = NewSessionID()
+	}
+
+	accessClaims := claims
+	accessClaims.ID = newTokenID()
+	accessClaims.ExpiresAt = timeNow.Add(o.jwtTokenTimeExp).Unix()
+	accessClaims.TokenType = TokenTypeAccess
+	tokenString, err := o.signer.SignedString(&accessClaims)
 	if err != nil {
-		log.Err(err)
-		return dto.Token{}
+		return dto.Token{}, fmt.Errorf("sign token: %v", err)
 	}
 
-	authToken.Token = tokenString
-	authToken.Type = "Bearer"
-
 	//create refresh token
-	refreshToken := jwt.New(jwt.SigningMethodHS256)
-	refreshTokenExpired := timeNow.Add(o.jwtRefreshTokenTimeExp).Unix()
-
-	claims["exp"] = refreshTokenExpired
-	claims["token_type"] = "refresh_token"
-	refreshToken.Claims = claims
-
-	refreshTokenString, err := refreshToken.SignedString([]byte(config.Get().Application.Key.Default))
+	refreshClaims := claims
+	refreshClaims.ID = newTokenID()
+	refreshClaims.ExpiresAt = timeNow.Add(o.jwtRefreshTokenTimeExp).Unix()
+	refreshClaims.TokenType = TokenTypeRefresh
+	refreshTokenString, err := o.signer.SignedString(&refreshClaims)
+	if err != nil {
+		return dto.Token{}, fmt.Errorf("sign refresh token: %v", err)
+	}
 
+	encryptedRefreshToken, err := o.encrypter.Encrypt(refreshTokenString)
 	if err != nil {
-		return dto.Token{}
-	}
-	authToken.RefreshToken = refreshTokenString
-
-	//save token to redis db
-	go func() {
-		encryptedRefreshToken, err := encryption.AesCFBEncryption(refreshTokenString, config.Get().Application.Key.Default)
-		if err != nil {
-			log.Err(err)
-		}
-		// check data type of the claims
-		switch claims["id"].(type) {
-		case int:
-			claims["id"] = fmt.Sprintf("%d", claims["id"].(int))
-		case int32:
-			claims["id"] = fmt.Sprintf("%d", claims["id"].(int32))
-		case float64:
-			claims["id"] = fmt.Sprintf("%d", int(claims["id"].(float64)))
-		default:
-		}
-		o.cached.DB().Write("refresh_token", claims["id"].(string), dto.RefreshToken{RefreshToken: encryptedRefreshToken, Expired: refreshTokenExpired})
-		if err != nil {
-			log.Err(err).Msgf("Failed to save refresh token to scrible")
-		} else {
-			log.Info().Msg("Successfully to save refresh token to scrible")
-		}
-	}()
+		return dto.Token{}, fmt.Errorf("encrypt refresh token: %v", err)
+	}
+	err = o.tokenStore.SaveRefreshToken(ctx, claims.Subject, claims.SessionID, dto.RefreshToken{
+		RefreshToken: encryptedRefreshToken,
+		Expired:      refreshClaims.ExpiresAt,
+	})
+	if err != nil {
+		return dto.Token{}, fmt.Errorf("save refresh token: %v", err)
+	}
 
 	return dto.Token{
-		Type:         config.Get().Auth.JwtToken.Type,
-		Token:        authToken.Token,
-		RefreshToken: authToken.RefreshToken,
-	}
+		Type:         "Bearer",
+		Token:        tokenString,
+		RefreshToken: refreshTokenString,
+	}, nil
 }
 
-// Sign ins method to generate jwt token and refresh token
-// it has ... parameter
-// userdata is map data, it's using for passing user data
-// default expired time is 60 second
-func (o JwtTokenImpl) SignRSA(claims jwt.MapClaims) dto.Token {
-	timeNow := time.Now()
-	tokenExpired := timeNow.Add(o.jwtTokenTimeExp).Unix()
+// VerifyRefreshToken compare tokenId with the jti of the stored refresh token of the session, every refresh replace
+// the stored one so each refresh token can be used once. a refresh token that isn't the stored one was already used,
+// it may be stolen so the session is removed and ErrRefreshTokenReused is returned
+func (o JwtTokenImpl) VerifyRefreshToken(ctx context.Context, userId, sessionId, tokenId string) error {
+	storedTokenId, err := o.storedRefreshTokenID(ctx, userId, sessionId)
+	if err != nil {
+		return err
+	}
+	if sameTokenID(storedTokenId, tokenId) {
+		return nil
+	}
 
-	if claims["id"] == nil {
-		return dto.Token{}
+	if err := o.tokenStore.DeleteRefreshToken(ctx, userId, sessionId); err != nil {
+		return fmt.Errorf("delete reused session: %v", err)
 	}
+	return ErrRefreshTokenReused
+}
 
-	token := jwt.New(jwt.SigningMethodRS256)
-	// setup userdata
-	var _, checkExp = claims["exp"]
-	var _, checkIat = claims["exp"]
+// RefreshTokenActive is VerifyRefreshToken without removing the session, a used refresh token is only inactive.
+// a session that doesn't exist or is expired is inactive too
+func (o JwtTokenImpl) RefreshTokenActive(ctx context.Context, userId, sessionId, tokenId string) (bool, error) {
+	storedTokenId, err := o.storedRefreshTokenID(ctx, userId, sessionId)
+	if err == ErrRefreshTokenNotFound || err == ErrTokenExpired {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return sameTokenID(storedTokenId, tokenId), nil
+}
 
-	// if user didn't define claims expired
-	if !checkExp {
-		claims["exp"] = tokenExpired
+// storedRefreshTokenID return the jti of the refresh token stored for the session, ErrTokenExpired once it expired
+func (o JwtTokenImpl) storedRefreshTokenID(ctx context.Context, userId, sessionId string) (string, error) {
+	refreshToken, err := o.tokenStore.FindRefreshToken(ctx, userId, sessionId)
+	if err != nil {
+		return "", err
 	}
-	// if user didn't define claims iat
-	if !checkIat {
-		claims["iat"] = timeNow.Unix()
+	if refreshToken.Expired < time.Now().Unix() {
+		return "", ErrTokenExpired
 	}
-	claims["token_type"] = "access_token"
 
-	token.Claims = claims
-	authToken := new(dto.Token)
-	privateRsa, err := rsa.ReadPrivateKeyFromEnv(config.Get().Application.Key.Rsa.Private)
+	tokenId, err := o.refreshTokenID(refreshToken)
 	if err != nil {
-		log.Err(err).Msg("err read private key rsa from env")
-		return dto.Token{}
+		return "", fmt.Errorf("read stored refresh token: %v", err)
 	}
-	tokenString, err := token.SignedString(privateRsa)
+	return tokenId, nil
+}
+
+func sameTokenID(storedTokenId, tokenId string) bool {
+	return tokenId != "" && subtle.ConstantTimeCompare([]byte(storedTokenId), []byte(tokenId)) == 1
+}
+
+// refreshTokenID return the jti of the stored refresh token, it's signed by Sign so it isn't verified again
+func (o JwtTokenImpl) refreshTokenID(refreshToken dto.RefreshToken) (string, error) {
+	tokenString, err := o.encrypter.Decrypt(refreshToken.RefreshToken)
 	if err != nil {
-		log.Err(err).Msg("err read private rsa")
-		return dto.Token{}
+		return "", err
 	}
 
-	authToken.Token = tokenString
-	authToken.Type = "Bearer"
+	claims := &Claims{}
+	if _, _, err := new(jwt.Parser).ParseUnverified(tokenString, claims); err != nil {
+		return "", err
+	}
+	return claims.ID, nil
+}
 
-	//create refresh token
-	refreshToken := jwt.New(jwt.SigningMethodRS256)
-	refreshTokenExpired := timeNow.Add(o.jwtRefreshTokenTimeExp).Unix()
+// NewSessionID return a random session id for the sid claims
+func NewSessionID() string {
+	return randomID()
+}
 
-	claims["exp"] = refreshTokenExpired
-	claims["token_type"] = "refresh_token"
-	refreshToken.Claims = claims
-	refreshTokenString, err := refreshToken.SignedString(privateRsa)
-	if err != nil {
-		log.Err(err).Msg("")
-		return dto.Token{}
-	}
-	authToken.RefreshToken = refreshTokenString
-
-	//save token to redis db
-	go func() {
-		encryptedRefreshToken, err := encryption.AesCFBEncryption(refreshTokenString, config.Get().Application.Key.Default)
-		if err != nil {
-			log.Err(err)
-		}
-		// check data type of the claims
-		switch claims["id"].(type) {
-		case int:
-			claims["id"] = fmt.Sprintf("%d", claims["id"].(int))
-		case int32:
-			claims["id"] = fmt.Sprintf("%d", claims["id"].(int32))
-		case float64:
-			claims["id"] = fmt.Sprintf("%d", int(claims["id"].(float64)))
-		default:
-		}
-		o.cached.DB().Write("refresh_token", claims["id"].(string), dto.RefreshToken{RefreshToken: encryptedRefreshToken, Expired: refreshTokenExpired})
-		if err != nil {
-			log.Err(err).Msg("Failed to save refresh token to redis")
-		} else {
-			log.Info().Msg("Successfully to save refresh token to redis")
-		}
-	}()
+// newTokenID return a random id for the jti claims, every token has its own
+func newTokenID() string {
+	return randomID()
+}
 
-	return dto.Token{
-		Type:         "Bearer",
-		Token:        authToken.Token,
-		RefreshToken: authToken.RefreshToken,
+func randomID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		log.Err(err).Msg("err generate random id")
 	}
+	return hex.EncodeToString(b)
 }
@@ -0,0 +1,122 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"golang-starter/internal/utils/auth/dto"
+	"golang-starter/internal/utils/encryption"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryTokenStore map[string]dto.RefreshToken
+
+func (s memoryTokenStore) SaveRefreshToken(_ context.Context, userId, sessionId string, refreshToken dto.RefreshToken) error {
+	s[userId+"/"+sessionId] = refreshToken
+	return nil
+}
+
+func (s memoryTokenStore) FindRefreshToken(_ context.Context, userId, sessionId string) (dto.RefreshToken, error) {
+	refreshToken, ok := s[userId+"/"+sessionId]
+	if !ok {
+		return dto.RefreshToken{}, ErrRefreshTokenNotFound
+	}
+	return refreshToken, nil
+}
+
+func (s memoryTokenStore) DeleteRefreshToken(_ context.Context, userId, sessionId string) error {
+	delete(s, userId+"/"+sessionId)
+	return nil
+}
+
+func (s memoryTokenStore) DeleteUserRefreshTokens(context.Context, string, string) ([]string, error) {
+	return nil, nil
+}
+
+func (s memoryTokenStore) EachRefreshToken(context.Context, func(userId, sessionId string, refreshToken dto.RefreshToken) error) error {
+	return nil
+}
+
+// storeRefreshToken store a refresh token of jti for the session like Sign does
+func storeRefreshToken(t *testing.T, o JwtTokenImpl, jti string, expired time.Time) {
+	tokenString, err := jwt.NewWithClaims(jwt.SigningMethodHS256, &Claims{
+		Subject:   "1",
+		ID:        jti,
+		SessionID: "session",
+		ExpiresAt: expired.Unix(),
+		TokenType: TokenTypeRefresh,
+	}).SignedString([]byte("secret"))
+	require.NoError(t, err)
+
+	encrypted, err := o.encrypter.Encrypt(tokenString)
+	require.NoError(t, err)
+	require.NoError(t, o.tokenStore.SaveRefreshToken(context.Background(), "1", "session",
+		dto.RefreshToken{RefreshToken: encrypted, Expired: expired.Unix()}))
+}
+
+func TestVerifyRefreshTokenRejectsReusedToken(t *testing.T) {
+	ctx := context.Background()
+	encrypter, err := encryption.NewEncrypterFromKeys("k1", map[string][]byte{"k1": bytes.Repeat([]byte("k"), 32)})
+	require.NoError(t, err)
+	store := memoryTokenStore{}
+	o := JwtTokenImpl{tokenStore: store, encrypter: encrypter}
+
+	storeRefreshToken(t, o, "first", time.Now().Add(time.Hour))
+	require.NoError(t, o.VerifyRefreshToken(ctx, "1", "session", "first"))
+
+	// the refresh replaced the stored token, the first one is reused
+	storeRefreshToken(t, o, "second", time.Now().Add(time.Hour))
+	assert.Equal(t, ErrRefreshTokenReused, o.VerifyRefreshToken(ctx, "1", "session", "first"))
+
+	// the session is removed, so the token of the refresh doesn't work either
+	assert.Equal(t, ErrRefreshTokenNotFound, o.VerifyRefreshToken(ctx, "1", "session", "second"))
+	assert.Empty(t, store)
+}
+
+func TestVerifyRefreshToken(t *testing.T) {
+	ctx := context.Background()
+	encrypter, err := encryption.NewEncrypterFromKeys("k1", map[string][]byte{"k1": bytes.Repeat([]byte("k"), 32)})
+	require.NoError(t, err)
+	o := JwtTokenImpl{tokenStore: memoryTokenStore{}, encrypter: encrypter}
+
+	assert.Equal(t, ErrRefreshTokenNotFound, o.VerifyRefreshToken(ctx, "1", "session", "first"))
+
+	storeRefreshToken(t, o, "first", time.Now().Add(-time.Minute))
+	assert.Equal(t, ErrTokenExpired, o.VerifyRefreshToken(ctx, "1", "session", "first"))
+
+	storeRefreshToken(t, o, "first", time.Now().Add(time.Hour))
+	assert.Equal(t, ErrRefreshTokenReused, o.VerifyRefreshToken(ctx, "1", "session", ""))
+}
+
+func TestRefreshTokenActive(t *testing.T) {
+	ctx := context.Background()
+	encrypter, err := encryption.NewEncrypterFromKeys("k1", map[string][]byte{"k1": bytes.Repeat([]byte("k"), 32)})
+	require.NoError(t, err)
+	store := memoryTokenStore{}
+	o := JwtTokenImpl{tokenStore: store, encrypter: encrypter}
+
+	active, err := o.RefreshTokenActive(ctx, "1", "session", "first")
+	require.NoError(t, err)
+	assert.False(t, active)
+
+	storeRefreshToken(t, o, "first", time.Now().Add(time.Hour))
+	active, err = o.RefreshTokenActive(ctx, "1", "session", "first")
+	require.NoError(t, err)
+	assert.True(t, active)
+
+	// the refresh rotated the token, the used one is inactive but the session is kept
+	storeRefreshToken(t, o, "second", time.Now().Add(time.Hour))
+	active, err = o.RefreshTokenActive(ctx, "1", "session", "first")
+	require.NoError(t, err)
+	assert.False(t, active)
+	assert.Len(t, store, 1)
+
+	storeRefreshToken(t, o, "second", time.Now().Add(-time.Minute))
+	active, err = o.RefreshTokenActive(ctx, "1", "session", "second")
+	require.NoError(t, err)
+	assert.False(t, active)
+}
@@ -0,0 +1,99 @@
+package auth
+
+import (
+	"context"
+	"strconv"
+)
+
+// how the caller of a request is authenticated
+const (
+	AuthMethodJwt    = "jwt"
+	AuthMethodApiKey = "api_key"
+)
+
+// Principal is the authenticated caller of a request, it's only set by the auth middleware
+// and never read from the request headers
+type Principal struct {
+	UserId    uint
+	Roles     []string
+	SessionId string
+	Method    string
+	// ApiKeyId and Scopes are only set when Method is AuthMethodApiKey
+	ApiKeyId uint
+	Scopes   []string
+}
+
+// NewJwtPrincipal return the principal of verified token claims
+func NewJwtPrincipal(claims *Claims) (*Principal, error) {
+	userId, err := strconv.ParseUint(claims.Subject, 10, 64)
+	if err != nil {
+		return nil, ErrTokenSubject
+	}
+
+	var roles []string
+	if claims.Role != "" {
+		roles = []string{claims.Role}
+	}
+
+	return &Principal{
+		UserId:    uint(userId),
+		Roles:     roles,
+		SessionId: claims.SessionID,
+		Method:    AuthMethodJwt,
+	}, nil
+}
+
+// NewApiKeyPrincipal return the principal of an authenticated api key
+func NewApiKeyPrincipal(identity *ApiKeyIdentity) *Principal {
+	principal := &Principal{
+		UserId:   uint(identity.UserId),
+		Method:   AuthMethodApiKey,
+		ApiKeyId: uint(identity.ApiKeyId),
+		Scopes:   identity.Scopes,
+	}
+	if identity.Role != "" {
+		principal.Roles = []string{identity.Role}
+	}
+	return principal
+}
+
+// HasRole tell whether the principal has one of roles
+func (p *Principal) HasRole(roles ...string) bool {
+	for _, role := range p.Roles {
+		for _, allowed := range roles {
+			if role == allowed {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// HasScope tell whether the principal is granted one of scopes, a jwt caller isn't limited by scopes
+func (p *Principal) HasScope(scopes ...string) bool {
+	if p.Method != AuthMethodApiKey {
+		return true
+	}
+
+	for _, granted := range p.Scopes {
+		for _, scope := range scopes {
+			if granted == scope {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+type principalContextKey struct{}
+
+// ContextWithPrincipal return a copy of ctx that carries the principal
+func ContextWithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalContextKey{}, principal)
+}
+
+// PrincipalFromContext return the caller of the request, false when the request isn't authenticated
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	principal, ok := ctx.Value(principalContextKey{}).(*Principal)
+	return principal, ok
+}
@@ -0,0 +1,49 @@
+package auth
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewJwtPrincipal(t *testing.T) {
+	principal, err := NewJwtPrincipal(&Claims{Subject: "42", Role: "admin", SessionID: "sid"})
+	require.NoError(t, err)
+	assert.Equal(t, &Principal{UserId: 42, Roles: []string{"admin"}, SessionId: "sid", Method: AuthMethodJwt}, principal)
+
+	_, err = NewJwtPrincipal(&Claims{Subject: "not-a-number"})
+	assert.Equal(t, ErrTokenSubject, err)
+}
+
+func TestNewApiKeyPrincipal(t *testing.T) {
+	principal := NewApiKeyPrincipal(&ApiKeyIdentity{ApiKeyId: 7, UserId: 42, Role: "user", Scopes: []string{"users:read"}})
+	assert.Equal(t, &Principal{
+		UserId:   42,
+		Roles:    []string{"user"},
+		Method:   AuthMethodApiKey,
+		ApiKeyId: 7,
+		Scopes:   []string{"users:read"},
+	}, principal)
+}
+
+func TestPrincipal(t *testing.T) {
+	jwtPrincipal := &Principal{UserId: 1, Roles: []string{"user"}, Method: AuthMethodJwt}
+	apiKeyPrincipal := &Principal{UserId: 1, Roles: []string{"admin"}, Method: AuthMethodApiKey, Scopes: []string{"users:read"}}
+
+	assert.True(t, jwtPrincipal.HasRole("admin", "user"))
+	assert.False(t, jwtPrincipal.HasRole("admin"))
+	assert.False(t, (&Principal{}).HasRole("admin"))
+
+	// a jwt caller isn't limited by scopes, an api key only by its own
+	assert.True(t, jwtPrincipal.HasScope("admin"))
+	assert.True(t, apiKeyPrincipal.HasScope("users:write", "users:read"))
+	assert.False(t, apiKeyPrincipal.HasScope("admin"))
+
+	_, ok := PrincipalFromContext(context.Background())
+	assert.False(t, ok)
+	got, ok := PrincipalFromContext(ContextWithPrincipal(context.Background(), apiKeyPrincipal))
+	assert.True(t, ok)
+	assert.Equal(t, apiKeyPrincipal, got)
+}
@@ -0,0 +1,114 @@
+package auth
+
+import (
+	"fmt"
+	"golang-starter/config"
+	"golang-starter/internal/utils/keyring"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/rs/zerolog/log"
+)
+
+// Signer sign the tokens with the active key of AUTH.JWT_TOKEN.ALGORITHM
+// and verify them with the key of their kid
+type Signer struct {
+	keys *keyring.Provider
+}
+
+// NewSigner stop the application when the configured algorithms are not supported
+func NewSigner(keys *keyring.Provider) *Signer {
+	alg := keyring.SigningAlgorithm()
+	accepted := acceptedAlgorithms()
+
+	var signingAccepted bool
+	for _, a := range accepted {
+		if !keyring.ValidAlgorithm(a) {
+			log.Fatal().Msgf("unsupported jwt algorithm %q, the algorithms are %v", a, keyring.Algorithms)
+		}
+		signingAccepted = signingAccepted || a == alg
+	}
+	if !keyring.ValidAlgorithm(alg) || !signingAccepted {
+		log.Fatal().Msgf("jwt algorithm %q must be supported and accepted", alg)
+	}
+
+	return &Signer{keys: keys}
+}
+
+// SignedString sign the claims with the active key, the kid header tell the verifier which key signed it
+func (s *Signer) SignedString(claims jwt.Claims) (string, error) {
+	alg := keyring.SigningAlgorithm()
+	key, err := s.keys.Keyring().SigningKey(alg)
+	if err != nil {
+		return "", err
+	}
+
+	token := jwt.NewWithClaims(signingMethod(alg), claims)
+	token.Header["kid"] = key.Kid
+	return token.SignedString(key.SignKey())
+}
+
+// Parse verify the tokenType token with the key of its kid and validate its claims. only the accepted algorithms are allowed
+// and the algorithm must be the one of the key, so a public key can't be used as a HS256 secret
+func (s *Signer) Parse(tokenString, tokenType string) (*Claims, error) {
+	parser := jwt.Parser{ValidMethods: acceptedAlgorithms(), SkipClaimsValidation: true}
+
+	claims := &Claims{}
+	_, err := parser.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		key, err := s.keys.Keyring().Key(kid)
+		if err != nil {
+			return nil, err
+		}
+		if token.Method.Alg() != key.Alg {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return key.VerifyKey(), nil
+	})
+	if err != nil {
+		if ve, ok := err.(*jwt.ValidationError); ok && ve.Errors&jwt.ValidationErrorMalformed != 0 {
+			return nil, fmt.Errorf("%w: %v", ErrTokenMalformed, err)
+		}
+		return nil, fmt.Errorf("%w: %v", ErrTokenSignature, err)
+	}
+
+	if err := claims.Validate(time.Now(), tokenType, claimsValidation()); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// JWKS return the public keys of the keyring
+func (s *Signer) JWKS() keyring.JWKS {
+	return s.keys.Keyring().JWKS()
+}
+
+func claimsValidation() ClaimsValidation {
+	cfg := config.Get().Auth.JwtToken
+	return ClaimsValidation{
+		Issuer:   cfg.Issuer,
+		Audience: cfg.Audience,
+		Leeway:   cfg.Leeway,
+	}
+}
+
+func acceptedAlgorithms() []string {
+	accepted := config.Get().Auth.JwtToken.AcceptedAlgorithms
+	if len(accepted) == 0 {
+		return []string{keyring.SigningAlgorithm()}
+	}
+	return accepted
+}
+
+func signingMethod(alg string) jwt.SigningMethod {
+	switch alg {
+	case keyring.AlgHS256:
+		return jwt.SigningMethodHS256
+	case keyring.AlgES256:
+		return jwt.SigningMethodES256
+	case keyring.AlgEdDSA:
+		return SigningMethodEdDSA
+	default:
+		return jwt.SigningMethodRS256
+	}
+}
@@ -0,0 +1,46 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"golang-starter/config"
+	"golang-starter/infrastructures/cached"
+	"golang-starter/infrastructures/db"
+	"golang-starter/infrastructures/localdb"
+	"golang-starter/internal/utils/auth/dto"
+
+	"github.com/rs/zerolog/log"
+)
+
+var ErrRefreshTokenNotFound = errors.New("refresh token not found")
+
+// TokenStore keep the refresh token of each session, a session can only be refreshed while its refresh token is stored.
+// the refresh token is removed once it's expired
+type TokenStore interface {
+	// SaveRefreshToken store the refresh token of the session until refreshToken.Expired
+	SaveRefreshToken(ctx context.Context, userId, sessionId string, refreshToken dto.RefreshToken) error
+	// FindRefreshToken return ErrRefreshTokenNotFound when the session doesn't exist or is expired
+	FindRefreshToken(ctx context.Context, userId, sessionId string) (dto.RefreshToken, error)
+	// DeleteRefreshToken remove a session of the user, a session that doesn't exist is already removed
+	DeleteRefreshToken(ctx context.Context, userId, sessionId string) error
+	// DeleteUserRefreshTokens remove every session of the user except exceptSessionId and return the removed ones,
+	// pass an empty exceptSessionId to remove all of them
+	DeleteUserRefreshTokens(ctx context.Context, userId, exceptSessionId string) ([]string, error)
+	// EachRefreshToken call fn for every stored refresh token that isn't expired, it's used by the migrations
+	EachRefreshToken(ctx context.Context, fn func(userId, sessionId string, refreshToken dto.RefreshToken) error) error
+}
+
+// NewTokenStore choose the store based on AUTH.REFRESH_TOKEN.STORE, scribble only works when the app run as a single instance
+func NewTokenStore(scribble *localdb.ScribleImpl, redis *cached.RedisImpl, mysql *db.MysqlImpl) TokenStore {
+	switch config.Get().Auth.RefreshToken.Store {
+	case "redis":
+		if !redis.Enabled() {
+			log.Fatal().Msg("refresh token store is redis but redis is disabled")
+		}
+		return newInstrumentedTokenStore("redis", NewRedisTokenStore(redis))
+	case "mysql":
+		return newInstrumentedTokenStore("mysql", NewMysqlTokenStore(mysql))
+	default:
+		return newInstrumentedTokenStore("scribble", NewScribbleTokenStore(scribble))
+	}
+}
@@ -0,0 +1,72 @@
+package auth
+
+import (
+	"context"
+	"golang-starter/internal/utils/auth/dto"
+	"golang-starter/internal/utils/metrics"
+	"time"
+)
+
+var (
+	tokenStoreOperations = metrics.NewCounter("refresh_token_store_operations_total",
+		"the operations of the refresh token store by their result", "store", "operation", "result")
+	tokenStoreDuration = metrics.NewHistogram("refresh_token_store_duration_seconds",
+		"the latency of the operations of the refresh token store", nil, "store", "operation")
+)
+
+// instrumentedTokenStore record the operations of a store, a refresh token that isn't found is not an error
+type instrumentedTokenStore struct {
+	store TokenStore
+	name  string
+}
+
+func newInstrumentedTokenStore(name string, store TokenStore) TokenStore {
+	return &instrumentedTokenStore{store: store, name: name}
+}
+
+func (s *instrumentedTokenStore) observe(operation string, start time.Time, err error) {
+	result := "success"
+	switch {
+	case err == ErrRefreshTokenNotFound:
+		result = "not_found"
+	case err != nil:
+		result = "error"
+	}
+	tokenStoreOperations.Inc(s.name, operation, result)
+	tokenStoreDuration.ObserveSince(start, s.name, operation)
+}
+
+func (s *instrumentedTokenStore) SaveRefreshToken(ctx context.Context, userId, sessionId string, refreshToken dto.RefreshToken) error {
+	start := time.Now()
+	err := s.store.SaveRefreshToken(ctx, userId, sessionId, refreshToken)
+	s.observe("save", start, err)
+	return err
+}
+
+func (s *instrumentedTokenStore) FindRefreshToken(ctx context.Context, userId, sessionId string) (dto.RefreshToken, error) {
+	start := time.Now()
+	refreshToken, err := s.store.FindRefreshToken(ctx, userId, sessionId)
+	s.observe("find", start, err)
+	return refreshToken, err
+}
+
+func (s *instrumentedTokenStore) DeleteRefreshToken(ctx context.Context, userId, sessionId string) error {
+	start := time.Now()
+	err := s.store.DeleteRefreshToken(ctx, userId, sessionId)
+	s.observe("delete", start, err)
+	return err
+}
+
+func (s *instrumentedTokenStore) DeleteUserRefreshTokens(ctx context.Context, userId, exceptSessionId string) ([]string, error) {
+	start := time.Now()
+	sessionIds, err := s.store.DeleteUserRefreshTokens(ctx, userId, exceptSessionId)
+	s.observe("delete_user", start, err)
+	return sessionIds, err
+}
+
+func (s *instrumentedTokenStore) EachRefreshToken(ctx context.Context, fn func(userId, sessionId string, refreshToken dto.RefreshToken) error) error {
+	start := time.Now()
+	err := s.store.EachRefreshToken(ctx, fn)
+	s.observe("each", start, err)
+	return err
+}
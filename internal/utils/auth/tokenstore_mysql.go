@@ -0,0 +1,100 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"golang-starter/infrastructures/db"
+	"golang-starter/internal/utils/auth/dto"
+	"time"
+
+	"github.com/nurcahyaari/sqlabst"
+)
+
+// mysql has no expiry, the expired sessions are never returned and they are removed when the user get a new one.
+// the times of the refresh_tokens table are in milliseconds like the other tables
+type MysqlTokenStore struct {
+	db *sqlabst.SqlAbst
+}
+
+func NewMysqlTokenStore(mysql *db.MysqlImpl) *MysqlTokenStore {
+	return &MysqlTokenStore{
+		db: mysql.DB,
+	}
+}
+
+func (s MysqlTokenStore) SaveRefreshToken(ctx context.Context, userId, sessionId string, refreshToken dto.RefreshToken) error {
+	now := time.Now()
+	_, err := s.db.ExecContext(ctx, `INSERT INTO refresh_tokens (user_fkid, session_id, refresh_token, expired_at, created_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE refresh_token = VALUES(refresh_token), expired_at = VALUES(expired_at)`,
+		userId, sessionId, refreshToken.RefreshToken, refreshToken.Expired*1000, now.UnixNano()/int64(time.Millisecond))
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.ExecContext(ctx, `DELETE FROM refresh_tokens WHERE user_fkid = ? AND expired_at < ?`,
+		userId, now.UnixNano()/int64(time.Millisecond))
+	return err
+}
+
+func (s MysqlTokenStore) FindRefreshToken(ctx context.Context, userId, sessionId string) (dto.RefreshToken, error) {
+	var refreshToken dto.RefreshToken
+	err := s.db.QueryRowContext(ctx, `SELECT refresh_token, expired_at DIV 1000 FROM refresh_tokens
+		WHERE user_fkid = ? AND session_id = ? AND expired_at >= ?`,
+		userId, sessionId, time.Now().UnixNano()/int64(time.Millisecond)).
+		Scan(&refreshToken.RefreshToken, &refreshToken.Expired)
+	if err == sql.ErrNoRows {
+		return dto.RefreshToken{}, ErrRefreshTokenNotFound
+	}
+	if err != nil {
+		return dto.RefreshToken{}, err
+	}
+	return refreshToken, nil
+}
+
+func (s MysqlTokenStore) DeleteRefreshToken(ctx context.Context, userId, sessionId string) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM refresh_tokens WHERE user_fkid = ? AND session_id = ?`, userId, sessionId)
+	return err
+}
+
+func (s MysqlTokenStore) EachRefreshToken(ctx context.Context, fn func(userId, sessionId string, refreshToken dto.RefreshToken) error) error {
+	var rows []struct {
+		UserId       string `db:"user_fkid"`
+		SessionId    string `db:"session_id"`
+		RefreshToken string `db:"refresh_token"`
+		Expired      int64  `db:"expired"`
+	}
+	// the rows are read before fn is called, so fn can write the table
+	err := s.db.SelectContext(ctx, &rows, `SELECT user_fkid, session_id, refresh_token, expired_at DIV 1000 AS expired
+		FROM refresh_tokens WHERE expired_at >= ?`, time.Now().UnixNano()/int64(time.Millisecond))
+	if err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		err := fn(row.UserId, row.SessionId, dto.RefreshToken{RefreshToken: row.RefreshToken, Expired: row.Expired})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s MysqlTokenStore) DeleteUserRefreshTokens(ctx context.Context, userId, exceptSessionId string) ([]string, error) {
+	var sessionIds []string
+	err := s.db.SelectContext(ctx, &sessionIds, `SELECT session_id FROM refresh_tokens WHERE user_fkid = ? AND session_id <> ?`,
+		userId, exceptSessionId)
+	if err != nil {
+		return nil, err
+	}
+
+	var deleted []string
+	for _, sessionId := range sessionIds {
+		if err := s.DeleteRefreshToken(ctx, userId, sessionId); err != nil {
+			return deleted, err
+		}
+		deleted = append(deleted, sessionId)
+	}
+
+	return deleted, nil
+}
@@ -0,0 +1,121 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"golang-starter/infrastructures/cached"
+	"golang-starter/internal/utils/auth/dto"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+const (
+	refreshTokenRedisPrefix  = "refresh_token:"
+	refreshTokensRedisPrefix = "refresh_tokens:"
+)
+
+// each refresh token expire by its own ttl, the set of the user sessions is only used to remove all of them
+type RedisTokenStore struct {
+	redis *cached.RedisImpl
+}
+
+func NewRedisTokenStore(redis *cached.RedisImpl) *RedisTokenStore {
+	return &RedisTokenStore{
+		redis: redis,
+	}
+}
+
+func (s RedisTokenStore) SaveRefreshToken(ctx context.Context, userId, sessionId string, refreshToken dto.RefreshToken) error {
+	ttl := time.Until(time.Unix(refreshToken.Expired, 0))
+	if ttl <= 0 {
+		return nil
+	}
+
+	data, err := json.Marshal(refreshToken)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.redis.DB().TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, refreshTokenRedisKey(userId, sessionId), data, ttl)
+		pipe.SAdd(ctx, refreshTokensRedisPrefix+userId, sessionId)
+		// the newest session live the longest, the set doesn't need to outlive it
+		pipe.Expire(ctx, refreshTokensRedisPrefix+userId, ttl)
+		return nil
+	})
+	return err
+}
+
+func (s RedisTokenStore) FindRefreshToken(ctx context.Context, userId, sessionId string) (dto.RefreshToken, error) {
+	data, err := s.redis.DB().Get(ctx, refreshTokenRedisKey(userId, sessionId)).Bytes()
+	if err == redis.Nil {
+		return dto.RefreshToken{}, ErrRefreshTokenNotFound
+	}
+	if err != nil {
+		return dto.RefreshToken{}, err
+	}
+
+	var refreshToken dto.RefreshToken
+	if err := json.Unmarshal(data, &refreshToken); err != nil {
+		return dto.RefreshToken{}, err
+	}
+	return refreshToken, nil
+}
+
+func (s RedisTokenStore) DeleteRefreshToken(ctx context.Context, userId, sessionId string) error {
+	_, err := s.redis.DB().TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, refreshTokenRedisKey(userId, sessionId))
+		pipe.SRem(ctx, refreshTokensRedisPrefix+userId, sessionId)
+		return nil
+	})
+	return err
+}
+
+func (s RedisTokenStore) DeleteUserRefreshTokens(ctx context.Context, userId, exceptSessionId string) ([]string, error) {
+	sessionIds, err := s.redis.DB().SMembers(ctx, refreshTokensRedisPrefix+userId).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	var deleted []string
+	for _, sessionId := range sessionIds {
+		if sessionId == exceptSessionId {
+			continue
+		}
+
+		if err := s.DeleteRefreshToken(ctx, userId, sessionId); err != nil {
+			return deleted, err
+		}
+		deleted = append(deleted, sessionId)
+	}
+
+	return deleted, nil
+}
+
+func (s RedisTokenStore) EachRefreshToken(ctx context.Context, fn func(userId, sessionId string, refreshToken dto.RefreshToken) error) error {
+	iter := s.redis.DB().Scan(ctx, 0, refreshTokenRedisPrefix+"*", 100).Iterator()
+	for iter.Next(ctx) {
+		ids := strings.SplitN(strings.TrimPrefix(iter.Val(), refreshTokenRedisPrefix), ":", 2)
+		if len(ids) != 2 {
+			continue
+		}
+
+		refreshToken, err := s.FindRefreshToken(ctx, ids[0], ids[1])
+		if err == ErrRefreshTokenNotFound {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if err := fn(ids[0], ids[1], refreshToken); err != nil {
+			return err
+		}
+	}
+	return iter.Err()
+}
+
+func refreshTokenRedisKey(userId, sessionId string) string {
+	return refreshTokenRedisPrefix + userId + ":" + sessionId
+}
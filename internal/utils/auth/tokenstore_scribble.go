@@ -0,0 +1,146 @@
+package auth
+
+import (
+	"context"
+	"golang-starter/infrastructures/localdb"
+	"golang-starter/internal/utils/auth/dto"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// scribble has no expiry, the expired sessions of a user are removed when the user get a new one
+type ScribbleTokenStore struct {
+	scribbleDB *localdb.ScribleImpl
+}
+
+func NewScribbleTokenStore(scribbleDB *localdb.ScribleImpl) *ScribbleTokenStore {
+	return &ScribbleTokenStore{
+		scribbleDB: scribbleDB,
+	}
+}
+
+func (s ScribbleTokenStore) SaveRefreshToken(ctx context.Context, userId, sessionId string, refreshToken dto.RefreshToken) error {
+	if err := s.scribbleDB.DB().Write(refreshTokenCollection(userId), sessionId, refreshToken); err != nil {
+		return err
+	}
+	return s.removeExpired(userId)
+}
+
+func (s ScribbleTokenStore) FindRefreshToken(ctx context.Context, userId, sessionId string) (dto.RefreshToken, error) {
+	if !s.exists(userId, sessionId) {
+		return dto.RefreshToken{}, ErrRefreshTokenNotFound
+	}
+
+	var refreshToken dto.RefreshToken
+	if err := s.scribbleDB.DB().Read(refreshTokenCollection(userId), sessionId, &refreshToken); err != nil {
+		return dto.RefreshToken{}, err
+	}
+	if refreshToken.Expired < time.Now().Unix() {
+		return dto.RefreshToken{}, ErrRefreshTokenNotFound
+	}
+	return refreshToken, nil
+}
+
+func (s ScribbleTokenStore) DeleteRefreshToken(ctx context.Context, userId, sessionId string) error {
+	if !s.exists(userId, sessionId) {
+		return nil
+	}
+	return s.scribbleDB.DB().Delete(refreshTokenCollection(userId), sessionId)
+}
+
+func (s ScribbleTokenStore) DeleteUserRefreshTokens(ctx context.Context, userId, exceptSessionId string) ([]string, error) {
+	collection := refreshTokenCollection(userId)
+	sessionIds, err := s.sessionIds(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	var deleted []string
+	for _, sessionId := range sessionIds {
+		if sessionId == exceptSessionId {
+			continue
+		}
+
+		if err := s.scribbleDB.DB().Delete(collection, sessionId); err != nil {
+			return deleted, err
+		}
+		deleted = append(deleted, sessionId)
+	}
+
+	return deleted, nil
+}
+
+func (s ScribbleTokenStore) EachRefreshToken(ctx context.Context, fn func(userId, sessionId string, refreshToken dto.RefreshToken) error) error {
+	files, err := filepath.Glob(filepath.Join(s.scribbleDB.Dir(), refreshTokenCollection("*"), "*.json"))
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().Unix()
+	for _, file := range files {
+		userId := filepath.Base(filepath.Dir(file))
+		sessionId := strings.TrimSuffix(filepath.Base(file), ".json")
+
+		var refreshToken dto.RefreshToken
+		if err := s.scribbleDB.DB().Read(refreshTokenCollection(userId), sessionId, &refreshToken); err != nil {
+			return err
+		}
+		if refreshToken.Expired < now {
+			continue
+		}
+		if err := fn(userId, sessionId, refreshToken); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// removeExpired remove the expired sessions of the user
+func (s ScribbleTokenStore) removeExpired(userId string) error {
+	sessionIds, err := s.sessionIds(userId)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().Unix()
+	for _, sessionId := range sessionIds {
+		var refreshToken dto.RefreshToken
+		if err := s.scribbleDB.DB().Read(refreshTokenCollection(userId), sessionId, &refreshToken); err != nil {
+			return err
+		}
+		if refreshToken.Expired >= now {
+			continue
+		}
+		if err := s.scribbleDB.DB().Delete(refreshTokenCollection(userId), sessionId); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sessionIds list the sessions of the user, scribble doesn't expose a way to list the resources of a collection
+func (s ScribbleTokenStore) sessionIds(userId string) ([]string, error) {
+	files, err := filepath.Glob(filepath.Join(s.scribbleDB.Dir(), refreshTokenCollection(userId), "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	sessionIds := make([]string, 0, len(files))
+	for _, file := range files {
+		sessionIds = append(sessionIds, strings.TrimSuffix(filepath.Base(file), ".json"))
+	}
+	return sessionIds, nil
+}
+
+func (s ScribbleTokenStore) exists(userId, sessionId string) bool {
+	_, err := os.Stat(filepath.Join(s.scribbleDB.Dir(), refreshTokenCollection(userId), sessionId+".json"))
+	return err == nil
+}
+
+// refreshTokenCollection return the scribble collection of the user refresh tokens,
+// each session of the user is stored as a resource named by its session id
+func refreshTokenCollection(userId string) string {
+	return "refresh_token/" + userId
+}
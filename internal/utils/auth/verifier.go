@@ -0,0 +1,120 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"golang-starter/config"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+var ErrTokenRevoked = errors.New("token has been revoked")
+
+var tokenErrors = []error{
+	ErrTokenMalformed,
+	ErrTokenSignature,
+	ErrTokenExpired,
+	ErrTokenNotValidYet,
+	ErrTokenUsedBeforeIssued,
+	ErrTokenIssuer,
+	ErrTokenAudience,
+	ErrTokenType,
+	ErrTokenSubject,
+	ErrTokenRevoked,
+}
+
+// TokenError return the reason the token is rejected, false when err isn't about the token
+// but about verifying it, like the denylist being unreachable
+func TokenError(err error) (error, bool) {
+	for _, tokenErr := range tokenErrors {
+		if errors.Is(err, tokenErr) {
+			return tokenErr, true
+		}
+	}
+	return nil, false
+}
+
+// TokenRevoker revoke the tokens before they expire
+type TokenRevoker interface {
+	// RevokeToken deny the token of claims until it expires
+	RevokeToken(ctx context.Context, claims *Claims) error
+	// RevokeSessions deny the access tokens of sessionIds, the refresh tokens of them must be removed by the caller
+	RevokeSessions(ctx context.Context, sessionIds ...string) error
+}
+
+// TokenVerifier verify the tokens against the signature, the claims and the denylist
+type TokenVerifier interface {
+	TokenRevoker
+	Verify(ctx context.Context, tokenString, tokenType string) (*Claims, error)
+}
+
+// Verifier is the TokenVerifier of the tokens signed by Signer
+type Verifier struct {
+	signer             *Signer
+	denylist           Denylist
+	accessTokenExpired time.Duration
+}
+
+func NewVerifier(signer *Signer, denylist Denylist) *Verifier {
+	accessTokenExpired, err := time.ParseDuration(config.Get().Auth.JwtToken.Expired)
+	if err != nil {
+		log.Err(err).Msg(config.Get().Auth.JwtToken.Expired)
+	}
+
+	return &Verifier{
+		signer:             signer,
+		denylist:           denylist,
+		accessTokenExpired: accessTokenExpired,
+	}
+}
+
+// Verify parse the tokenType token and reject it when the token or its session is revoked
+func (v *Verifier) Verify(ctx context.Context, tokenString, tokenType string) (*Claims, error) {
+	claims, err := v.signer.Parse(tokenString, tokenType)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := []string{denylistTokenKey(claims.ID)}
+	if claims.SessionID != "" {
+		keys = append(keys, denylistSessionKey(claims.SessionID))
+	}
+	denied, err := v.denylist.IsDenied(ctx, keys...)
+	if err != nil {
+		return nil, err
+	}
+	if denied {
+		return nil, ErrTokenRevoked
+	}
+
+	return claims, nil
+}
+
+func (v *Verifier) RevokeToken(ctx context.Context, claims *Claims) error {
+	// the token is accepted until exp plus the leeway, the denylist must cover it as well
+	ttl := time.Until(time.Unix(claims.ExpiresAt, 0)) + config.Get().Auth.JwtToken.Leeway
+	if ttl <= 0 {
+		return nil
+	}
+	return v.denylist.Deny(ctx, denylistTokenKey(claims.ID), ttl)
+}
+
+func (v *Verifier) RevokeSessions(ctx context.Context, sessionIds ...string) error {
+	// without the refresh token, the session only live as long as its latest access token
+	ttl := v.accessTokenExpired + config.Get().Auth.JwtToken.Leeway
+	for _, sessionId := range sessionIds {
+		if err := v.denylist.Deny(ctx, denylistSessionKey(sessionId), ttl); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func denylistTokenKey(jti string) string {
+	return "jti:" + jti
+}
+
+func denylistSessionKey(sid string) string {
+	return "sid:" + sid
+}
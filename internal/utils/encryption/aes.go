@@ -4,82 +4,164 @@ import (
 	"crypto/aes"
 	"crypto/cipher"
 	"crypto/rand"
-	"encoding/hex"
+	"encoding/base64"
+	"errors"
 	"fmt"
+	"golang-starter/config"
+	"golang-starter/internal/utils/keyring"
 	"io"
+	"strings"
+
+	"github.com/rs/zerolog/log"
 )
 
-func AddKeyLen(encryptionKey string) string {
-	var min int
-	encryptionKeyLen := len(encryptionKey)
-	if encryptionKeyLen < 32 {
-		min = 32 - encryptionKeyLen
-	} else if encryptionKeyLen > 32 {
-		min = encryptionKeyLen - 32
-	}
-	return encryptionKey + encryptionKey[0:min]
-}
+// envelopeVersion prefix the ciphertext, the legacy hex ciphertext of AES-CFB has no prefix
+const envelopeVersion = "v1"
+
+// DefaultKeyId is the key id of APPLICATION.KEY.DEFAULT when APPLICATION.ENCRYPTION.KEYS is empty
+const DefaultKeyId = "default"
 
-// encryptionKey should have 32 of length
-func AesCFBDecryption(text string, encryptionKey string) (string, error) {
-	encryptionKey = AddKeyLen(encryptionKey)
+const keySize = 32
 
-	key, _ := hex.DecodeString(
-		encryptionKey,
-	)
-	ciphertext, _ := hex.DecodeString(text)
+var (
+	ErrMalformed  = errors.New("encryption: malformed ciphertext")
+	ErrUnknownKey = errors.New("encryption: unknown key id")
+	ErrDecrypt    = errors.New("encryption: cannot decrypt, the ciphertext is tampered or the key is wrong")
+)
+
+// Encrypter encrypt with AES-256-GCM into the envelope v1:<key id>:<base64url nonce and ciphertext>.
+// the active key encrypts, every key decrypts the envelope of its id, so the keys can be rotated
+type Encrypter struct {
+	active string
+	keys   map[string]cipher.AEAD
+}
 
-	block, err := aes.NewCipher(key)
+// NewEncrypter load the keys of APPLICATION.ENCRYPTION, it stops the application when a key is invalid
+func NewEncrypter() *Encrypter {
+	encrypter, err := LoadEncrypter()
 	if err != nil {
-		return "", err
+		log.Fatal().Err(err).Msg("cannot load the encryption keys")
+	}
+	return encrypter
+}
+
+// LoadEncrypter load the keys of APPLICATION.ENCRYPTION, the key values are the base64 of 32 bytes
+// either inline, file:<path> or env:<name>. without any key, APPLICATION.KEY.DEFAULT is the key of DefaultKeyId
+func LoadEncrypter() (*Encrypter, error) {
+	cfg := config.Get().Application
+
+	keys := map[string][]byte{}
+	for kid, source := range cfg.Encryption.Keys {
+		value, err := keyring.ReadKeySource(source)
+		if err != nil {
+			return nil, fmt.Errorf("encryption key %s: %v", kid, err)
+		}
+		key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("encryption key %s is not base64: %v", kid, err)
+		}
+		keys[kid] = key
 	}
 
-	// The IV needs to be unique, but not secure. Therefore it's common to
-	// include it at the beginning of the ciphertext.
-	if len(ciphertext) < aes.BlockSize {
-		panic("ciphertext too short")
+	active := cfg.Encryption.Active
+	if len(keys) == 0 {
+		active = DefaultKeyId
+		keys[DefaultKeyId] = []byte(cfg.Key.Default)
 	}
-	iv := ciphertext[:aes.BlockSize]
-	ciphertext = ciphertext[aes.BlockSize:]
 
-	stream := cipher.NewCFBDecrypter(block, iv)
+	return NewEncrypterFromKeys(active, keys)
+}
 
-	// XORKeyStream can work in-place if the two arguments are the same.
-	stream.XORKeyStream(ciphertext, ciphertext)
-	// fmt.Printf("%s", ciphertext)
-	// Output: some plaintext
+// NewEncrypterFromKeys create the encrypter of keys, each key must be 32 bytes and active must be one of them
+func NewEncrypterFromKeys(active string, keys map[string][]byte) (*Encrypter, error) {
+	encrypter := &Encrypter{
+		active: active,
+		keys:   map[string]cipher.AEAD{},
+	}
+
+	for kid, key := range keys {
+		if kid == "" || strings.Contains(kid, ":") {
+			return nil, fmt.Errorf("encryption key id %q must not be empty or contain ':'", kid)
+		}
+		if len(key) != keySize {
+			return nil, fmt.Errorf("encryption key %s must be %d bytes, it's %d", kid, keySize, len(key))
+		}
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, err
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+		encrypter.keys[kid] = aead
+	}
 
-	return string(ciphertext), nil
+	if _, ok := encrypter.keys[active]; !ok {
+		return nil, fmt.Errorf("the active encryption key %q is not configured", active)
+	}
+	return encrypter, nil
 }
 
-// ecryptionKey length must 32
-func AesCFBEncryption(text string, encryptionKey string) (string, error) {
-	encryptionKey = AddKeyLen(encryptionKey)
-	// Load secret key from app config
-	key := []byte(encryptionKey)
+// Encrypt seal plaintext with the active key
+func (e *Encrypter) Encrypt(plaintext string) (string, error) {
+	aead := e.keys[e.active]
+
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
 
-	plaintext := []byte(text)
-	block, err := aes.NewCipher(key)
+	// the key id is authenticated as well, so an envelope can't be moved to another key
+	sealed := aead.Seal(nonce, nonce, []byte(plaintext), []byte(e.active))
+	return strings.Join([]string{envelopeVersion, e.active, base64.RawURLEncoding.EncodeToString(sealed)}, ":"), nil
+}
 
+// Decrypt open the envelope with the key of its id
+func (e *Encrypter) Decrypt(envelope string) (string, error) {
+	kid, sealed, err := parseEnvelope(envelope)
 	if err != nil {
 		return "", err
 	}
 
-	// The IV needs to be unique, but not secure. Therefore it's common to
-	// include it at the beginning of the ciphertext.
-	ciphertext := make([]byte, aes.BlockSize+len(plaintext))
-	iv := ciphertext[:aes.BlockSize]
-	if _, err := io.ReadFull(rand.Reader, iv); err != nil {
-		panic(err)
+	aead, ok := e.keys[kid]
+	if !ok {
+		return "", ErrUnknownKey
+	}
+	if len(sealed) < aead.NonceSize()+aead.Overhead() {
+		return "", ErrMalformed
 	}
 
-	stream := cipher.NewCFBEncrypter(block, iv)
-	stream.XORKeyStream(ciphertext[aes.BlockSize:], plaintext)
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, []byte(kid))
+	if err != nil {
+		return "", ErrDecrypt
+	}
+	return string(plaintext), nil
+}
 
-	// It's important to remember that ciphertexts must be authenticated
-	// (i.e. by using crypto/hmac) as well as being encrypted in order to
-	// be secure.
+// NeedsReencrypt tell whether the envelope isn't encrypted by the active key, like the legacy ciphertext
+// or the one of a rotated key
+func (e *Encrypter) NeedsReencrypt(envelope string) bool {
+	kid, _, err := parseEnvelope(envelope)
+	return err != nil || kid != e.active
+}
 
-	return fmt.Sprintf("%x", ciphertext), nil
+// IsEnvelope tell whether text is the envelope of Encrypt
+func IsEnvelope(text string) bool {
+	_, _, err := parseEnvelope(text)
+	return err == nil
+}
 
+func parseEnvelope(envelope string) (string, []byte, error) {
+	parts := strings.Split(envelope, ":")
+	if len(parts) != 3 || parts[0] != envelopeVersion || parts[1] == "" {
+		return "", nil, ErrMalformed
+	}
+
+	sealed, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return "", nil, ErrMalformed
+	}
+	return parts[1], sealed, nil
 }
@@ -1,37 +1,103 @@
 package encryption_test
 
 import (
+	"bytes"
 	"golang-starter/internal/utils/encryption"
+	"strings"
 	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
-func TestEncryptDecryptAesCBF(t *testing.T) {
-	cipherText, _ := encryption.AesCFBEncryption(
-		"saya makan beras",
-		"12345678123456781234567812345678",
-	)
+var (
+	key1 = bytes.Repeat([]byte{1}, 32)
+	key2 = bytes.Repeat([]byte{2}, 32)
+)
 
-	if len(cipherText) == 0 {
-		t.Errorf("Maybe there was an incorrect when encrypt this data")
-	}
+func TestEncryptDecrypt(t *testing.T) {
+	encrypter, err := encryption.NewEncrypterFromKeys("k1", map[string][]byte{"k1": key1})
+	require.NoError(t, err)
 
-	plainText, _ := encryption.AesCFBDecryption(
-		cipherText,
-		"12345678123456781234567812345678",
-	)
+	cipherText, err := encrypter.Encrypt("saya makan beras")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(cipherText, "v1:k1:"))
+	assert.True(t, encryption.IsEnvelope(cipherText))
 
-	if plainText != "saya makan beras" {
-		t.Errorf("Error when decrypting text")
-	}
+	plainText, err := encrypter.Decrypt(cipherText)
+	require.NoError(t, err)
+	assert.Equal(t, "saya makan beras", plainText)
+
+	// the nonce is random, the same plaintext never has the same ciphertext
+	other, _ := encrypter.Encrypt("saya makan beras")
+	assert.NotEqual(t, cipherText, other)
 }
 
-func TestAddKeyLen(t *testing.T) {
-	newText := encryption.AddKeyLen("greg")
+func TestDecryptRotatedKey(t *testing.T) {
+	old, err := encryption.NewEncrypterFromKeys("k1", map[string][]byte{"k1": key1})
+	require.NoError(t, err)
+	cipherText, err := old.Encrypt("secret")
+	require.NoError(t, err)
 
-	if len(newText) != 32 {
-		t.Errorf("Len is not 32")
-	}
-	if newText != "sayamakanmiesayamakanmiesayamakanmie" {
-		t.Errorf("String is not same as expected")
+	rotated, err := encryption.NewEncrypterFromKeys("k2", map[string][]byte{"k1": key1, "k2": key2})
+	require.NoError(t, err)
+
+	plainText, err := rotated.Decrypt(cipherText)
+	require.NoError(t, err)
+	assert.Equal(t, "secret", plainText)
+	assert.True(t, rotated.NeedsReencrypt(cipherText))
+
+	reencrypted, err := rotated.Encrypt(plainText)
+	require.NoError(t, err)
+	assert.False(t, rotated.NeedsReencrypt(reencrypted))
+
+	_, err = old.Decrypt(reencrypted)
+	assert.Equal(t, encryption.ErrUnknownKey, err)
+}
+
+func TestDecryptInvalid(t *testing.T) {
+	encrypter, err := encryption.NewEncrypterFromKeys("k1", map[string][]byte{"k1": key1, "k2": key2})
+	require.NoError(t, err)
+	cipherText, err := encrypter.Encrypt("secret")
+	require.NoError(t, err)
+
+	for _, text := range []string{"", "abc", "v1:k1:", "v1:k1:!!!", "v2:k1:AAAA", "v1::AAAA"} {
+		_, err := encrypter.Decrypt(text)
+		assert.Equal(t, encryption.ErrMalformed, err, text)
 	}
+
+	// flipping a byte or moving the envelope to another key is detected
+	tampered := []byte(cipherText)
+	tampered[len(tampered)-2] ^= 1
+	_, err = encrypter.Decrypt(string(tampered))
+	assert.Error(t, err)
+
+	_, err = encrypter.Decrypt(strings.Replace(cipherText, "v1:k1:", "v1:k2:", 1))
+	assert.Equal(t, encryption.ErrDecrypt, err)
+}
+
+func TestNewEncrypterFromKeys(t *testing.T) {
+	_, err := encryption.NewEncrypterFromKeys("k1", map[string][]byte{"k1": []byte("short")})
+	assert.Error(t, err)
+
+	_, err = encryption.NewEncrypterFromKeys("k3", map[string][]byte{"k1": key1})
+	assert.Error(t, err)
+
+	_, err = encryption.NewEncrypterFromKeys("k:1", map[string][]byte{"k:1": key1})
+	assert.Error(t, err)
+}
+
+func TestDecryptLegacyCFB(t *testing.T) {
+	// encrypted by the AES-CFB encryption before the envelope
+	legacy := "28137b8b1cec5699165a49e132ee1bfbf412820bcbc80e7840db3b956f62d7de"
+
+	plainText, err := encryption.DecryptLegacyCFB(legacy, "tgcEOnliPuyvSAlOyC84SZu0yeZrfXW8")
+	require.NoError(t, err)
+	assert.Equal(t, "saya makan beras", plainText)
+	assert.False(t, encryption.IsEnvelope(legacy))
+
+	_, err = encryption.DecryptLegacyCFB("abc", "tgcEOnliPuyvSAlOyC84SZu0yeZrfXW8")
+	assert.Equal(t, encryption.ErrMalformed, err)
+	_, err = encryption.DecryptLegacyCFB("00", "tgcEOnliPuyvSAlOyC84SZu0yeZrfXW8")
+	assert.Equal(t, encryption.ErrMalformed, err)
 }
@@ -0,0 +1,117 @@
+package encryption
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"golang-starter/config"
+	"golang-starter/internal/utils/keyring"
+	"sort"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+)
+
+// BlindIndexer compute the blind index <key id>:<base64url HMAC-SHA256> of a value, so an encrypted column
+// can still be looked up by equality. the active key indexes, every key is looked up while the keys are rotated
+type BlindIndexer struct {
+	active string
+	keys   map[string][]byte
+}
+
+// NewBlindIndexer load the keys of APPLICATION.ENCRYPTION.BLIND_INDEX, it stops the application when a key is invalid
+func NewBlindIndexer() *BlindIndexer {
+	indexer, err := LoadBlindIndexer()
+	if err != nil {
+		log.Fatal().Err(err).Msg("cannot load the blind index keys")
+	}
+	return indexer
+}
+
+// LoadBlindIndexer load the keys of APPLICATION.ENCRYPTION.BLIND_INDEX, the key values are the base64 of 32 bytes
+// either inline, file:<path> or env:<name>. without any key, the key of DefaultKeyId is derived from APPLICATION.KEY.DEFAULT
+func LoadBlindIndexer() (*BlindIndexer, error) {
+	cfg := config.Get().Application
+
+	keys := map[string][]byte{}
+	for kid, source := range cfg.Encryption.BlindIndex.Keys {
+		value, err := keyring.ReadKeySource(source)
+		if err != nil {
+			return nil, fmt.Errorf("blind index key %s: %v", kid, err)
+		}
+		key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("blind index key %s is not base64: %v", kid, err)
+		}
+		keys[kid] = key
+	}
+
+	active := cfg.Encryption.BlindIndex.Active
+	if len(keys) == 0 {
+		// the encryption key isn't reused as is, the blind index must not leak anything about it
+		mac := hmac.New(sha256.New, []byte(cfg.Key.Default))
+		mac.Write([]byte("blind index"))
+		active = DefaultKeyId
+		keys[DefaultKeyId] = mac.Sum(nil)
+	}
+
+	return NewBlindIndexerFromKeys(active, keys)
+}
+
+// NewBlindIndexerFromKeys create the blind indexer of keys, each key must be at least 32 bytes and active must be one of them
+func NewBlindIndexerFromKeys(active string, keys map[string][]byte) (*BlindIndexer, error) {
+	indexer := &BlindIndexer{
+		active: active,
+		keys:   map[string][]byte{},
+	}
+
+	for kid, key := range keys {
+		if kid == "" || strings.Contains(kid, ":") {
+			return nil, fmt.Errorf("blind index key id %q must not be empty or contain ':'", kid)
+		}
+		if len(key) < keySize {
+			return nil, fmt.Errorf("blind index key %s must be at least %d bytes, it's %d", kid, keySize, len(key))
+		}
+		indexer.keys[kid] = key
+	}
+
+	if _, ok := indexer.keys[active]; !ok {
+		return nil, fmt.Errorf("the active blind index key %q is not configured", active)
+	}
+	return indexer, nil
+}
+
+// Index compute the blind index of value with the active key
+func (b *BlindIndexer) Index(value string) string {
+	return b.index(b.active, value)
+}
+
+// Indexes compute the blind index of value with every key, the active one first. look the value up
+// by all of them, the rows that aren't reindexed yet still have the index of a rotated key
+func (b *BlindIndexer) Indexes(value string) []string {
+	kids := make([]string, 0, len(b.keys))
+	for kid := range b.keys {
+		if kid != b.active {
+			kids = append(kids, kid)
+		}
+	}
+	sort.Strings(kids)
+
+	indexes := []string{b.Index(value)}
+	for _, kid := range kids {
+		indexes = append(indexes, b.index(kid, value))
+	}
+	return indexes
+}
+
+// NeedsReindex tell whether index isn't computed by the active key
+func (b *BlindIndexer) NeedsReindex(index string) bool {
+	return !strings.HasPrefix(index, b.active+":")
+}
+
+func (b *BlindIndexer) index(kid, value string) string {
+	mac := hmac.New(sha256.New, b.keys[kid])
+	mac.Write([]byte(value))
+	return kid + ":" + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,48 @@
+package encryption_test
+
+import (
+	"golang-starter/internal/utils/encryption"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlindIndex(t *testing.T) {
+	indexer, err := encryption.NewBlindIndexerFromKeys("k1", map[string][]byte{"k1": key1})
+	require.NoError(t, err)
+
+	index := indexer.Index("test@gmail.com")
+	assert.True(t, strings.HasPrefix(index, "k1:"))
+	assert.Equal(t, index, indexer.Index("test@gmail.com"))
+	assert.NotEqual(t, index, indexer.Index("other@gmail.com"))
+	assert.False(t, indexer.NeedsReindex(index))
+}
+
+func TestBlindIndexRotatedKey(t *testing.T) {
+	old, err := encryption.NewBlindIndexerFromKeys("k1", map[string][]byte{"k1": key1})
+	require.NoError(t, err)
+	rotated, err := encryption.NewBlindIndexerFromKeys("k2", map[string][]byte{"k1": key1, "k2": key2})
+	require.NoError(t, err)
+
+	index := old.Index("test@gmail.com")
+	assert.True(t, rotated.NeedsReindex(index))
+
+	// the index of the rotated key is still looked up, after the active one
+	indexes := rotated.Indexes("test@gmail.com")
+	require.Len(t, indexes, 2)
+	assert.Equal(t, rotated.Index("test@gmail.com"), indexes[0])
+	assert.Equal(t, index, indexes[1])
+}
+
+func TestNewBlindIndexerFromKeys(t *testing.T) {
+	_, err := encryption.NewBlindIndexerFromKeys("k1", map[string][]byte{"k1": []byte("short")})
+	assert.Error(t, err)
+
+	_, err = encryption.NewBlindIndexerFromKeys("k3", map[string][]byte{"k1": key1})
+	assert.Error(t, err)
+
+	_, err = encryption.NewBlindIndexerFromKeys("k:1", map[string][]byte{"k:1": key1})
+	assert.Error(t, err)
+}
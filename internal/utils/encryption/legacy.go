@@ -0,0 +1,36 @@
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/hex"
+)
+
+// DecryptLegacyCFB decrypt the hex AES-CFB ciphertext that was stored before the envelope,
+// it's only kept to migrate the old ciphertext. the legacy encryption used the raw key,
+// a key shorter than 32 bytes was padded with its own prefix
+func DecryptLegacyCFB(text string, encryptionKey string) (string, error) {
+	key := []byte(encryptionKey)
+	if len(key) < keySize && len(key)*2 >= keySize {
+		key = append(key, key[:keySize-len(key)]...)
+	}
+
+	ciphertext, err := hex.DecodeString(text)
+	if err != nil {
+		return "", ErrMalformed
+	}
+	if len(ciphertext) < aes.BlockSize {
+		return "", ErrMalformed
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return "", err
+	}
+
+	iv := ciphertext[:aes.BlockSize]
+	plaintext := make([]byte, len(ciphertext)-aes.BlockSize)
+	cipher.NewCFBDecrypter(block, iv).XORKeyStream(plaintext, ciphertext[aes.BlockSize:])
+
+	return string(plaintext), nil
+}
@@ -0,0 +1,28 @@
+package health
+
+import (
+	"context"
+	"golang-starter/config"
+	"golang-starter/infrastructures/cached"
+	"golang-starter/infrastructures/db"
+	"golang-starter/infrastructures/localdb"
+	"time"
+)
+
+// NewDependencyChecker check the dependencies of the application, redis is only checked when it's enabled.
+// each check times out after HEALTH.TIMEOUT
+func NewDependencyChecker(mysql *db.MysqlImpl, redis *cached.RedisImpl, scribble *localdb.ScribleImpl) *Checker {
+	timeout := config.Get().Health.Timeout
+	if timeout <= 0 {
+		timeout = 2 * time.Second
+	}
+
+	checks := []Check{
+		{Name: "mysql", Check: mysql.Ping},
+		{Name: "scribble", Check: func(context.Context) error { return scribble.Writable() }},
+	}
+	if redis.Enabled() {
+		checks = append(checks, Check{Name: "redis", Check: redis.Ping})
+	}
+	return NewChecker(timeout, checks...)
+}
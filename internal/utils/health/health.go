@@ -0,0 +1,118 @@
+package health
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	StatusOk   = "ok"
+	StatusFail = "fail"
+)
+
+// Check is a dependency the application needs to serve the requests, like the database
+type Check struct {
+	Name  string
+	Check func(ctx context.Context) error
+}
+
+// Report is the result of the checks, Status is fail when a check fails or the application is draining
+type Report struct {
+	Status string                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks,omitempty"`
+}
+
+type CheckResult struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+	// DurationMs is how long the check took in millisecond
+	DurationMs int64 `json:"duration_ms"`
+}
+
+// Checker run the checks of the readiness, each check has its own timeout
+type Checker struct {
+	checks   []Check
+	timeout  time.Duration
+	draining int32
+}
+
+func NewChecker(timeout time.Duration, checks ...Check) *Checker {
+	sort.Slice(checks, func(i, j int) bool { return checks[i].Name < checks[j].Name })
+	return &Checker{
+		checks:  checks,
+		timeout: timeout,
+	}
+}
+
+// Drain make the readiness fail from now on, so the load balancers stop sending requests before the shutdown
+func (c *Checker) Drain() {
+	atomic.StoreInt32(&c.draining, 1)
+}
+
+func (c *Checker) Draining() bool {
+	return atomic.LoadInt32(&c.draining) == 1
+}
+
+// Ready run every check at once, a draining application is not ready whatever the checks say
+func (c *Checker) Ready(ctx context.Context) Report {
+	report := Report{
+		Status: StatusOk,
+		Checks: make(map[string]CheckResult, len(c.checks)+1),
+	}
+
+	if c.Draining() {
+		report.Status = StatusFail
+		report.Checks["shutdown"] = CheckResult{Status: StatusFail, Error: "the application is shutting down"}
+	}
+
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+	wg.Add(len(c.checks))
+	for _, check := range c.checks {
+		go func(check Check) {
+			defer wg.Done()
+			result := c.run(ctx, check)
+
+			mu.Lock()
+			defer mu.Unlock()
+			report.Checks[check.Name] = result
+			if result.Status != StatusOk {
+				report.Status = StatusFail
+			}
+		}(check)
+	}
+	wg.Wait()
+
+	return report
+}
+
+func (c *Checker) run(ctx context.Context, check Check) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		done <- check.Check(ctx)
+	}()
+
+	// a check that ignores its context still can't hold the probe longer than the timeout
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	result := CheckResult{Status: StatusOk, DurationMs: time.Since(start).Milliseconds()}
+	if err != nil {
+		result.Status = StatusFail
+		result.Error = err.Error()
+	}
+	return result
+}
@@ -0,0 +1,55 @@
+package health_test
+
+import (
+	"context"
+	"errors"
+	"golang-starter/internal/utils/health"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReady(t *testing.T) {
+	checker := health.NewChecker(time.Second,
+		health.Check{Name: "mysql", Check: func(context.Context) error { return nil }},
+		health.Check{Name: "redis", Check: func(context.Context) error { return nil }},
+	)
+
+	report := checker.Ready(context.Background())
+	assert.Equal(t, health.StatusOk, report.Status)
+	assert.Len(t, report.Checks, 2)
+	assert.Equal(t, health.StatusOk, report.Checks["mysql"].Status)
+}
+
+func TestReadyFailingCheck(t *testing.T) {
+	checker := health.NewChecker(50*time.Millisecond,
+		health.Check{Name: "mysql", Check: func(context.Context) error { return errors.New("connection refused") }},
+		// a check that ignores its context is still cut at the timeout
+		health.Check{Name: "redis", Check: func(context.Context) error { time.Sleep(time.Second); return nil }},
+		health.Check{Name: "scribble", Check: func(context.Context) error { return nil }},
+	)
+
+	start := time.Now()
+	report := checker.Ready(context.Background())
+	assert.Less(t, int64(time.Since(start)), int64(500*time.Millisecond))
+
+	assert.Equal(t, health.StatusFail, report.Status)
+	assert.Equal(t, "connection refused", report.Checks["mysql"].Error)
+	assert.Equal(t, context.DeadlineExceeded.Error(), report.Checks["redis"].Error)
+	assert.Equal(t, health.StatusOk, report.Checks["scribble"].Status)
+}
+
+func TestReadyDraining(t *testing.T) {
+	checker := health.NewChecker(time.Second,
+		health.Check{Name: "mysql", Check: func(context.Context) error { return nil }},
+	)
+	assert.False(t, checker.Draining())
+
+	checker.Drain()
+	report := checker.Ready(context.Background())
+	assert.True(t, checker.Draining())
+	assert.Equal(t, health.StatusFail, report.Status)
+	assert.Equal(t, health.StatusFail, report.Checks["shutdown"].Status)
+	assert.Equal(t, health.StatusOk, report.Checks["mysql"].Status)
+}
@@ -0,0 +1,218 @@
+package keyring
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	rsareader "golang-starter/internal/utils/rsa"
+	"math/big"
+	"strings"
+)
+
+// the jwt algorithms that a key can sign
+const (
+	AlgHS256 = "HS256"
+	AlgRS256 = "RS256"
+	AlgES256 = "ES256"
+	AlgEdDSA = "EdDSA"
+)
+
+var Algorithms = []string{AlgHS256, AlgRS256, AlgES256, AlgEdDSA}
+
+const (
+	rsaKeyBits     = 2048
+	hmacSecretSize = 32
+)
+
+// ValidAlgorithm tell whether alg is one of Algorithms
+func ValidAlgorithm(alg string) bool {
+	for _, valid := range Algorithms {
+		if alg == valid {
+			return true
+		}
+	}
+	return false
+}
+
+// parsePrivateKey parse the key of alg, it's a pem for the asymmetric algorithms and the secret itself for HS256
+func parsePrivateKey(alg, key string) (interface{}, error) {
+	switch alg {
+	case AlgHS256:
+		secret := strings.TrimSpace(key)
+		if len(secret) < hmacSecretSize {
+			return nil, fmt.Errorf("the HS256 secret must have at least %d characters", hmacSecretSize)
+		}
+		return []byte(secret), nil
+	case AlgRS256:
+		return rsareader.ReadPrivateKeyFromEnv(key)
+	case AlgES256:
+		data, _ := pem.Decode([]byte(key))
+		if data == nil {
+			return nil, errors.New("cannot decode the private key pem")
+		}
+		var private interface{}
+		var err error
+		if data.Type == "EC PRIVATE KEY" {
+			private, err = x509.ParseECPrivateKey(data.Bytes)
+		} else {
+			private, err = x509.ParsePKCS8PrivateKey(data.Bytes)
+		}
+		if err != nil {
+			return nil, err
+		}
+		ecPrivate, ok := private.(*ecdsa.PrivateKey)
+		if !ok || ecPrivate.Curve != elliptic.P256() {
+			return nil, errors.New("the private key is not ecdsa P-256")
+		}
+		return ecPrivate, nil
+	case AlgEdDSA:
+		data, _ := pem.Decode([]byte(key))
+		if data == nil {
+			return nil, errors.New("cannot decode the private key pem")
+		}
+		private, err := x509.ParsePKCS8PrivateKey(data.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		edPrivate, ok := private.(ed25519.PrivateKey)
+		if !ok {
+			return nil, errors.New("the private key is not ed25519")
+		}
+		return edPrivate, nil
+	default:
+		return nil, fmt.Errorf("unsupported algorithm %q", alg)
+	}
+}
+
+// generatePrivateKey return a new key of alg together with its encoded form
+func generatePrivateKey(alg string) (interface{}, string, error) {
+	switch alg {
+	case AlgHS256:
+		secret := make([]byte, hmacSecretSize)
+		if _, err := rand.Read(secret); err != nil {
+			return nil, "", err
+		}
+		encoded := base64.RawURLEncoding.EncodeToString(secret)
+		return []byte(encoded), encoded, nil
+	case AlgRS256:
+		private, err := rsa.GenerateKey(rand.Reader, rsaKeyBits)
+		if err != nil {
+			return nil, "", err
+		}
+		return private, string(pem.EncodeToMemory(&pem.Block{
+			Type:  "RSA PRIVATE KEY",
+			Bytes: x509.MarshalPKCS1PrivateKey(private),
+		})), nil
+	case AlgES256:
+		private, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			return nil, "", err
+		}
+		encoded, err := encodePKCS8(private)
+		return private, encoded, err
+	case AlgEdDSA:
+		_, private, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, "", err
+		}
+		encoded, err := encodePKCS8(private)
+		return private, encoded, err
+	default:
+		return nil, "", fmt.Errorf("unsupported algorithm %q", alg)
+	}
+}
+
+func encodePKCS8(private interface{}) (string, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		return "", err
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})), nil
+}
+
+// publicKey return the key that verifies the signature, the secret itself for HS256
+func publicKey(private interface{}) interface{} {
+	switch k := private.(type) {
+	case *rsa.PrivateKey:
+		return &k.PublicKey
+	case *ecdsa.PrivateKey:
+		return &k.PublicKey
+	case ed25519.PrivateKey:
+		return k.Public()
+	default:
+		return private
+	}
+}
+
+// JWK is the public part of a key as described in RFC 7517
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// jwk return the public jwk of the key, the HS256 secret is never published
+func jwk(kid, alg string, public interface{}) (JWK, bool) {
+	b64 := base64.RawURLEncoding.EncodeToString
+
+	switch k := public.(type) {
+	case *rsa.PublicKey:
+		return JWK{Kty: "RSA", Kid: kid, Use: "sig", Alg: alg, N: b64(k.N.Bytes()), E: b64(big.NewInt(int64(k.E)).Bytes())}, true
+	case *ecdsa.PublicKey:
+		size := (k.Curve.Params().BitSize + 7) / 8
+		return JWK{Kty: "EC", Kid: kid, Use: "sig", Alg: alg, Crv: k.Curve.Params().Name, X: b64(padLeft(k.X.Bytes(), size)), Y: b64(padLeft(k.Y.Bytes(), size))}, true
+	case ed25519.PublicKey:
+		return JWK{Kty: "OKP", Kid: kid, Use: "sig", Alg: alg, Crv: "Ed25519", X: b64(k)}, true
+	default:
+		return JWK{}, false
+	}
+}
+
+// Thumbprint return the RFC 7638 jwk thumbprint of the key, it's used as the kid
+func Thumbprint(public interface{}) string {
+	b64 := base64.RawURLEncoding.EncodeToString
+
+	// the members must be in lexicographic order without any whitespace
+	var canonical string
+	switch k := public.(type) {
+	case *rsa.PublicKey:
+		canonical = fmt.Sprintf(`{"e":"%s","kty":"RSA","n":"%s"}`, b64(big.NewInt(int64(k.E)).Bytes()), b64(k.N.Bytes()))
+	case *ecdsa.PublicKey:
+		size := (k.Curve.Params().BitSize + 7) / 8
+		canonical = fmt.Sprintf(`{"crv":"%s","kty":"EC","x":"%s","y":"%s"}`, k.Curve.Params().Name, b64(padLeft(k.X.Bytes(), size)), b64(padLeft(k.Y.Bytes(), size)))
+	case ed25519.PublicKey:
+		canonical = fmt.Sprintf(`{"crv":"Ed25519","kty":"OKP","x":"%s"}`, b64(k))
+	case []byte:
+		canonical = fmt.Sprintf(`{"k":"%s","kty":"oct"}`, b64(k))
+	}
+
+	sum := sha256.Sum256([]byte(canonical))
+	return b64(sum[:])
+}
+
+func padLeft(b []byte, size int) []byte {
+	if len(b) >= size {
+		return b
+	}
+	padded := make([]byte, size)
+	copy(padded[size-len(b):], b)
+	return padded
+}
@@ -0,0 +1,282 @@
+package keyring
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"golang-starter/config"
+	rsareader "golang-starter/internal/utils/rsa"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+const (
+	KeyStatusActive  = "active"
+	KeyStatusRetired = "retired"
+)
+
+var ErrKeyNotFound = errors.New("keyring: key not found")
+
+// Key is a signing key of the keyring. an active key signs the new tokens,
+// a retired key only verifies the tokens that are signed before the rotation.
+// PrivateKey is a key source, see ReadKeySource
+type Key struct {
+	Kid        string `json:"kid"`
+	Alg        string `json:"alg"`
+	Status     string `json:"status"`
+	PrivateKey string `json:"private_key"`
+	CreatedAt  int64  `json:"created_at"`
+	RetiredAt  int64  `json:"retired_at,omitempty"`
+
+	private interface{}
+}
+
+// SignKey return the key that signs the token, *rsa.PrivateKey, *ecdsa.PrivateKey, ed25519.PrivateKey or the HS256 secret
+func (k Key) SignKey() interface{} {
+	return k.private
+}
+
+// VerifyKey return the key that verifies the token, the public key or the HS256 secret
+func (k Key) VerifyKey() interface{} {
+	return publicKey(k.private)
+}
+
+// Keyring is the set of jwt signing keys, it's stored as a json file
+type Keyring struct {
+	Keys []*Key `json:"keys"`
+}
+
+// LoadKeyring read the keyring file of APPLICATION.KEY.KEYRING, it must have an active key
+// of AUTH.JWT_TOKEN.ALGORITHM. when the keyring isn't configured or the file doesn't exist yet,
+// the key of the algorithm in APPLICATION.KEY is used as the only active key
+func LoadKeyring() (*Keyring, error) {
+	cfg := config.Get()
+	alg := SigningAlgorithm()
+
+	if cfg.Application.Key.Keyring != "" {
+		keyring, err := ReadKeyringFile(cfg.Application.Key.Keyring)
+		if err == nil {
+			if _, err := keyring.SigningKey(alg); err != nil {
+				return nil, fmt.Errorf("keyring %s: %v, rotate the key to the new algorithm", cfg.Application.Key.Keyring, err)
+			}
+			return keyring, nil
+		}
+		if !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+
+	var source string
+	switch alg {
+	case AlgHS256:
+		source = cfg.Application.Key.Hmac
+	case AlgRS256:
+		source = cfg.Application.Key.Rsa.Private
+	case AlgES256:
+		source = cfg.Application.Key.Ecdsa.Private
+	case AlgEdDSA:
+		source = cfg.Application.Key.Ed25519.Private
+	}
+
+	key, err := ReadKeySource(source)
+	if err != nil {
+		return nil, fmt.Errorf("keyring: %s key: %v", alg, err)
+	}
+	keyring, err := NewKeyringFromPrivateKey(alg, key)
+	if err != nil {
+		return nil, fmt.Errorf("keyring: %s key: %v", alg, err)
+	}
+
+	// the public key is derived from the private key, a configured one must be its pair
+	if alg == AlgRS256 && cfg.Application.Key.Rsa.Public != "" {
+		publicPem, err := ReadKeySource(cfg.Application.Key.Rsa.Public)
+		if err != nil {
+			return nil, fmt.Errorf("keyring: rsa public key: %v", err)
+		}
+		public, err := rsareader.ReadPublicKeyFromEnv(publicPem)
+		if err != nil {
+			return nil, fmt.Errorf("keyring: rsa public key: %v", err)
+		}
+		if Thumbprint(public) != keyring.Keys[0].Kid {
+			return nil, errors.New("keyring: the rsa public key is not the pair of the private key")
+		}
+	}
+
+	return keyring, nil
+}
+
+// SigningAlgorithm return AUTH.JWT_TOKEN.ALGORITHM, RS256 when it's empty
+func SigningAlgorithm() string {
+	alg := config.Get().Auth.JwtToken.Algorithm
+	if alg == "" {
+		return AlgRS256
+	}
+	return alg
+}
+
+func ReadKeyringFile(path string) (*Keyring, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	keyring := &Keyring{}
+	if err := json.Unmarshal(data, keyring); err != nil {
+		return nil, fmt.Errorf("keyring: invalid keyring %s: %v", path, err)
+	}
+
+	for _, key := range keyring.Keys {
+		// the keyring before the algorithm was configurable only had rsa keys
+		if key.Alg == "" {
+			key.Alg = AlgRS256
+		}
+		privateKey, err := ReadKeySource(key.PrivateKey)
+		if err != nil {
+			return nil, fmt.Errorf("keyring: private key %s: %v", key.Kid, err)
+		}
+		key.private, err = parsePrivateKey(key.Alg, privateKey)
+		if err != nil {
+			return nil, fmt.Errorf("keyring: invalid private key %s: %v", key.Kid, err)
+		}
+		if key.Kid == "" {
+			key.Kid = Thumbprint(key.VerifyKey())
+		}
+	}
+
+	return keyring, nil
+}
+
+// NewKeyringFromPrivateKey create a keyring of a single active key, its kid is the jwk thumbprint
+func NewKeyringFromPrivateKey(alg, privateKey string) (*Keyring, error) {
+	private, err := parsePrivateKey(alg, privateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Keyring{
+		Keys: []*Key{{
+			Kid:        Thumbprint(publicKey(private)),
+			Alg:        alg,
+			Status:     KeyStatusActive,
+			PrivateKey: privateKey,
+			private:    private,
+		}},
+	}, nil
+}
+
+// WriteFile save the keyring, the file only can be read by the owner because it has the private keys
+func (k *Keyring) WriteFile(path string) error {
+	data, err := json.MarshalIndent(k, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+
+	// write to a temporary file first, so the server never reads a half written keyring
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// SigningKey return the newest active key of alg
+func (k *Keyring) SigningKey(alg string) (*Key, error) {
+	var signing *Key
+	for _, key := range k.Keys {
+		if key.Status != KeyStatusActive || key.Alg != alg {
+			continue
+		}
+		if signing == nil || key.CreatedAt > signing.CreatedAt {
+			signing = key
+		}
+	}
+
+	if signing == nil {
+		return nil, fmt.Errorf("keyring has no active %s key", alg)
+	}
+	return signing, nil
+}
+
+// Key return the key of kid. the tokens that are signed before the keyring existed
+// don't have a kid, they are verified by the oldest key
+func (k *Keyring) Key(kid string) (*Key, error) {
+	if kid == "" {
+		var oldest *Key
+		for _, key := range k.Keys {
+			if oldest == nil || key.CreatedAt < oldest.CreatedAt {
+				oldest = key
+			}
+		}
+		if oldest == nil {
+			return nil, ErrKeyNotFound
+		}
+		return oldest, nil
+	}
+
+	for _, key := range k.Keys {
+		if key.Kid == kid {
+			return key, nil
+		}
+	}
+	return nil, ErrKeyNotFound
+}
+
+// Rotate add a new active key of alg and retire the current active keys, the retired keys keep verifying
+// until they are pruned. retain is how long a retired key is kept, it must cover the longest token expiry
+func (k *Keyring) Rotate(alg string, now time.Time, retain time.Duration) (*Key, error) {
+	private, encoded, err := generatePrivateKey(alg)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, key := range k.Keys {
+		if key.Status == KeyStatusActive {
+			key.Status = KeyStatusRetired
+			key.RetiredAt = now.Unix()
+		}
+	}
+
+	var keys []*Key
+	for _, key := range k.Keys {
+		if key.Status == KeyStatusRetired && now.Sub(time.Unix(key.RetiredAt, 0)) > retain {
+			continue
+		}
+		keys = append(keys, key)
+	}
+
+	newKey := &Key{
+		Kid:        Thumbprint(publicKey(private)),
+		Alg:        alg,
+		Status:     KeyStatusActive,
+		PrivateKey: encoded,
+		CreatedAt:  now.Unix(),
+		private:    private,
+	}
+	k.Keys = append(keys, newKey)
+
+	return newKey, nil
+}
+
+// JWKS return the public keys of the keyring, the newest key first
+func (k *Keyring) JWKS() JWKS {
+	keys := make([]*Key, len(k.Keys))
+	copy(keys, k.Keys)
+	sort.SliceStable(keys, func(i, j int) bool {
+		return keys[i].CreatedAt > keys[j].CreatedAt
+	})
+
+	set := JWKS{Keys: []JWK{}}
+	for _, key := range keys {
+		if public, ok := jwk(key.Kid, key.Alg, key.VerifyKey()); ok {
+			set.Keys = append(set.Keys, public)
+		}
+	}
+	return set
+}
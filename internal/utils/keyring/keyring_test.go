@@ -0,0 +1,141 @@
+package keyring
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newRsaPrivatePem(t *testing.T) string {
+	private, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err)
+	return string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(private)}))
+}
+
+func TestKeyring(t *testing.T) {
+	keyring, err := NewKeyringFromPrivateKey(AlgRS256, newRsaPrivatePem(t))
+	require.NoError(t, err)
+	legacy, err := keyring.SigningKey(AlgRS256)
+	require.NoError(t, err)
+	assert.Equal(t, Thumbprint(legacy.VerifyKey()), legacy.Kid)
+
+	t.Run("Rotate", func(t *testing.T) {
+		newKey, err := keyring.Rotate(AlgES256, time.Now(), time.Hour)
+		require.NoError(t, err)
+
+		signing, err := keyring.SigningKey(AlgES256)
+		require.NoError(t, err)
+		assert.Equal(t, newKey.Kid, signing.Kid)
+		assert.Equal(t, KeyStatusRetired, legacy.Status)
+		_, err = keyring.SigningKey(AlgRS256)
+		assert.Error(t, err)
+
+		// the retired key keeps verifying, the token without kid is verified by the oldest key
+		key, err := keyring.Key(legacy.Kid)
+		require.NoError(t, err)
+		assert.Equal(t, legacy.VerifyKey(), key.VerifyKey())
+		key, err = keyring.Key("")
+		require.NoError(t, err)
+		assert.Equal(t, legacy.Kid, key.Kid)
+
+		_, err = keyring.Key("unknown")
+		assert.Equal(t, ErrKeyNotFound, err)
+
+		jwks := keyring.JWKS()
+		require.Len(t, jwks.Keys, 2)
+		assert.Equal(t, newKey.Kid, jwks.Keys[0].Kid)
+		assert.Equal(t, "EC", jwks.Keys[0].Kty)
+		assert.Equal(t, "P-256", jwks.Keys[0].Crv)
+	})
+
+	t.Run("PruneRetiredKey", func(t *testing.T) {
+		_, err := keyring.Rotate(AlgEdDSA, time.Now().Add(2*time.Hour), time.Hour)
+		require.NoError(t, err)
+
+		_, err = keyring.Key(legacy.Kid)
+		assert.Equal(t, ErrKeyNotFound, err)
+		assert.Len(t, keyring.Keys, 2)
+	})
+
+	t.Run("WriteAndRead", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "keys", "keyring.json")
+		require.NoError(t, keyring.WriteFile(path))
+
+		read, err := ReadKeyringFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, keyring.JWKS(), read.JWKS())
+
+		signing, err := read.SigningKey(AlgEdDSA)
+		require.NoError(t, err)
+		expected, _ := keyring.SigningKey(AlgEdDSA)
+		assert.Equal(t, expected.Kid, signing.Kid)
+	})
+}
+
+func TestKeyringHS256(t *testing.T) {
+	_, err := NewKeyringFromPrivateKey(AlgHS256, "too-short")
+	assert.Error(t, err)
+
+	keyring := &Keyring{}
+	key, err := keyring.Rotate(AlgHS256, time.Now(), time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, []byte(key.PrivateKey), key.SignKey())
+
+	// the secret must never be published
+	assert.Empty(t, keyring.JWKS().Keys)
+}
+
+func TestReadKeySource(t *testing.T) {
+	private, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err)
+	pkcs8, err := x509.MarshalPKCS8PrivateKey(private)
+	require.NoError(t, err)
+	pkcs8Pem := string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8}))
+
+	t.Run("File", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "private.pem")
+		require.NoError(t, ioutil.WriteFile(path, []byte(pkcs8Pem), 0600))
+
+		source, err := ReadKeySource("file:" + path)
+		require.NoError(t, err)
+		key, err := parsePrivateKey(AlgRS256, source)
+		require.NoError(t, err)
+		assert.Equal(t, private.N, key.(*rsa.PrivateKey).N)
+	})
+
+	t.Run("Env", func(t *testing.T) {
+		t.Setenv("TEST_RSA_PRIVATE", strings.ReplaceAll(pkcs8Pem, "\n", `\n`))
+
+		source, err := ReadKeySource("env:TEST_RSA_PRIVATE")
+		require.NoError(t, err)
+		key, err := parsePrivateKey(AlgRS256, source)
+		require.NoError(t, err)
+		assert.Equal(t, private.N, key.(*rsa.PrivateKey).N)
+
+		_, err = ReadKeySource("env:TEST_RSA_UNKNOWN")
+		assert.Error(t, err)
+	})
+
+	t.Run("InvalidPem", func(t *testing.T) {
+		for _, alg := range []string{AlgRS256, AlgES256, AlgEdDSA} {
+			_, err := parsePrivateKey(alg, "not a pem")
+			assert.Error(t, err, alg)
+		}
+	})
+
+	t.Run("WrongKeyType", func(t *testing.T) {
+		_, err := parsePrivateKey(AlgEdDSA, pkcs8Pem)
+		assert.Error(t, err)
+		_, err = parsePrivateKey(AlgES256, pkcs8Pem)
+		assert.Error(t, err)
+	})
+}
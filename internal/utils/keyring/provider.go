@@ -0,0 +1,87 @@
+package keyring
+
+import (
+	"crypto/rsa"
+	"golang-starter/config"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+
+	"github.com/rs/zerolog/log"
+)
+
+// minKeyBits is the recommended rsa key size, a smaller key is accepted but warned
+const minKeyBits = 2048
+
+// Provider hold the parsed keyring, the keys are parsed once instead of on every sign and verify
+type Provider struct {
+	mu      sync.RWMutex
+	keyring *Keyring
+}
+
+// NewProvider load the keyring at startup and stop the application when a key is not valid,
+// then the keyring is loaded again whenever the process receive SIGHUP
+func NewProvider() *Provider {
+	keyring, err := LoadKeyring()
+	if err != nil {
+		log.Fatal().Err(err).Msg("cannot load the signing keys")
+	}
+	warnWeakKeys(keyring)
+
+	p := NewProviderFromKeyring(keyring)
+	go p.reloadOnSignal()
+
+	return p
+}
+
+func NewProviderFromKeyring(keyring *Keyring) *Provider {
+	return &Provider{keyring: keyring}
+}
+
+// Keyring return the current keyring, it must not be modified
+func (p *Provider) Keyring() *Keyring {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.keyring
+}
+
+// Reload read the config and the keyring again, the current keyring is kept when the new one is not valid
+func (p *Provider) Reload() error {
+	if err := config.Reload(); err != nil {
+		return err
+	}
+
+	keyring, err := LoadKeyring()
+	if err != nil {
+		return err
+	}
+	warnWeakKeys(keyring)
+
+	p.mu.Lock()
+	p.keyring = keyring
+	p.mu.Unlock()
+	return nil
+}
+
+func (p *Provider) reloadOnSignal() {
+	signalchan := make(chan os.Signal, 1)
+	signal.Notify(signalchan, syscall.SIGHUP)
+
+	for range signalchan {
+		if err := p.Reload(); err != nil {
+			log.Err(err).Msg("cannot reload the signing keys, the current keys are kept")
+			continue
+		}
+		log.Info().Int("keys", len(p.Keyring().Keys)).Msg("signing keys are reloaded")
+	}
+}
+
+func warnWeakKeys(keyring *Keyring) {
+	for _, key := range keyring.Keys {
+		public, ok := key.VerifyKey().(*rsa.PublicKey)
+		if ok && public.N.BitLen() < minKeyBits {
+			log.Warn().Str("kid", key.Kid).Int("bits", public.N.BitLen()).Msg("the rsa key is weaker than 2048 bits, rotate it")
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package keyring
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+const (
+	sourceFilePrefix = "file:"
+	sourceEnvPrefix  = "env:"
+)
+
+// ReadKeySource return the key of a key source, the source is either the key itself,
+// file:<path> to read it from a file or env:<name> to read it from an environment variable
+func ReadKeySource(source string) (string, error) {
+	switch {
+	case strings.HasPrefix(source, sourceFilePrefix):
+		path := strings.TrimPrefix(source, sourceFilePrefix)
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("cannot read the key file %s: %v", path, err)
+		}
+		return string(data), nil
+	case strings.HasPrefix(source, sourceEnvPrefix):
+		name := strings.TrimPrefix(source, sourceEnvPrefix)
+		value, ok := os.LookupEnv(name)
+		if !ok || value == "" {
+			return "", fmt.Errorf("the key env %s is empty", name)
+		}
+		// a multiline env is hard to set, hence the escaped new line is accepted as well
+		return strings.ReplaceAll(value, `\n`, "\n"), nil
+	default:
+		return source, nil
+	}
+}
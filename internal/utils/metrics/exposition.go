@@ -0,0 +1,77 @@
+package metrics
+
+import (
+	"bufio"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ContentType is the prometheus text format
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// WriteText write every metric of r in the prometheus text format, sorted by their name
+func (r *Registry) WriteText(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	for _, m := range r.sorted() {
+		m.write(bw)
+	}
+	return bw.Flush()
+}
+
+// Handler serve the metrics of r to the prometheus scraper
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", ContentType)
+		r.WriteText(w)
+	})
+}
+
+// Handler serve the metrics of the default registry
+func Handler() http.Handler {
+	return Default.Handler()
+}
+
+var (
+	helpEscaper       = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelValueEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func writeHeader(w *bufio.Writer, name, help, metricType string) {
+	w.WriteString("# HELP " + name + " " + helpEscaper.Replace(help) + "\n")
+	w.WriteString("# TYPE " + name + " " + metricType + "\n")
+}
+
+func writeSample(w *bufio.Writer, name string, labels, labelValues []string, value float64) {
+	w.WriteString(name)
+	if len(labels) > 0 {
+		w.WriteByte('{')
+		for i, label := range labels {
+			if i > 0 {
+				w.WriteByte(',')
+			}
+			w.WriteString(label + `="` + labelValueEscaper.Replace(labelValues[i]) + `"`)
+		}
+		w.WriteByte('}')
+	}
+	w.WriteString(" " + formatFloat(value) + "\n")
+}
+
+func formatFloat(value float64) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	case math.IsNaN(value):
+		return "NaN"
+	}
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+// withLabel return a copy of labelValues with value at the end, so the stored values are never shared
+func withLabel(labelValues []string, value string) []string {
+	return append(append(make([]string, 0, len(labelValues)+1), labelValues...), value)
+}
@@ -0,0 +1,114 @@
+package metrics
+
+import (
+	"bufio"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultBuckets fit the latency of a request in seconds, from 5ms to 10s
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// Histogram count the observations in buckets by their upper bound, like the latency of the requests
+type Histogram struct {
+	name    string
+	help    string
+	labels  []string
+	buckets []float64
+
+	mu     sync.Mutex
+	values map[string]*histogramValue
+}
+
+type histogramValue struct {
+	labelValues []string
+	// counts is the observations of each bucket, they're only cumulated when they're written
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+// NewHistogram create a histogram in the default registry, the buckets are DefaultBuckets when it's nil
+func NewHistogram(name, help string, buckets []float64, labels ...string) *Histogram {
+	return Default.NewHistogram(name, help, buckets, labels...)
+}
+
+// NewHistogram create a histogram in r, the buckets are DefaultBuckets when it's nil
+func (r *Registry) NewHistogram(name, help string, buckets []float64, labels ...string) *Histogram {
+	if buckets == nil {
+		buckets = DefaultBuckets
+	}
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+
+	h := &Histogram{name: name, help: help, labels: labels, buckets: buckets, values: map[string]*histogramValue{}}
+	r.register(h)
+	return h
+}
+
+func (h *Histogram) Name() string {
+	return h.name
+}
+
+// Observe add value to the buckets of labelValues
+func (h *Histogram) Observe(value float64, labelValues ...string) {
+	checkLabels(h.name, h.labels, labelValues)
+
+	key := strings.Join(labelValues, "\xff")
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	v, ok := h.values[key]
+	if !ok {
+		v = &histogramValue{labelValues: append([]string(nil), labelValues...), counts: make([]uint64, len(h.buckets))}
+		h.values[key] = v
+	}
+
+	if i := sort.SearchFloat64s(h.buckets, value); i < len(h.buckets) {
+		v.counts[i]++
+	}
+	v.sum += value
+	v.count++
+}
+
+// ObserveSince add the seconds since start, it's meant to be deferred
+func (h *Histogram) ObserveSince(start time.Time, labelValues ...string) {
+	h.Observe(time.Since(start).Seconds(), labelValues...)
+}
+
+// Count return the observations of labelValues
+func (h *Histogram) Count(labelValues ...string) uint64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if v, ok := h.values[strings.Join(labelValues, "\xff")]; ok {
+		return v.count
+	}
+	return 0
+}
+
+func (h *Histogram) write(w *bufio.Writer) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	writeHeader(w, h.name, h.help, "histogram")
+	bucketLabels := withLabel(h.labels, "le")
+	keys := make([]string, 0, len(h.values))
+	for key := range h.values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		v := h.values[key]
+
+		var cumulative uint64
+		for i, upperBound := range h.buckets {
+			cumulative += v.counts[i]
+			writeSample(w, h.name+"_bucket", bucketLabels, withLabel(v.labelValues, formatFloat(upperBound)), float64(cumulative))
+		}
+		writeSample(w, h.name+"_bucket", bucketLabels, withLabel(v.labelValues, "+Inf"), float64(v.count))
+		writeSample(w, h.name+"_sum", h.labels, v.labelValues, v.sum)
+		writeSample(w, h.name+"_count", h.labels, v.labelValues, float64(v.count))
+	}
+}
@@ -0,0 +1,226 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Registry keep the metrics of the application by their name
+type Registry struct {
+	mu      sync.RWMutex
+	metrics map[string]metric
+}
+
+// metric write its samples in the prometheus text format, a collector can write more than one family
+type metric interface {
+	Name() string
+	write(w *bufio.Writer)
+}
+
+func NewRegistry() *Registry {
+	return &Registry{metrics: map[string]metric{}}
+}
+
+// Default is the registry of the metrics that are created by the package functions
+var Default = NewRegistry()
+
+func (r *Registry) register(m metric) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.metrics[m.Name()]; ok {
+		panic(fmt.Sprintf("metrics: %s is already registered", m.Name()))
+	}
+	r.metrics[m.Name()] = m
+}
+
+// sorted return the metrics sorted by their name
+func (r *Registry) sorted() []metric {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	result := make([]metric, 0, len(r.metrics))
+	for _, m := range r.metrics {
+		result = append(result, m)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name() < result[j].Name() })
+	return result
+}
+
+// series keep a value per label values, it's shared by the counters and the gauges
+type series struct {
+	name   string
+	help   string
+	labels []string
+
+	mu     sync.Mutex
+	values map[string]*seriesValue
+}
+
+type seriesValue struct {
+	labelValues []string
+	value       float64
+}
+
+func newSeries(name, help string, labels []string) series {
+	return series{name: name, help: help, labels: labels, values: map[string]*seriesValue{}}
+}
+
+func (s *series) Name() string {
+	return s.name
+}
+
+func (s *series) update(labelValues []string, fn func(value float64) float64) {
+	checkLabels(s.name, s.labels, labelValues)
+
+	key := strings.Join(labelValues, "\xff")
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	v, ok := s.values[key]
+	if !ok {
+		v = &seriesValue{labelValues: append([]string(nil), labelValues...)}
+		s.values[key] = v
+	}
+	v.value = fn(v.value)
+}
+
+// Value return the value of labelValues, it's zero when it's never set
+func (s *series) Value(labelValues ...string) float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if v, ok := s.values[strings.Join(labelValues, "\xff")]; ok {
+		return v.value
+	}
+	return 0
+}
+
+func (s *series) writeAs(w *bufio.Writer, metricType string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	writeHeader(w, s.name, s.help, metricType)
+	keys := make([]string, 0, len(s.values))
+	for key := range s.values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		v := s.values[key]
+		writeSample(w, s.name, s.labels, v.labelValues, v.value)
+	}
+}
+
+// Counter is a value that only goes up, like the handled requests. a value is kept per label values
+type Counter struct {
+	series
+}
+
+// NewCounter create a counter in the default registry, it panics when the name is already used
+func NewCounter(name, help string, labels ...string) *Counter {
+	return Default.NewCounter(name, help, labels...)
+}
+
+// NewCounter create a counter in r, it panics when the name is already used
+func (r *Registry) NewCounter(name, help string, labels ...string) *Counter {
+	c := &Counter{series: newSeries(name, help, labels)}
+	r.register(c)
+	return c
+}
+
+// Inc add one to the value of labelValues, they're in the order of the labels of the counter
+func (c *Counter) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add add delta to the value of labelValues, a negative delta is ignored since a counter never goes down
+func (c *Counter) Add(delta float64, labelValues ...string) {
+	if delta < 0 {
+		return
+	}
+	c.update(labelValues, func(value float64) float64 { return value + delta })
+}
+
+func (c *Counter) write(w *bufio.Writer) {
+	c.writeAs(w, "counter")
+}
+
+// Gauge is a value that goes up and down, like the requests in flight
+type Gauge struct {
+	series
+}
+
+// NewGauge create a gauge in the default registry, it panics when the name is already used
+func NewGauge(name, help string, labels ...string) *Gauge {
+	return Default.NewGauge(name, help, labels...)
+}
+
+// NewGauge create a gauge in r, it panics when the name is already used
+func (r *Registry) NewGauge(name, help string, labels ...string) *Gauge {
+	g := &Gauge{series: newSeries(name, help, labels)}
+	r.register(g)
+	return g
+}
+
+func (g *Gauge) Set(value float64, labelValues ...string) {
+	g.update(labelValues, func(float64) float64 { return value })
+}
+
+func (g *Gauge) Add(delta float64, labelValues ...string) {
+	g.update(labelValues, func(value float64) float64 { return value + delta })
+}
+
+func (g *Gauge) Inc(labelValues ...string) {
+	g.Add(1, labelValues...)
+}
+
+func (g *Gauge) Dec(labelValues ...string) {
+	g.Add(-1, labelValues...)
+}
+
+func (g *Gauge) write(w *bufio.Writer) {
+	g.writeAs(w, "gauge")
+}
+
+// funcMetric read its value when it's collected, like the stats of a connection pool
+type funcMetric struct {
+	name       string
+	help       string
+	metricType string
+	fn         func() float64
+}
+
+func (f *funcMetric) Name() string {
+	return f.name
+}
+
+func (f *funcMetric) write(w *bufio.Writer) {
+	writeHeader(w, f.name, f.help, f.metricType)
+	writeSample(w, f.name, nil, nil, f.fn())
+}
+
+// NewGaugeFunc create a gauge in the default registry that is read from fn when it's collected
+func NewGaugeFunc(name, help string, fn func() float64) {
+	Default.NewGaugeFunc(name, help, fn)
+}
+
+func (r *Registry) NewGaugeFunc(name, help string, fn func() float64) {
+	r.register(&funcMetric{name: name, help: help, metricType: "gauge", fn: fn})
+}
+
+// NewCounterFunc create a counter in the default registry that is read from fn when it's collected,
+// fn must only go up like the total of a stats
+func NewCounterFunc(name, help string, fn func() float64) {
+	Default.NewCounterFunc(name, help, fn)
+}
+
+func (r *Registry) NewCounterFunc(name, help string, fn func() float64) {
+	r.register(&funcMetric{name: name, help: help, metricType: "counter", fn: fn})
+}
+
+func checkLabels(name string, labels, labelValues []string) {
+	if len(labels) != len(labelValues) {
+		panic(fmt.Sprintf("metrics: %s has %d labels but got %d values", name, len(labels), len(labelValues)))
+	}
+}
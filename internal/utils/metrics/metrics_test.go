@@ -0,0 +1,74 @@
+package metrics_test
+
+import (
+	"golang-starter/internal/utils/metrics"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCounter(t *testing.T) {
+	registry := metrics.NewRegistry()
+	counter := registry.NewCounter("requests_total", "the requests", "method")
+
+	counter.Inc("GET")
+	counter.Inc("GET")
+	counter.Add(3, "POST")
+	counter.Add(-1, "POST")
+
+	assert.Equal(t, float64(2), counter.Value("GET"))
+	assert.Equal(t, float64(3), counter.Value("POST"))
+	assert.Equal(t, float64(0), counter.Value("PUT"))
+}
+
+func TestCounterMisuse(t *testing.T) {
+	registry := metrics.NewRegistry()
+	counter := registry.NewCounter("requests_total", "the requests", "method")
+
+	assert.Panics(t, func() { counter.Inc() })
+	assert.Panics(t, func() { registry.NewCounter("requests_total", "the requests") })
+}
+
+func TestGauge(t *testing.T) {
+	registry := metrics.NewRegistry()
+	gauge := registry.NewGauge("in_flight", "the requests in flight", "route")
+
+	gauge.Inc("/users")
+	gauge.Inc("/users")
+	gauge.Dec("/users")
+	gauge.Set(5, "/products")
+
+	assert.Equal(t, float64(1), gauge.Value("/users"))
+	assert.Equal(t, float64(5), gauge.Value("/products"))
+}
+
+func TestWriteText(t *testing.T) {
+	registry := metrics.NewRegistry()
+	counter := registry.NewCounter("requests_total", "the requests", "route")
+	histogram := registry.NewHistogram("latency_seconds", "the latency", []float64{1, 0.1}, "route")
+	registry.NewGaugeFunc("connections", "the open\nconnections", func() float64 { return 3 })
+
+	counter.Inc(`/say/"hi"`)
+	histogram.Observe(0.05, "/users")
+	histogram.Observe(0.5, "/users")
+	histogram.Observe(2, "/users")
+
+	var b strings.Builder
+	require.NoError(t, registry.WriteText(&b))
+	assert.Equal(t, `# HELP connections the open\nconnections
+# TYPE connections gauge
+connections 3
+# HELP latency_seconds the latency
+# TYPE latency_seconds histogram
+latency_seconds_bucket{route="/users",le="0.1"} 1
+latency_seconds_bucket{route="/users",le="1"} 2
+latency_seconds_bucket{route="/users",le="+Inf"} 3
+latency_seconds_sum{route="/users"} 2.55
+latency_seconds_count{route="/users"} 3
+# HELP requests_total the requests
+# TYPE requests_total counter
+requests_total{route="/say/\"hi\""} 1
+`, b.String())
+}
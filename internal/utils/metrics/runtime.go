@@ -0,0 +1,52 @@
+package metrics
+
+import (
+	"bufio"
+	"runtime"
+	"time"
+)
+
+// runtimeCollector write the go runtime metrics, the memory stats are read once per collect
+// since reading them stops the world
+type runtimeCollector struct {
+	start time.Time
+}
+
+// RegisterRuntime add the go runtime metrics to r, like the goroutines, the memory and the gc
+func (r *Registry) RegisterRuntime() {
+	r.register(&runtimeCollector{start: time.Now()})
+}
+
+func (c *runtimeCollector) Name() string {
+	return "go_"
+}
+
+func (c *runtimeCollector) write(w *bufio.Writer) {
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+
+	gauge := func(name, help string, value float64) {
+		writeHeader(w, name, help, "gauge")
+		writeSample(w, name, nil, nil, value)
+	}
+	counter := func(name, help string, value float64) {
+		writeHeader(w, name, help, "counter")
+		writeSample(w, name, nil, nil, value)
+	}
+
+	writeHeader(w, "go_info", "the version of go", "gauge")
+	writeSample(w, "go_info", []string{"version"}, []string{runtime.Version()}, 1)
+	gauge("go_goroutines", "the goroutines that currently exist", float64(runtime.NumGoroutine()))
+	gauge("go_memstats_alloc_bytes", "the bytes of the allocated heap objects", float64(stats.Alloc))
+	counter("go_memstats_alloc_bytes_total", "the bytes allocated for the heap objects", float64(stats.TotalAlloc))
+	gauge("go_memstats_heap_inuse_bytes", "the bytes of the in-use heap spans", float64(stats.HeapInuse))
+	gauge("go_memstats_heap_objects", "the allocated heap objects", float64(stats.HeapObjects))
+	gauge("go_memstats_sys_bytes", "the bytes obtained from the system", float64(stats.Sys))
+	counter("go_gc_cycles_total", "the completed gc cycles", float64(stats.NumGC))
+	counter("go_gc_pause_seconds_total", "the stop the world pause of the gc", time.Duration(stats.PauseTotalNs).Seconds())
+	gauge("process_start_time_seconds", "the start time of the process since unix epoch", float64(c.start.Unix()))
+}
+
+func init() {
+	Default.RegisterRuntime()
+}
@@ -0,0 +1,59 @@
+package oidc
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+type IDTokenClaims struct {
+	Issuer        string   `json:"iss"`
+	Subject       string   `json:"sub"`
+	Audience      audience `json:"aud"`
+	ExpiresAt     int64    `json:"exp"`
+	IssuedAt      int64    `json:"iat"`
+	Nonce         string   `json:"nonce"`
+	Email         string   `json:"email"`
+	EmailVerified bool     `json:"email_verified"`
+	Name          string   `json:"name"`
+	Picture       string   `json:"picture"`
+}
+
+// Valid is called by the jwt parser after the signature is verified
+func (c IDTokenClaims) Valid() error {
+	now := time.Now()
+	if c.ExpiresAt == 0 || now.Add(-leeway).Unix() > c.ExpiresAt {
+		return errors.New("id token is expired")
+	}
+	if c.IssuedAt > now.Add(leeway).Unix() {
+		return errors.New("id token is issued in the future")
+	}
+	return nil
+}
+
+// audience can be sent either as a string or an array of string
+type audience []string
+
+func (a *audience) UnmarshalJSON(b []byte) error {
+	var single string
+	if err := json.Unmarshal(b, &single); err == nil {
+		*a = audience{single}
+		return nil
+	}
+
+	var multiple []string
+	if err := json.Unmarshal(b, &multiple); err != nil {
+		return err
+	}
+	*a = multiple
+	return nil
+}
+
+func (a audience) contains(clientID string) bool {
+	for _, aud := range a {
+		if aud == clientID {
+			return true
+		}
+	}
+	return false
+}
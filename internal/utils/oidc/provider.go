@@ -0,0 +1,240 @@
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+)
+
+// leeway is the clock skew that is allowed when validating the id token
+const leeway = time.Minute
+
+type ProviderConfig struct {
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+}
+
+// discovery is the part of the openid configuration that is used by the authorization code flow
+type discovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JwksURI               string `json:"jwks_uri"`
+}
+
+// Provider is an openid connect provider that is discovered from its issuer
+type Provider struct {
+	name      string
+	config    ProviderConfig
+	client    *http.Client
+	discovery discovery
+
+	mu   sync.RWMutex
+	keys map[string]interface{}
+}
+
+type TokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	IDToken     string `json:"id_token"`
+	ExpiresIn   int64  `json:"expires_in"`
+}
+
+// NewProvider fetch the openid configuration of the issuer and make sure it's issued by the same issuer
+func NewProvider(ctx context.Context, name string, config ProviderConfig, client *http.Client) (*Provider, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	wellKnown := strings.TrimSuffix(config.Issuer, "/") + "/.well-known/openid-configuration"
+	var d discovery
+	if err := getJSON(ctx, client, wellKnown, &d); err != nil {
+		return nil, fmt.Errorf("oidc: discover %s: %v", name, err)
+	}
+
+	if d.Issuer != config.Issuer {
+		return nil, fmt.Errorf("oidc: issuer of %s didn't match, expected %q got %q", name, config.Issuer, d.Issuer)
+	}
+
+	if len(config.Scopes) == 0 {
+		config.Scopes = []string{"openid", "email", "profile"}
+	}
+
+	return &Provider{
+		name:      name,
+		config:    config,
+		client:    client,
+		discovery: d,
+	}, nil
+}
+
+func (p *Provider) Name() string {
+	return p.name
+}
+
+// AuthCodeURL return the url that the user is redirected to, codeChallenge is the S256 challenge of PKCE
+func (p *Provider) AuthCodeURL(state, nonce, codeChallenge string) string {
+	v := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {p.config.ClientID},
+		"redirect_uri":          {p.config.RedirectURL},
+		"scope":                 {strings.Join(p.config.Scopes, " ")},
+		"state":                 {state},
+		"nonce":                 {nonce},
+		"code_challenge":        {codeChallenge},
+		"code_challenge_method": {"S256"},
+	}
+
+	if strings.Contains(p.discovery.AuthorizationEndpoint, "?") {
+		return p.discovery.AuthorizationEndpoint + "&" + v.Encode()
+	}
+	return p.discovery.AuthorizationEndpoint + "?" + v.Encode()
+}
+
+// Exchange trade the authorization code to the tokens, codeVerifier is the PKCE verifier of the code challenge
+func (p *Provider) Exchange(ctx context.Context, code, codeVerifier string) (*TokenResponse, error) {
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {p.config.RedirectURL},
+		"code_verifier": {codeVerifier},
+	}
+
+	req, err := http.NewRequest(http.MethodPost, p.discovery.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(p.config.ClientID), url.QueryEscape(p.config.ClientSecret))
+
+	res, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("oidc: exchange code: %v", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		var oauthErr struct {
+			Error            string `json:"error"`
+			ErrorDescription string `json:"error_description"`
+		}
+		json.NewDecoder(res.Body).Decode(&oauthErr)
+		return nil, fmt.Errorf("oidc: exchange code: status %d %s %s", res.StatusCode, oauthErr.Error, oauthErr.ErrorDescription)
+	}
+
+	var token TokenResponse
+	if err := json.NewDecoder(res.Body).Decode(&token); err != nil {
+		return nil, fmt.Errorf("oidc: decode token response: %v", err)
+	}
+	if token.IDToken == "" {
+		return nil, errors.New("oidc: token response has no id_token")
+	}
+
+	return &token, nil
+}
+
+// VerifyIDToken check the signature of the id token against the provider jwks,
+// then validate its issuer, audience, expiry and nonce
+func (p *Provider) VerifyIDToken(ctx context.Context, rawIDToken, nonce string) (*IDTokenClaims, error) {
+	parser := jwt.Parser{
+		ValidMethods: []string{"RS256", "RS384", "RS512", "ES256", "ES384", "ES512"},
+	}
+
+	claims := &IDTokenClaims{}
+	_, err := parser.ParseWithClaims(rawIDToken, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return p.key(ctx, kid)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("oidc: invalid id token: %v", err)
+	}
+
+	if claims.Issuer != p.config.Issuer {
+		return nil, fmt.Errorf("oidc: unexpected issuer %q", claims.Issuer)
+	}
+	if !claims.Audience.contains(p.config.ClientID) {
+		return nil, errors.New("oidc: id token isn't issued for this client")
+	}
+	if claims.Nonce == "" || claims.Nonce != nonce {
+		return nil, errors.New("oidc: nonce didn't match")
+	}
+	if claims.Subject == "" {
+		return nil, errors.New("oidc: id token has no subject")
+	}
+
+	return claims, nil
+}
+
+// key return the public key of kid, the jwks is fetched again once when kid is unknown
+// because the provider may have rotated its keys
+func (p *Provider) key(ctx context.Context, kid string) (interface{}, error) {
+	p.mu.RLock()
+	key, ok := p.lookupKey(kid)
+	p.mu.RUnlock()
+	if ok {
+		return key, nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var set jwks
+	if err := getJSON(ctx, p.client, p.discovery.JwksURI, &set); err != nil {
+		return nil, fmt.Errorf("fetch jwks: %v", err)
+	}
+	keys, err := set.publicKeys()
+	if err != nil {
+		return nil, err
+	}
+	p.keys = keys
+
+	key, ok = p.lookupKey(kid)
+	if !ok {
+		return nil, fmt.Errorf("unknown key id %q", kid)
+	}
+	return key, nil
+}
+
+func (p *Provider) lookupKey(kid string) (interface{}, bool) {
+	if kid == "" && len(p.keys) == 1 {
+		for _, key := range p.keys {
+			return key, true
+		}
+	}
+	key, ok := p.keys[kid]
+	return key, ok
+}
+
+func getJSON(ctx context.Context, client *http.Client, url string, v interface{}) error {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Accept", "application/json")
+
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d from %s", res.StatusCode, url)
+	}
+
+	return json.NewDecoder(res.Body).Decode(v)
+}
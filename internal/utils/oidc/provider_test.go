@@ -0,0 +1,333 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeProvider is a minimal openid connect provider for the authorization code flow with PKCE
+type fakeProvider struct {
+	server       *httptest.Server
+	key          *rsa.PrivateKey
+	kid          string
+	clientID     string
+	clientSecret string
+
+	mu    sync.Mutex
+	codes map[string]fakeAuthorization
+	// idTokenClaims let the test change the id token that is issued
+	idTokenClaims func(claims jwt.MapClaims)
+}
+
+type fakeAuthorization struct {
+	nonce         string
+	codeChallenge string
+	redirectURI   string
+}
+
+func newFakeProvider(t *testing.T) *fakeProvider {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	f := &fakeProvider{
+		key:          key,
+		kid:          "fake-key",
+		clientID:     "client-id",
+		clientSecret: "client-secret",
+		codes:        map[string]fakeAuthorization{},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 f.server.URL,
+			"authorization_endpoint": f.server.URL + "/authorize",
+			"token_endpoint":         f.server.URL + "/token",
+			"jwks_uri":               f.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"kid": f.kid,
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	})
+	mux.HandleFunc("/authorize", func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("client_id") != f.clientID || q.Get("code_challenge_method") != "S256" {
+			http.Error(w, "invalid_request", http.StatusBadRequest)
+			return
+		}
+
+		code, _ := RandomString(16)
+		f.mu.Lock()
+		f.codes[code] = fakeAuthorization{
+			nonce:         q.Get("nonce"),
+			codeChallenge: q.Get("code_challenge"),
+			redirectURI:   q.Get("redirect_uri"),
+		}
+		f.mu.Unlock()
+
+		redirect, _ := url.Parse(q.Get("redirect_uri"))
+		redirect.RawQuery = url.Values{"code": {code}, "state": {q.Get("state")}}.Encode()
+		http.Redirect(w, r, redirect.String(), http.StatusFound)
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		clientID, clientSecret, ok := r.BasicAuth()
+		if !ok || clientID != f.clientID || clientSecret != f.clientSecret {
+			w.WriteHeader(http.StatusUnauthorized)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_client"})
+			return
+		}
+
+		f.mu.Lock()
+		authorization, ok := f.codes[r.FormValue("code")]
+		delete(f.codes, r.FormValue("code"))
+		f.mu.Unlock()
+		if !ok ||
+			authorization.redirectURI != r.FormValue("redirect_uri") ||
+			authorization.codeChallenge != CodeChallenge(r.FormValue("code_verifier")) {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+
+		claims := jwt.MapClaims{
+			"iss":            f.server.URL,
+			"sub":            "fake-subject",
+			"aud":            []string{f.clientID},
+			"exp":            time.Now().Add(time.Hour).Unix(),
+			"iat":            time.Now().Unix(),
+			"nonce":          authorization.nonce,
+			"email":          "fake@example.com",
+			"email_verified": true,
+			"name":           "Fake User",
+		}
+		if f.idTokenClaims != nil {
+			f.idTokenClaims(claims)
+		}
+
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token": "access-token",
+			"token_type":   "Bearer",
+			"expires_in":   3600,
+			"id_token":     f.sign(t, claims),
+		})
+	})
+
+	f.server = httptest.NewServer(mux)
+	t.Cleanup(f.server.Close)
+
+	return f
+}
+
+func (f *fakeProvider) sign(t *testing.T, claims jwt.MapClaims) string {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = f.kid
+	signed, err := token.SignedString(f.key)
+	require.NoError(t, err)
+	return signed
+}
+
+func (f *fakeProvider) config() ProviderConfig {
+	return ProviderConfig{
+		Issuer:       f.server.URL,
+		ClientID:     f.clientID,
+		ClientSecret: f.clientSecret,
+		RedirectURL:  "http://localhost/auth/oidc/fake/callback",
+	}
+}
+
+// authorize follow the authorization url like the browser does and return the code and state of the callback
+func authorize(t *testing.T, authURL string) (string, string) {
+	client := &http.Client{
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	res, err := client.Get(authURL)
+	require.NoError(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusFound, res.StatusCode)
+
+	location, err := url.Parse(res.Header.Get("Location"))
+	require.NoError(t, err)
+	return location.Query().Get("code"), location.Query().Get("state")
+}
+
+func TestProviderAuthorizationCodeFlow(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Success", func(t *testing.T) {
+		fake := newFakeProvider(t)
+		provider, err := NewProvider(ctx, "fake", fake.config(), fake.server.Client())
+		require.NoError(t, err)
+
+		verifier, _ := RandomString(32)
+		code, state := authorize(t, provider.AuthCodeURL("the-state", "the-nonce", CodeChallenge(verifier)))
+		assert.Equal(t, "the-state", state)
+
+		token, err := provider.Exchange(ctx, code, verifier)
+		require.NoError(t, err)
+
+		claims, err := provider.VerifyIDToken(ctx, token.IDToken, "the-nonce")
+		require.NoError(t, err)
+		assert.Equal(t, "fake-subject", claims.Subject)
+		assert.Equal(t, "fake@example.com", claims.Email)
+		assert.True(t, claims.EmailVerified)
+	})
+
+	t.Run("WrongCodeVerifier", func(t *testing.T) {
+		fake := newFakeProvider(t)
+		provider, err := NewProvider(ctx, "fake", fake.config(), fake.server.Client())
+		require.NoError(t, err)
+
+		verifier, _ := RandomString(32)
+		code, _ := authorize(t, provider.AuthCodeURL("the-state", "the-nonce", CodeChallenge(verifier)))
+
+		_, err = provider.Exchange(ctx, code, "another-verifier")
+		assert.Error(t, err)
+	})
+
+	t.Run("WrongNonce", func(t *testing.T) {
+		fake := newFakeProvider(t)
+		provider, err := NewProvider(ctx, "fake", fake.config(), fake.server.Client())
+		require.NoError(t, err)
+
+		verifier, _ := RandomString(32)
+		code, _ := authorize(t, provider.AuthCodeURL("the-state", "the-nonce", CodeChallenge(verifier)))
+		token, err := provider.Exchange(ctx, code, verifier)
+		require.NoError(t, err)
+
+		_, err = provider.VerifyIDToken(ctx, token.IDToken, "another-nonce")
+		assert.Error(t, err)
+	})
+
+	t.Run("InvalidIDToken", func(t *testing.T) {
+		cases := map[string]func(claims jwt.MapClaims){
+			"Expired":       func(claims jwt.MapClaims) { claims["exp"] = time.Now().Add(-time.Hour).Unix() },
+			"WrongAudience": func(claims jwt.MapClaims) { claims["aud"] = "another-client" },
+			"WrongIssuer":   func(claims jwt.MapClaims) { claims["iss"] = "https://another-issuer" },
+		}
+
+		for name, modify := range cases {
+			t.Run(name, func(t *testing.T) {
+				fake := newFakeProvider(t)
+				fake.idTokenClaims = modify
+				provider, err := NewProvider(ctx, "fake", fake.config(), fake.server.Client())
+				require.NoError(t, err)
+
+				verifier, _ := RandomString(32)
+				code, _ := authorize(t, provider.AuthCodeURL("the-state", "the-nonce", CodeChallenge(verifier)))
+				token, err := provider.Exchange(ctx, code, verifier)
+				require.NoError(t, err)
+
+				_, err = provider.VerifyIDToken(ctx, token.IDToken, "the-nonce")
+				assert.Error(t, err)
+			})
+		}
+	})
+
+	t.Run("UnknownSigningKey", func(t *testing.T) {
+		fake := newFakeProvider(t)
+		provider, err := NewProvider(ctx, "fake", fake.config(), fake.server.Client())
+		require.NoError(t, err)
+
+		otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+		require.NoError(t, err)
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+			"iss":   fake.server.URL,
+			"sub":   "fake-subject",
+			"aud":   fake.clientID,
+			"exp":   time.Now().Add(time.Hour).Unix(),
+			"nonce": "the-nonce",
+		})
+		token.Header["kid"] = fake.kid
+		forged, err := token.SignedString(otherKey)
+		require.NoError(t, err)
+
+		_, err = provider.VerifyIDToken(ctx, forged, "the-nonce")
+		assert.Error(t, err)
+	})
+}
+
+func TestRegistry(t *testing.T) {
+	fake := newFakeProvider(t)
+	registry := NewRegistryFromConfig(map[string]ProviderConfig{"fake": fake.config()}, fake.server.Client())
+
+	assert.True(t, registry.Has("fake"))
+	assert.False(t, registry.Has("unknown"))
+
+	provider, err := registry.Provider(context.Background(), "fake")
+	require.NoError(t, err)
+	assert.Equal(t, "fake", provider.Name())
+
+	_, err = registry.Provider(context.Background(), "unknown")
+	assert.Error(t, err)
+}
+
+func TestRegistryDiscoveryDoesNotBlockOtherProviders(t *testing.T) {
+	fake := newFakeProvider(t)
+
+	release := make(chan struct{})
+	var slowDiscoveries int32
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&slowDiscoveries, 1)
+		<-release
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer slow.Close()
+	defer close(release)
+
+	slowConfig := fake.config()
+	slowConfig.Issuer = slow.URL
+	registry := NewRegistryFromConfig(map[string]ProviderConfig{"fake": fake.config(), "slow": slowConfig}, fake.server.Client())
+
+	var wg sync.WaitGroup
+	errs := make([]error, 2)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = registry.Provider(context.Background(), "slow")
+		}(i)
+	}
+	require.Eventually(t, func() bool { return atomic.LoadInt32(&slowDiscoveries) == 1 }, time.Second, time.Millisecond)
+
+	provider, err := registry.Provider(context.Background(), "fake")
+	require.NoError(t, err)
+	assert.Equal(t, "fake", provider.Name())
+	cached, err := registry.Provider(context.Background(), "fake")
+	require.NoError(t, err)
+	assert.Same(t, provider, cached)
+
+	// the second caller of the slow provider wait for the first discovery instead of starting its own
+	assert.Equal(t, int32(1), atomic.LoadInt32(&slowDiscoveries))
+	release <- struct{}{}
+	release <- struct{}{}
+	wg.Wait()
+	assert.Error(t, errs[0])
+	assert.Error(t, errs[1])
+	// the failed discovery isn't cached
+	assert.Equal(t, int32(2), atomic.LoadInt32(&slowDiscoveries))
+}
@@ -0,0 +1,93 @@
+package oidc
+
+import (
+	"context"
+	"fmt"
+	"golang-starter/config"
+	"golang-starter/internal/utils/tracing"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Registry hold the providers from AUTH.OIDC.PROVIDERS, each provider is discovered on its first use
+// so a provider that is down doesn't stop the app from starting
+type Registry struct {
+	client  *http.Client
+	configs map[string]ProviderConfig
+
+	mu        sync.Mutex
+	providers map[string]*registryEntry
+}
+
+// registryEntry discover a provider once, the callers of the same provider wait for the discovery
+// while the other providers aren't blocked by it
+type registryEntry struct {
+	mu       sync.Mutex
+	provider *Provider
+}
+
+func NewRegistry() *Registry {
+	configs := map[string]ProviderConfig{}
+	for name, provider := range config.Get().Auth.Oidc.Providers {
+		configs[name] = ProviderConfig{
+			Issuer:       provider.Issuer,
+			ClientID:     provider.ClientID,
+			ClientSecret: provider.ClientSecret,
+			RedirectURL:  provider.RedirectURL,
+			Scopes:       provider.Scopes,
+		}
+	}
+
+	return NewRegistryFromConfig(configs, &http.Client{Timeout: 10 * time.Second, Transport: tracing.NewTransport(nil)})
+}
+
+func NewRegistryFromConfig(configs map[string]ProviderConfig, client *http.Client) *Registry {
+	return &Registry{
+		client:    client,
+		configs:   configs,
+		providers: map[string]*registryEntry{},
+	}
+}
+
+// Provider return the discovered provider by its name, a failed discovery is tried again by the next call
+func (r *Registry) Provider(ctx context.Context, name string) (*Provider, error) {
+	providerConfig, ok := r.configs[name]
+	if !ok {
+		return nil, fmt.Errorf("oidc: provider %s is not registered", name)
+	}
+
+	entry := r.entry(name)
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+
+	if entry.provider != nil {
+		return entry.provider, nil
+	}
+
+	provider, err := NewProvider(ctx, name, providerConfig, r.client)
+	if err != nil {
+		return nil, err
+	}
+	entry.provider = provider
+
+	return provider, nil
+}
+
+func (r *Registry) entry(name string) *registryEntry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entry, ok := r.providers[name]
+	if !ok {
+		entry = &registryEntry{}
+		r.providers[name] = entry
+	}
+	return entry
+}
+
+// Has report whether the provider is registered
+func (r *Registry) Has(name string) bool {
+	_, ok := r.configs[name]
+	return ok
+}
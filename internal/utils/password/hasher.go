@@ -0,0 +1,217 @@
+package password
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"golang-starter/config"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	AlgorithmArgon2id = "argon2id"
+	AlgorithmBcrypt   = "bcrypt"
+)
+
+// the defaults follow the OWASP recommendation for argon2id
+const (
+	defaultArgon2idMemory      = 64 * 1024
+	defaultArgon2idIterations  = 3
+	defaultArgon2idParallelism = 2
+	defaultArgon2idSaltLength  = 16
+	defaultArgon2idKeyLength   = 32
+)
+
+var ErrUnknownHash = errors.New("password: unknown hash format")
+
+// Hasher hash the new passwords with the configured algorithm and verify the stored hash
+// with the algorithm of its prefix, hence the old hashes keep working after the algorithm is changed
+type Hasher interface {
+	Hash(password string) (string, error)
+	Verify(hash, password string) (bool, error)
+	// NeedsRehash tell whether the hash isn't made by the configured algorithm and parameters
+	NeedsRehash(hash string) bool
+}
+
+type Argon2idParams struct {
+	Memory      uint32
+	Iterations  uint32
+	Parallelism uint8
+	SaltLength  uint32
+	KeyLength   uint32
+}
+
+type Config struct {
+	Algorithm  string
+	BcryptCost int
+	Argon2id   Argon2idParams
+}
+
+type HasherImpl struct {
+	config Config
+}
+
+// NewHasher create the hasher from the AUTH.PASSWORD config
+func NewHasher() Hasher {
+	cfg := config.Get().Auth.Password
+	return NewHasherFromConfig(Config{
+		Algorithm:  cfg.Algorithm,
+		BcryptCost: cfg.Bcrypt.Cost,
+		Argon2id: Argon2idParams{
+			Memory:      cfg.Argon2id.Memory,
+			Iterations:  cfg.Argon2id.Iterations,
+			Parallelism: cfg.Argon2id.Parallelism,
+			SaltLength:  cfg.Argon2id.SaltLength,
+			KeyLength:   cfg.Argon2id.KeyLength,
+		},
+	})
+}
+
+// NewHasherFromConfig fill the empty config with the defaults, argon2id is used when the algorithm is empty
+func NewHasherFromConfig(cfg Config) *HasherImpl {
+	if cfg.Algorithm == "" {
+		cfg.Algorithm = AlgorithmArgon2id
+	}
+	if cfg.BcryptCost == 0 {
+		cfg.BcryptCost = bcrypt.DefaultCost
+	}
+	if cfg.Argon2id.Memory == 0 {
+		cfg.Argon2id.Memory = defaultArgon2idMemory
+	}
+	if cfg.Argon2id.Iterations == 0 {
+		cfg.Argon2id.Iterations = defaultArgon2idIterations
+	}
+	if cfg.Argon2id.Parallelism == 0 {
+		cfg.Argon2id.Parallelism = defaultArgon2idParallelism
+	}
+	if cfg.Argon2id.SaltLength == 0 {
+		cfg.Argon2id.SaltLength = defaultArgon2idSaltLength
+	}
+	if cfg.Argon2id.KeyLength == 0 {
+		cfg.Argon2id.KeyLength = defaultArgon2idKeyLength
+	}
+
+	return &HasherImpl{config: cfg}
+}
+
+func (h HasherImpl) Hash(password string) (string, error) {
+	switch h.config.Algorithm {
+	case AlgorithmArgon2id:
+		return hashArgon2id(password, h.config.Argon2id)
+	case AlgorithmBcrypt:
+		hash, err := bcrypt.GenerateFromPassword([]byte(password), h.config.BcryptCost)
+		return string(hash), err
+	default:
+		return "", fmt.Errorf("password: unknown algorithm %q", h.config.Algorithm)
+	}
+}
+
+func (h HasherImpl) Verify(hash, password string) (bool, error) {
+	switch {
+	case isArgon2id(hash):
+		params, salt, key, err := decodeArgon2id(hash)
+		if err != nil {
+			return false, err
+		}
+		other := argon2.IDKey([]byte(password), salt, params.Iterations, params.Memory, params.Parallelism, uint32(len(key)))
+		return subtle.ConstantTimeCompare(key, other) == 1, nil
+	case isBcrypt(hash):
+		err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+		if err == bcrypt.ErrMismatchedHashAndPassword {
+			return false, nil
+		}
+		return err == nil, err
+	default:
+		return false, ErrUnknownHash
+	}
+}
+
+func (h HasherImpl) NeedsRehash(hash string) bool {
+	switch h.config.Algorithm {
+	case AlgorithmArgon2id:
+		if !isArgon2id(hash) {
+			return true
+		}
+		params, salt, key, err := decodeArgon2id(hash)
+		if err != nil {
+			return true
+		}
+		params.SaltLength = uint32(len(salt))
+		params.KeyLength = uint32(len(key))
+		return params != h.config.Argon2id
+	case AlgorithmBcrypt:
+		// the php $2y$ hash is compatible, but it's rehashed to the go version anyway
+		if !strings.HasPrefix(hash, "$2a$") {
+			return true
+		}
+		cost, err := bcrypt.Cost([]byte(hash))
+		return err != nil || cost != h.config.BcryptCost
+	default:
+		return false
+	}
+}
+
+func isArgon2id(hash string) bool {
+	return strings.HasPrefix(hash, "$argon2id$")
+}
+
+func isBcrypt(hash string) bool {
+	return strings.HasPrefix(hash, "$2a$") || strings.HasPrefix(hash, "$2b$") || strings.HasPrefix(hash, "$2y$")
+}
+
+// hashArgon2id encode the hash in the PHC string format that is used by the reference implementation,
+// $argon2id$v=19$m=65536,t=3,p=2$<salt>$<key>
+func hashArgon2id(password string, params Argon2idParams) (string, error) {
+	salt := make([]byte, params.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	key := argon2.IDKey([]byte(password), salt, params.Iterations, params.Memory, params.Parallelism, params.KeyLength)
+
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version,
+		params.Memory,
+		params.Iterations,
+		params.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+func decodeArgon2id(hash string) (Argon2idParams, []byte, []byte, error) {
+	var params Argon2idParams
+
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 {
+		return params, nil, nil, ErrUnknownHash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil {
+		return params, nil, nil, ErrUnknownHash
+	}
+	if version != argon2.Version {
+		return params, nil, nil, fmt.Errorf("password: unsupported argon2 version %d", version)
+	}
+
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Iterations, &params.Parallelism); err != nil {
+		return params, nil, nil, ErrUnknownHash
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return params, nil, nil, ErrUnknownHash
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return params, nil, nil, ErrUnknownHash
+	}
+
+	return params, salt, key, nil
+}
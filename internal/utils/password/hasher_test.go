@@ -0,0 +1,102 @@
+package password
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// small parameters keep the test fast, they must not be used in production
+var testArgon2id = Argon2idParams{Memory: 1024, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32}
+
+func TestHasher(t *testing.T) {
+	t.Run("Argon2id", func(t *testing.T) {
+		hasher := NewHasherFromConfig(Config{Algorithm: AlgorithmArgon2id, Argon2id: testArgon2id})
+
+		hash, err := hasher.Hash("secret-password")
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(hash, "$argon2id$v=19$m=1024,t=1,p=1$"))
+
+		ok, err := hasher.Verify(hash, "secret-password")
+		require.NoError(t, err)
+		assert.True(t, ok)
+
+		ok, err = hasher.Verify(hash, "wrong-password")
+		require.NoError(t, err)
+		assert.False(t, ok)
+
+		assert.False(t, hasher.NeedsRehash(hash))
+	})
+
+	t.Run("Bcrypt", func(t *testing.T) {
+		hasher := NewHasherFromConfig(Config{Algorithm: AlgorithmBcrypt, BcryptCost: bcrypt.MinCost})
+
+		hash, err := hasher.Hash("secret-password")
+		require.NoError(t, err)
+
+		ok, err := hasher.Verify(hash, "secret-password")
+		require.NoError(t, err)
+		assert.True(t, ok)
+
+		ok, err = hasher.Verify(hash, "wrong-password")
+		require.NoError(t, err)
+		assert.False(t, ok)
+
+		assert.False(t, hasher.NeedsRehash(hash))
+	})
+
+	t.Run("PhpBcrypt", func(t *testing.T) {
+		hasher := NewHasherFromConfig(Config{Algorithm: AlgorithmBcrypt, BcryptCost: bcrypt.MinCost})
+
+		hash, err := bcrypt.GenerateFromPassword([]byte("secret-password"), bcrypt.MinCost)
+		require.NoError(t, err)
+		phpHash := "$2y$" + strings.TrimPrefix(string(hash), "$2a$")
+
+		ok, err := hasher.Verify(phpHash, "secret-password")
+		require.NoError(t, err)
+		assert.True(t, ok)
+		assert.True(t, hasher.NeedsRehash(phpHash))
+	})
+
+	t.Run("UnknownHash", func(t *testing.T) {
+		hasher := NewHasherFromConfig(Config{Algorithm: AlgorithmArgon2id, Argon2id: testArgon2id})
+
+		_, err := hasher.Verify("plain-text", "plain-text")
+		assert.Equal(t, ErrUnknownHash, err)
+
+		_, err = hasher.Verify("$argon2id$v=19$broken", "secret-password")
+		assert.Error(t, err)
+	})
+}
+
+func TestHasherNeedsRehash(t *testing.T) {
+	bcryptHasher := NewHasherFromConfig(Config{Algorithm: AlgorithmBcrypt, BcryptCost: bcrypt.MinCost})
+	argon2idHasher := NewHasherFromConfig(Config{Algorithm: AlgorithmArgon2id, Argon2id: testArgon2id})
+
+	bcryptHash, err := bcryptHasher.Hash("secret-password")
+	require.NoError(t, err)
+	argon2idHash, err := argon2idHasher.Hash("secret-password")
+	require.NoError(t, err)
+
+	t.Run("AlgorithmChanged", func(t *testing.T) {
+		assert.True(t, argon2idHasher.NeedsRehash(bcryptHash))
+		assert.True(t, bcryptHasher.NeedsRehash(argon2idHash))
+	})
+
+	t.Run("ParamsChanged", func(t *testing.T) {
+		stronger := testArgon2id
+		stronger.Iterations = 2
+		assert.True(t, NewHasherFromConfig(Config{Algorithm: AlgorithmArgon2id, Argon2id: stronger}).NeedsRehash(argon2idHash))
+
+		assert.True(t, NewHasherFromConfig(Config{Algorithm: AlgorithmBcrypt, BcryptCost: bcrypt.MinCost + 1}).NeedsRehash(bcryptHash))
+	})
+
+	t.Run("OldHashStillVerified", func(t *testing.T) {
+		ok, err := argon2idHasher.Verify(bcryptHash, "secret-password")
+		require.NoError(t, err)
+		assert.True(t, ok)
+	})
+}
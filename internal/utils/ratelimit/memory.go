@@ -0,0 +1,70 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// the memory store only limits a single instance, every instance has its own buckets
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+type bucket struct {
+	tokens float64
+	at     time.Time
+	// full is when the bucket is full again, it's removed after that
+	full time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets:   map[string]*bucket{},
+		lastSweep: time.Now(),
+	}
+}
+
+func (s *MemoryStore) Take(ctx context.Context, key string, limit Limit) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.sweep(now)
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: limit.capacity(), at: now}
+		s.buckets[key] = b
+	}
+
+	elapsed := float64(now.Sub(b.at)) / float64(time.Millisecond)
+	b.tokens = math.Min(limit.capacity(), b.tokens+elapsed*limit.rate())
+	b.at = now
+
+	allowed := b.tokens >= 1
+	if allowed {
+		b.tokens--
+	}
+
+	res := limit.result(b.tokens, allowed)
+	b.full = now.Add(res.Reset)
+	return res, nil
+}
+
+// sweep remove the full buckets at most once a minute, a full bucket is the same as a missing one
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < time.Minute {
+		return
+	}
+	s.lastSweep = now
+
+	for key, b := range s.buckets {
+		if !b.full.After(now) {
+			delete(s.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package ratelimit_test
+
+import (
+	"context"
+	"golang-starter/internal/utils/ratelimit"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryStoreTake(t *testing.T) {
+	store := ratelimit.NewMemoryStore()
+	limit := ratelimit.Limit{Requests: 2, Period: time.Hour}
+
+	for remaining := 1; remaining >= 0; remaining-- {
+		result, err := store.Take(context.Background(), "client", limit)
+		require.NoError(t, err)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, 2, result.Limit)
+		assert.Equal(t, remaining, result.Remaining)
+	}
+
+	result, err := store.Take(context.Background(), "client", limit)
+	require.NoError(t, err)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, 0, result.Remaining)
+	// a token comes back every half an hour
+	assert.InDelta(t, float64(30*time.Minute), float64(result.RetryAfter), float64(time.Second))
+	assert.InDelta(t, float64(time.Hour), float64(result.Reset), float64(time.Second))
+
+	// the other clients have their own bucket
+	result, err = store.Take(context.Background(), "other", limit)
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+}
+
+func TestMemoryStoreBurst(t *testing.T) {
+	store := ratelimit.NewMemoryStore()
+	limit := ratelimit.Limit{Requests: 1, Period: time.Hour, Burst: 3}
+
+	allowed := 0
+	for i := 0; i < 5; i++ {
+		result, err := store.Take(context.Background(), "client", limit)
+		require.NoError(t, err)
+		assert.Equal(t, 3, result.Limit)
+		if result.Allowed {
+			allowed++
+		}
+	}
+	assert.Equal(t, 3, allowed)
+}
+
+func TestMemoryStoreRefill(t *testing.T) {
+	store := ratelimit.NewMemoryStore()
+	limit := ratelimit.Limit{Requests: 1, Period: 50 * time.Millisecond}
+
+	result, err := store.Take(context.Background(), "client", limit)
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+
+	result, err = store.Take(context.Background(), "client", limit)
+	require.NoError(t, err)
+	assert.False(t, result.Allowed)
+
+	time.Sleep(result.RetryAfter + 5*time.Millisecond)
+	result, err = store.Take(context.Background(), "client", limit)
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+}
@@ -0,0 +1,169 @@
+package ratelimit
+
+import (
+	"context"
+	"golang-starter/config"
+	"golang-starter/infrastructures/cached"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// the clients a bucket is kept for, user and api key fall back to the ip of an anonymous request
+const (
+	KeyIp     = "ip"
+	KeyUser   = "user"
+	KeyApiKey = "api_key"
+)
+
+// Limit is a token bucket, it holds Burst tokens and gets Requests tokens back every Period
+type Limit struct {
+	Requests int
+	Period   time.Duration
+	Burst    int
+}
+
+func (l Limit) capacity() float64 {
+	if l.Burst > 0 {
+		return float64(l.Burst)
+	}
+	return float64(l.Requests)
+}
+
+// rate is the tokens that come back every millisecond
+func (l Limit) rate() float64 {
+	return float64(l.Requests) / float64(l.Period.Milliseconds())
+}
+
+// result of the bucket that has tokens left after a request is allowed or not
+func (l Limit) result(tokens float64, allowed bool) Result {
+	res := Result{
+		Allowed:   allowed,
+		Limit:     int(l.capacity()),
+		Remaining: int(math.Floor(tokens)),
+		Reset:     time.Duration((l.capacity() - tokens) / l.rate() * float64(time.Millisecond)),
+	}
+	if !allowed {
+		res.RetryAfter = time.Duration((1 - tokens) / l.rate() * float64(time.Millisecond))
+	}
+	return res
+}
+
+// Result of taking a token, Reset is how long until the bucket is full again
+type Result struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	Reset      time.Duration
+	RetryAfter time.Duration
+}
+
+// Store keep the buckets, a bucket is created full
+type Store interface {
+	// Take take a token of the bucket of key
+	Take(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+// NewStore choose the store based on RATE_LIMIT.STORE
+func NewStore(redis *cached.RedisImpl) Store {
+	switch config.Get().RateLimit.Store {
+	case "redis":
+		if !redis.Enabled() {
+			log.Fatal().Msg("rate limit store is redis but redis is disabled")
+		}
+		return NewRedisStore(redis)
+	default:
+		return NewMemoryStore()
+	}
+}
+
+// Policy is the limit of a client, Key tell whether the client is an ip, a user or an api key
+type Policy struct {
+	// Name is the bucket of the policy, the routes that share a policy share the bucket
+	Name  string
+	Key   string
+	Limit Limit
+}
+
+// Limiter choose the policy of a route and take the tokens from the store
+type Limiter struct {
+	store   Store
+	enabled bool
+	policy  Policy
+	routes  map[string]Policy
+	auth    Policy
+}
+
+// NewLimiter read the policies of RATE_LIMIT, the routes are keyed by their method and chi pattern
+// like POST /users/login and each of them has its own bucket
+func NewLimiter(store Store) *Limiter {
+	cfg := config.Get().RateLimit
+	limiter := &Limiter{
+		store:   store,
+		enabled: cfg.Enabled,
+		policy:  newPolicy("default", cfg.RateLimitPolicy),
+		routes:  map[string]Policy{},
+		auth:    newPolicy("auth", cfg.Auth),
+	}
+	limiter.auth.Key = KeyIp
+
+	for _, route := range cfg.Routes {
+		name := strings.ToUpper(route.Method) + " " + route.Pattern
+		limiter.routes[name] = newPolicy(name, route.RateLimitPolicy)
+	}
+	return limiter
+}
+
+func newPolicy(name string, cfg config.RateLimitPolicy) Policy {
+	policy := Policy{
+		Name: name,
+		Key:  cfg.Key,
+		Limit: Limit{
+			Requests: cfg.Requests,
+			Period:   cfg.Period,
+			Burst:    cfg.Burst,
+		},
+	}
+	if policy.Key == "" {
+		policy.Key = KeyIp
+	}
+	if policy.Limit.Period <= 0 {
+		policy.Limit.Period = time.Minute
+	}
+	return policy
+}
+
+// Enabled reports whether the requests are limited
+func (l *Limiter) Enabled() bool {
+	return l.enabled && l.policy.Limit.Requests > 0
+}
+
+// AuthEnabled reports whether the requests are limited before they're authenticated
+func (l *Limiter) AuthEnabled() bool {
+	return l.enabled && l.auth.Limit.Requests > 0
+}
+
+// AuthPolicy return the policy of the requests before they're authenticated, it's keyed by ip
+func (l *Limiter) AuthPolicy() Policy {
+	return l.auth
+}
+
+// HasRoutes reports whether a route has its own policy, the route only needs to be resolved when it does
+func (l *Limiter) HasRoutes() bool {
+	return len(l.routes) > 0
+}
+
+// Policy return the policy of the route, it's the default one when the route has none
+func (l *Limiter) Policy(method, pattern string) Policy {
+	if policy, ok := l.routes[method+" "+pattern]; ok {
+		return policy
+	}
+	return l.policy
+}
+
+// Take take a token of the bucket of the client in policy
+func (l *Limiter) Take(ctx context.Context, policy Policy, client string) (Result, error) {
+	return l.store.Take(ctx, policy.Name+"|"+client, policy.Limit)
+}
@@ -0,0 +1,71 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"golang-starter/infrastructures/cached"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+const rateLimitRedisPrefix = "rate_limit:"
+
+// takeScript refill and take a token atomically, so the instances share the buckets.
+// the tokens are returned as a string since redis truncates the lua numbers to integers
+var takeScript = redis.NewScript(`
+local capacity = tonumber(ARGV[1])
+local rate = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+
+local bucket = redis.call("HMGET", KEYS[1], "tokens", "at")
+local tokens = tonumber(bucket[1])
+local at = tonumber(bucket[2])
+if tokens == nil or at == nil then
+	tokens = capacity
+	at = now
+end
+
+tokens = math.min(capacity, tokens + math.max(0, now - at) * rate)
+local allowed = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+end
+
+redis.call("HMSET", KEYS[1], "tokens", tostring(tokens), "at", now)
+redis.call("PEXPIRE", KEYS[1], math.ceil((capacity - tokens) / rate) + 1000)
+return {allowed, tostring(tokens)}
+`)
+
+type RedisStore struct {
+	redis *cached.RedisImpl
+}
+
+func NewRedisStore(redis *cached.RedisImpl) *RedisStore {
+	return &RedisStore{
+		redis: redis,
+	}
+}
+
+func (s RedisStore) Take(ctx context.Context, key string, limit Limit) (Result, error) {
+	values, err := takeScript.Run(ctx, s.redis.DB(), []string{rateLimitRedisPrefix + key},
+		limit.capacity(), limit.rate(), time.Now().UnixNano()/int64(time.Millisecond),
+	).Slice()
+	if err != nil {
+		return Result{}, err
+	}
+	if len(values) != 2 {
+		return Result{}, fmt.Errorf("ratelimit: unexpected script result %v", values)
+	}
+
+	allowed, _ := values[0].(int64)
+	tokensText, _ := values[1].(string)
+	tokens, err := strconv.ParseFloat(tokensText, 64)
+	if err != nil {
+		return Result{}, fmt.Errorf("ratelimit: unexpected tokens %q: %v", tokensText, err)
+	}
+
+	return limit.result(tokens, allowed == 1), nil
+}
@@ -7,9 +7,17 @@ import (
 	"errors"
 )
 
-// ReadPublicKey will return public key
+// ReadPublicKey will return public key, the pem is either PKIX or PKCS#1
 func ReadPublicKeyFromEnv(rsaPublic string) (*rsa.PublicKey, error) {
 	data, _ := pem.Decode([]byte(rsaPublic))
+	if data == nil {
+		return nil, errors.New("cannot decode the public key pem")
+	}
+
+	if data.Type == "RSA PUBLIC KEY" {
+		return x509.ParsePKCS1PublicKey(data.Bytes)
+	}
+
 	publicKeyImported, err := x509.ParsePKIXPublicKey(data.Bytes)
 	if err != nil {
 		return nil, err
@@ -23,11 +31,25 @@ func ReadPublicKeyFromEnv(rsaPublic string) (*rsa.PublicKey, error) {
 	return publicKey, nil
 }
 
+// ReadPrivateKeyFromEnv will return private key, the pem is either PKCS#1 or PKCS#8
 func ReadPrivateKeyFromEnv(rsaPrivate string) (*rsa.PrivateKey, error) {
 	data, _ := pem.Decode([]byte(rsaPrivate))
-	privateKeyImported, err := x509.ParsePKCS1PrivateKey(data.Bytes)
+	if data == nil {
+		return nil, errors.New("cannot decode the private key pem")
+	}
+
+	if data.Type == "RSA PRIVATE KEY" {
+		return x509.ParsePKCS1PrivateKey(data.Bytes)
+	}
+
+	privateKeyImported, err := x509.ParsePKCS8PrivateKey(data.Bytes)
 	if err != nil {
 		return nil, err
 	}
-	return privateKeyImported, nil
+	privateKey, ok := privateKeyImported.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("the private key is not rsa")
+	}
+
+	return privateKey, nil
 }
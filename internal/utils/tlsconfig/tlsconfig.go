@@ -0,0 +1,245 @@
+package tlsconfig
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Options of the tls of the server, ClientCaFile is the bundle of the CAs of the client certificates,
+// a client certificate is only asked for and verified when it's set
+type Options struct {
+	CertFile     string
+	KeyFile      string
+	ClientCaFile string
+	MinVersion   string
+	CipherSuites []string
+	Http2        bool
+}
+
+var versions = map[string]uint16{
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// ParseMinVersion read a version like 1.2, it's 1.2 when it's empty. the versions before 1.2 aren't supported
+func ParseMinVersion(version string) (uint16, error) {
+	if version == "" {
+		return tls.VersionTLS12, nil
+	}
+	v, ok := versions[version]
+	if !ok {
+		return 0, fmt.Errorf("tls version %q isn't supported, it's 1.2 or 1.3", version)
+	}
+	return v, nil
+}
+
+// ParseCipherSuites read the names of the cipher suites like TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+// the insecure ones are refused. they only apply to tls 1.2, the suites of tls 1.3 can't be chosen
+func ParseCipherSuites(names []string) ([]uint16, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	suites := make(map[string]uint16)
+	for _, suite := range tls.CipherSuites() {
+		suites[suite.Name] = suite.ID
+	}
+
+	ids := make([]uint16, 0, len(names))
+	for _, name := range names {
+		id, ok := suites[name]
+		if !ok {
+			return nil, fmt.Errorf("cipher suite %q is unknown or insecure", name)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// Reloader keep the certificate and the client CAs of the server, they're read again when their files change
+// so a renewed certificate is served without a restart. the current ones are kept when the new files aren't valid
+type Reloader struct {
+	opts     Options
+	interval time.Duration
+
+	mu        sync.RWMutex
+	cert      *tls.Certificate
+	clientCas *x509.CertPool
+	stamps    map[string]fileStamp
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewReloader read the files of opts and check them for a change every interval
+func NewReloader(opts Options, interval time.Duration) (*Reloader, error) {
+	r := &Reloader{
+		opts:     opts,
+		interval: interval,
+		stop:     make(chan struct{}),
+	}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	if interval > 0 {
+		go r.watch()
+	}
+	return r, nil
+}
+
+// Reload read the certificate, its key and the client CAs
+func (r *Reloader) Reload() error {
+	stamps := r.stat()
+
+	cert, err := tls.LoadX509KeyPair(r.opts.CertFile, r.opts.KeyFile)
+	if err != nil {
+		r.setStamps(stamps)
+		return fmt.Errorf("cannot load the certificate: %v", err)
+	}
+
+	var clientCas *x509.CertPool
+	if r.opts.ClientCaFile != "" {
+		pem, err := ioutil.ReadFile(r.opts.ClientCaFile)
+		if err != nil {
+			r.setStamps(stamps)
+			return fmt.Errorf("cannot read the client CAs: %v", err)
+		}
+		clientCas = x509.NewCertPool()
+		if !clientCas.AppendCertsFromPEM(pem) {
+			r.setStamps(stamps)
+			return fmt.Errorf("%s doesn't have any certificate", r.opts.ClientCaFile)
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cert = &cert
+	r.clientCas = clientCas
+	r.stamps = stamps
+	return nil
+}
+
+// a file that fails to load isn't read again until it changes
+func (r *Reloader) setStamps(stamps map[string]fileStamp) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.stamps = stamps
+}
+
+func (r *Reloader) stat() map[string]fileStamp {
+	stamps := make(map[string]fileStamp, 3)
+	for _, file := range []string{r.opts.CertFile, r.opts.KeyFile, r.opts.ClientCaFile} {
+		if file == "" {
+			continue
+		}
+		if info, err := os.Stat(file); err == nil {
+			stamps[file] = fileStamp{modTime: info.ModTime(), size: info.Size()}
+		}
+	}
+	return stamps
+}
+
+func (r *Reloader) changed() bool {
+	stamps := r.stat()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if len(stamps) != len(r.stamps) {
+		return true
+	}
+	for file, stamp := range stamps {
+		if current, ok := r.stamps[file]; !ok || !current.modTime.Equal(stamp.modTime) || current.size != stamp.size {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Reloader) watch() {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if !r.changed() {
+				continue
+			}
+			if err := r.Reload(); err != nil {
+				log.Err(err).Msg("cannot reload the tls certificate, the current one is kept")
+				continue
+			}
+			log.Info().Str("cert", r.opts.CertFile).Msg("tls certificate is reloaded")
+		case <-r.stop:
+			return
+		}
+	}
+}
+
+// Close stop checking the files
+func (r *Reloader) Close() {
+	r.stopOnce.Do(func() { close(r.stop) })
+}
+
+// Certificate return the current certificate of the server
+func (r *Reloader) Certificate() *tls.Certificate {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cert
+}
+
+func (r *Reloader) ClientCas() *x509.CertPool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.clientCas
+}
+
+// ServerConfig return the tls config of the server, each handshake gets the current certificate and client CAs.
+// a client certificate is verified when it's sent, the routes that require one check it themselves
+func (r *Reloader) ServerConfig() (*tls.Config, error) {
+	minVersion, err := ParseMinVersion(r.opts.MinVersion)
+	if err != nil {
+		return nil, err
+	}
+	cipherSuites, err := ParseCipherSuites(r.opts.CipherSuites)
+	if err != nil {
+		return nil, err
+	}
+
+	nextProtos := []string{"http/1.1"}
+	if r.opts.Http2 {
+		nextProtos = []string{"h2", "http/1.1"}
+	}
+
+	base := &tls.Config{
+		MinVersion:   minVersion,
+		CipherSuites: cipherSuites,
+		NextProtos:   nextProtos,
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			return r.Certificate(), nil
+		},
+	}
+
+	config := base.Clone()
+	config.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		handshake := base.Clone()
+		if clientCas := r.ClientCas(); clientCas != nil {
+			handshake.ClientCAs = clientCas
+			handshake.ClientAuth = tls.VerifyClientCertIfGiven
+		}
+		return handshake, nil
+	}
+	return config, nil
+}
@@ -0,0 +1,118 @@
+package tlsconfig_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"golang-starter/internal/utils/tlsconfig"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeCert write a self signed certificate of serial and its key to dir
+func writeCert(t *testing.T, dir string, serial int64) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		DNSNames:     []string{"localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDer, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+
+	certFile := filepath.Join(dir, "server.crt")
+	keyFile := filepath.Join(dir, "server.key")
+	require.NoError(t, ioutil.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	require.NoError(t, ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDer}), 0600))
+
+	// the files must look changed even when they're written within the resolution of the clock
+	modTime := time.Now().Add(time.Duration(serial) * time.Second)
+	require.NoError(t, os.Chtimes(certFile, modTime, modTime))
+	require.NoError(t, os.Chtimes(keyFile, modTime, modTime))
+	return certFile, keyFile
+}
+
+func serialOf(t *testing.T, cert *tls.Certificate) int64 {
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	require.NoError(t, err)
+	return leaf.SerialNumber.Int64()
+}
+
+func TestReloaderReloadsChangedFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tlsconfig")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	certFile, keyFile := writeCert(t, dir, 1)
+	reloader, err := tlsconfig.NewReloader(tlsconfig.Options{CertFile: certFile, KeyFile: keyFile}, 10*time.Millisecond)
+	require.NoError(t, err)
+	defer reloader.Close()
+	assert.Equal(t, int64(1), serialOf(t, reloader.Certificate()))
+
+	writeCert(t, dir, 2)
+	assert.Eventually(t, func() bool {
+		return serialOf(t, reloader.Certificate()) == 2
+	}, time.Second, 10*time.Millisecond)
+
+	// an invalid certificate keeps the current one
+	require.NoError(t, ioutil.WriteFile(certFile, []byte("not a certificate"), 0600))
+	assert.Error(t, reloader.Reload())
+	assert.Equal(t, int64(2), serialOf(t, reloader.Certificate()))
+}
+
+func TestServerConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tlsconfig")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	certFile, keyFile := writeCert(t, dir, 1)
+	reloader, err := tlsconfig.NewReloader(tlsconfig.Options{
+		CertFile:     certFile,
+		KeyFile:      keyFile,
+		ClientCaFile: certFile,
+		MinVersion:   "1.3",
+		Http2:        true,
+	}, 0)
+	require.NoError(t, err)
+
+	config, err := reloader.ServerConfig()
+	require.NoError(t, err)
+	assert.Equal(t, uint16(tls.VersionTLS13), config.MinVersion)
+	assert.Equal(t, []string{"h2", "http/1.1"}, config.NextProtos)
+
+	handshake, err := config.GetConfigForClient(&tls.ClientHelloInfo{})
+	require.NoError(t, err)
+	assert.Equal(t, tls.VerifyClientCertIfGiven, handshake.ClientAuth)
+	assert.NotNil(t, handshake.ClientCAs)
+}
+
+func TestParseOptions(t *testing.T) {
+	version, err := tlsconfig.ParseMinVersion("")
+	require.NoError(t, err)
+	assert.Equal(t, uint16(tls.VersionTLS12), version)
+	_, err = tlsconfig.ParseMinVersion("1.0")
+	assert.Error(t, err)
+
+	suites, err := tlsconfig.ParseCipherSuites([]string{"TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256"})
+	require.NoError(t, err)
+	assert.Equal(t, []uint16{tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256}, suites)
+	_, err = tlsconfig.ParseCipherSuites([]string{"TLS_RSA_WITH_RC4_128_SHA"})
+	assert.Error(t, err)
+}
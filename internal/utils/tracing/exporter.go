@@ -0,0 +1,84 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+)
+
+// Exporter send the ended spans to a backend, Export is called by one goroutine at a time
+type Exporter interface {
+	Export(ctx context.Context, spans []SpanData) error
+	Shutdown(ctx context.Context) error
+}
+
+type StdoutExporter struct {
+	mu      sync.Mutex
+	encoder *json.Encoder
+}
+
+// NewStdoutExporter write a json line per span to w, it's meant for a local debug
+func NewStdoutExporter(w io.Writer) *StdoutExporter {
+	return &StdoutExporter{encoder: json.NewEncoder(w)}
+}
+
+type stdoutSpan struct {
+	TraceId       string                 `json:"trace_id"`
+	SpanId        string                 `json:"span_id"`
+	ParentSpanId  string                 `json:"parent_span_id,omitempty"`
+	Name          string                 `json:"name"`
+	Kind          string                 `json:"kind"`
+	Start         time.Time              `json:"start"`
+	DurationMs    float64                `json:"duration_ms"`
+	Attributes    map[string]interface{} `json:"attributes,omitempty"`
+	Status        string                 `json:"status,omitempty"`
+	StatusMessage string                 `json:"status_message,omitempty"`
+}
+
+var kindNames = map[SpanKind]string{
+	KindInternal: "internal",
+	KindServer:   "server",
+	KindClient:   "client",
+}
+
+var statusNames = map[StatusCode]string{
+	StatusOk:    "ok",
+	StatusError: "error",
+}
+
+func (e *StdoutExporter) Export(ctx context.Context, spans []SpanData) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, span := range spans {
+		line := stdoutSpan{
+			TraceId:       span.SpanContext.TraceId.String(),
+			SpanId:        span.SpanContext.SpanId.String(),
+			Name:          span.Name,
+			Kind:          kindNames[span.Kind],
+			Start:         span.Start,
+			DurationMs:    float64(span.End.Sub(span.Start).Microseconds()) / 1000,
+			Status:        statusNames[span.Status],
+			StatusMessage: span.StatusMessage,
+		}
+		if span.ParentSpanId.IsValid() {
+			line.ParentSpanId = span.ParentSpanId.String()
+		}
+		if len(span.Attributes) > 0 {
+			line.Attributes = make(map[string]interface{}, len(span.Attributes))
+			for _, attr := range span.Attributes {
+				line.Attributes[attr.Key] = attr.Value
+			}
+		}
+		if err := e.encoder.Encode(line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *StdoutExporter) Shutdown(ctx context.Context) error {
+	return nil
+}
@@ -0,0 +1,48 @@
+package tracing
+
+import (
+	"golang-starter/config"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	ExporterNone   = "none"
+	ExporterStdout = "stdout"
+	ExporterOtlp   = "otlp"
+)
+
+// InitTracer set the provider of TRACING, the spans are created whatever the exporter so the logs have a trace id
+func InitTracer() {
+	cfg := config.Get().Tracing
+
+	ratio := cfg.SampleRatio
+	if ratio <= 0 {
+		ratio = 1
+	}
+
+	var exporter Exporter
+	switch cfg.Exporter {
+	case "", ExporterNone:
+	case ExporterStdout:
+		exporter = NewStdoutExporter(os.Stdout)
+	case ExporterOtlp:
+		timeout := cfg.Otlp.Timeout
+		if timeout <= 0 {
+			timeout = 10 * time.Second
+		}
+		serviceName := cfg.ServiceName
+		if serviceName == "" {
+			serviceName = "golang-starter"
+		}
+		exporter = NewOtlpExporter(cfg.Otlp.Endpoint, serviceName, cfg.Otlp.Headers, &http.Client{Timeout: timeout})
+	default:
+		log.Error().Str("exporter", cfg.Exporter).Msg("unknown tracing exporter, the spans aren't exported")
+	}
+
+	SetProvider(NewProvider(exporter, ratio))
+	log.Info().Str("exporter", cfg.Exporter).Float64("sample_ratio", ratio).Msg("Tracing initialized")
+}
@@ -0,0 +1,175 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const otlpTracesPath = "/v1/traces"
+
+// OtlpExporter post the spans to an otlp collector over http with the json encoding,
+// so it doesn't need the protobuf of the collector
+type OtlpExporter struct {
+	url         string
+	headers     map[string]string
+	serviceName string
+	client      *http.Client
+}
+
+// NewOtlpExporter post to the /v1/traces of endpoint like http://localhost:4318, headers are added
+// to every request like the api key of a hosted collector
+func NewOtlpExporter(endpoint, serviceName string, headers map[string]string, client *http.Client) *OtlpExporter {
+	url := strings.TrimRight(endpoint, "/")
+	if !strings.HasSuffix(url, otlpTracesPath) {
+		url += otlpTracesPath
+	}
+	return &OtlpExporter{
+		url:         url,
+		headers:     headers,
+		serviceName: serviceName,
+		client:      client,
+	}
+}
+
+// the json mapping of the otlp protobuf, the ids are hex and the 64 bit integers are strings
+type (
+	otlpRequest struct {
+		ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+	}
+	otlpResourceSpans struct {
+		Resource   otlpResource     `json:"resource"`
+		ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+	}
+	otlpResource struct {
+		Attributes []otlpKeyValue `json:"attributes"`
+	}
+	otlpScopeSpans struct {
+		Scope otlpScope  `json:"scope"`
+		Spans []otlpSpan `json:"spans"`
+	}
+	otlpScope struct {
+		Name string `json:"name"`
+	}
+	otlpSpan struct {
+		TraceId           string         `json:"traceId"`
+		SpanId            string         `json:"spanId"`
+		ParentSpanId      string         `json:"parentSpanId,omitempty"`
+		Name              string         `json:"name"`
+		Kind              SpanKind       `json:"kind"`
+		StartTimeUnixNano string         `json:"startTimeUnixNano"`
+		EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+		Attributes        []otlpKeyValue `json:"attributes,omitempty"`
+		Status            otlpStatus     `json:"status"`
+	}
+	otlpStatus struct {
+		Code    StatusCode `json:"code,omitempty"`
+		Message string     `json:"message,omitempty"`
+	}
+	otlpKeyValue struct {
+		Key   string    `json:"key"`
+		Value otlpValue `json:"value"`
+	}
+	otlpValue struct {
+		StringValue *string  `json:"stringValue,omitempty"`
+		IntValue    *string  `json:"intValue,omitempty"`
+		DoubleValue *float64 `json:"doubleValue,omitempty"`
+		BoolValue   *bool    `json:"boolValue,omitempty"`
+	}
+)
+
+func (e *OtlpExporter) Export(ctx context.Context, spans []SpanData) error {
+	body, err := json.Marshal(e.request(spans))
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, e.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range e.headers {
+		req.Header.Set(key, value)
+	}
+
+	res, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	io.Copy(ioutil.Discard, res.Body)
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %d from %s", res.StatusCode, e.url)
+	}
+	return nil
+}
+
+func (e *OtlpExporter) Shutdown(ctx context.Context) error {
+	e.client.CloseIdleConnections()
+	return nil
+}
+
+func (e *OtlpExporter) request(spans []SpanData) otlpRequest {
+	scopeSpans := otlpScopeSpans{
+		Scope: otlpScope{Name: "golang-starter"},
+		Spans: make([]otlpSpan, 0, len(spans)),
+	}
+	for _, span := range spans {
+		s := otlpSpan{
+			TraceId:           span.SpanContext.TraceId.String(),
+			SpanId:            span.SpanContext.SpanId.String(),
+			Name:              span.Name,
+			Kind:              span.Kind,
+			StartTimeUnixNano: strconv.FormatInt(span.Start.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(span.End.UnixNano(), 10),
+			Attributes:        otlpAttributes(span.Attributes),
+			Status:            otlpStatus{Code: span.Status, Message: span.StatusMessage},
+		}
+		if span.ParentSpanId.IsValid() {
+			s.ParentSpanId = span.ParentSpanId.String()
+		}
+		scopeSpans.Spans = append(scopeSpans.Spans, s)
+	}
+
+	return otlpRequest{
+		ResourceSpans: []otlpResourceSpans{{
+			Resource: otlpResource{Attributes: otlpAttributes([]Attribute{
+				String("service.name", e.serviceName),
+				String("telemetry.sdk.language", "go"),
+			})},
+			ScopeSpans: []otlpScopeSpans{scopeSpans},
+		}},
+	}
+}
+
+func otlpAttributes(attrs []Attribute) []otlpKeyValue {
+	kvs := make([]otlpKeyValue, 0, len(attrs))
+	for _, attr := range attrs {
+		var value otlpValue
+		switch v := attr.Value.(type) {
+		case string:
+			value.StringValue = &v
+		case int64:
+			s := strconv.FormatInt(v, 10)
+			value.IntValue = &s
+		case float64:
+			value.DoubleValue = &v
+		case bool:
+			value.BoolValue = &v
+		default:
+			s := fmt.Sprint(v)
+			value.StringValue = &s
+		}
+		kvs = append(kvs, otlpKeyValue{Key: attr.Key, Value: value})
+	}
+	return kvs
+}
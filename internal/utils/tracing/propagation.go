@@ -0,0 +1,76 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+)
+
+const (
+	TraceparentHeader = "traceparent"
+	TracestateHeader  = "tracestate"
+)
+
+type tracestateKey struct{}
+
+// Extract read the traceparent of a caller, the next span started from the returned context continues its trace.
+// an invalid traceparent is ignored and the tracestate is kept as is for the outgoing requests
+func Extract(ctx context.Context, header http.Header) context.Context {
+	sc, err := ParseTraceparent(header.Get(TraceparentHeader))
+	if err != nil {
+		return ctx
+	}
+	ctx = ContextWithRemoteSpanContext(ctx, sc)
+	if state := header.Get(TracestateHeader); state != "" {
+		ctx = context.WithValue(ctx, tracestateKey{}, state)
+	}
+	return ctx
+}
+
+// Inject write the traceparent of the span of ctx, so the callee continues the trace
+func Inject(ctx context.Context, header http.Header) {
+	sc := SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return
+	}
+	header.Set(TraceparentHeader, sc.Traceparent())
+	if state, ok := ctx.Value(tracestateKey{}).(string); ok {
+		header.Set(TracestateHeader, state)
+	}
+}
+
+// Transport trace the outgoing requests with a client span and propagate the trace to the callee
+type Transport struct {
+	Base http.RoundTripper
+}
+
+func NewTransport(base http.RoundTripper) *Transport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &Transport{Base: base}
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx, span := Start(req.Context(), "HTTP "+req.Method, WithKind(KindClient))
+	defer span.End()
+	span.SetAttributes(
+		String("http.method", req.Method),
+		String("http.url", req.URL.Scheme+"://"+req.URL.Host+req.URL.Path),
+	)
+
+	// a RoundTripper must not modify the request
+	req = req.Clone(ctx)
+	Inject(ctx, req.Header)
+
+	res, err := t.Base.RoundTrip(req)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	span.SetAttributes(Int("http.status_code", res.StatusCode))
+	if res.StatusCode >= http.StatusInternalServerError {
+		span.SetStatus(StatusError, "HTTP "+strconv.Itoa(res.StatusCode))
+	}
+	return res, nil
+}
@@ -0,0 +1,149 @@
+package tracing
+
+import (
+	"context"
+	"golang-starter/internal/utils/metrics"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	defaultQueueSize = 2048
+	defaultBatchSize = 512
+	defaultInterval  = 5 * time.Second
+	defaultTimeout   = 10 * time.Second
+)
+
+var tracingSpansTotal = metrics.NewCounter("tracing_spans_total",
+	"the ended spans by whether they're exported, dropped since the queue is full or failed to export", "result")
+
+// Provider batch the ended spans to its exporter, a provider without exporter still create the spans
+// so the trace ids are in the logs and propagated, but it drops them
+type Provider struct {
+	exporter Exporter
+	ratio    float64
+	timeout  time.Duration
+
+	queue    chan SpanData
+	stop     chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewProvider export the spans of the sampled traces to exporter, ratio is the share of the new traces that are sampled.
+// a trace continued from a caller follow the decision of the caller
+func NewProvider(exporter Exporter, ratio float64) *Provider {
+	p := &Provider{
+		exporter: exporter,
+		ratio:    ratio,
+		timeout:  defaultTimeout,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	if exporter == nil {
+		close(p.done)
+		return p
+	}
+
+	p.queue = make(chan SpanData, defaultQueueSize)
+	go p.run(defaultBatchSize, defaultInterval)
+	return p
+}
+
+func (p *Provider) export(data SpanData) {
+	if p.exporter == nil {
+		return
+	}
+	select {
+	case p.queue <- data:
+	default:
+		tracingSpansTotal.Inc("dropped")
+	}
+}
+
+// run export a batch when it's full or every interval, the queue is drained once the provider stops
+func (p *Provider) run(batchSize int, interval time.Duration) {
+	defer close(p.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	batch := make([]SpanData, 0, batchSize)
+	for {
+		select {
+		case data := <-p.queue:
+			batch = append(batch, data)
+			if len(batch) >= batchSize {
+				batch = p.exportBatch(batch)
+			}
+		case <-ticker.C:
+			batch = p.exportBatch(batch)
+		case <-p.stop:
+			for {
+				select {
+				case data := <-p.queue:
+					batch = append(batch, data)
+				default:
+					p.exportBatch(batch)
+					return
+				}
+			}
+		}
+	}
+}
+
+func (p *Provider) exportBatch(batch []SpanData) []SpanData {
+	if len(batch) == 0 {
+		return batch
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), p.timeout)
+	defer cancel()
+	if err := p.exporter.Export(ctx, batch); err != nil {
+		log.Warn().Err(err).Int("spans", len(batch)).Msg("cannot export the spans")
+		tracingSpansTotal.Add(float64(len(batch)), "failed")
+	} else {
+		tracingSpansTotal.Add(float64(len(batch)), "exported")
+	}
+	return make([]SpanData, 0, cap(batch))
+}
+
+// Shutdown export the queued spans then close the exporter, the spans that end after are dropped
+func (p *Provider) Shutdown(ctx context.Context) error {
+	p.stopOnce.Do(func() { close(p.stop) })
+
+	select {
+	case <-p.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	if p.exporter == nil {
+		return nil
+	}
+	return p.exporter.Shutdown(ctx)
+}
+
+var (
+	mu       sync.RWMutex
+	provider = NewProvider(nil, 1)
+)
+
+func global() *Provider {
+	mu.RLock()
+	defer mu.RUnlock()
+	return provider
+}
+
+// SetProvider make p the provider of the next spans
+func SetProvider(p *Provider) {
+	mu.Lock()
+	defer mu.Unlock()
+	provider = p
+}
+
+// Shutdown flush the spans of the provider, it must be called before the application exits
+func Shutdown(ctx context.Context) error {
+	return global().Shutdown(ctx)
+}
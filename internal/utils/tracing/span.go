@@ -0,0 +1,210 @@
+package tracing
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type SpanKind int
+
+// the kinds of the otlp specification
+const (
+	KindInternal SpanKind = 1
+	KindServer   SpanKind = 2
+	KindClient   SpanKind = 3
+)
+
+type StatusCode int
+
+const (
+	StatusUnset StatusCode = 0
+	StatusOk    StatusCode = 1
+	StatusError StatusCode = 2
+)
+
+// Attribute is a key value of a span, Value is a string, an int64, a float64 or a bool
+type Attribute struct {
+	Key   string
+	Value interface{}
+}
+
+func String(key, value string) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+func Int(key string, value int) Attribute {
+	return Attribute{Key: key, Value: int64(value)}
+}
+
+func Int64(key string, value int64) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+func Float64(key string, value float64) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+func Bool(key string, value bool) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+// SpanData is an ended span as it's exported
+type SpanData struct {
+	SpanContext   SpanContext
+	ParentSpanId  SpanId
+	Name          string
+	Kind          SpanKind
+	Start         time.Time
+	End           time.Time
+	Attributes    []Attribute
+	Status        StatusCode
+	StatusMessage string
+}
+
+// Span is an operation of a trace, it's exported when it ends if its trace is sampled.
+// the methods are safe for concurrent use and do nothing once the span ended
+type Span struct {
+	mu       sync.Mutex
+	data     SpanData
+	ended    bool
+	provider *Provider
+}
+
+type startConfig struct {
+	kind  SpanKind
+	start time.Time
+}
+
+type StartOption func(*startConfig)
+
+func WithKind(kind SpanKind) StartOption {
+	return func(c *startConfig) {
+		c.kind = kind
+	}
+}
+
+// WithStart start the span at t instead of now, for an operation that is only traced once it's done
+func WithStart(t time.Time) StartOption {
+	return func(c *startConfig) {
+		c.start = t
+	}
+}
+
+type spanKey struct{}
+type remoteKey struct{}
+
+// Start a span that is the child of the span of ctx, or of the caller read by Extract.
+// it's the root of a new trace otherwise
+func Start(ctx context.Context, name string, opts ...StartOption) (context.Context, *Span) {
+	cfg := startConfig{kind: KindInternal}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if cfg.start.IsZero() {
+		cfg.start = time.Now()
+	}
+
+	p := global()
+	span := &Span{
+		data: SpanData{
+			Name:  name,
+			Kind:  cfg.kind,
+			Start: cfg.start,
+		},
+		provider: p,
+	}
+
+	parent := SpanContextFromContext(ctx)
+	if parent.IsValid() {
+		span.data.SpanContext = SpanContext{
+			TraceId: parent.TraceId,
+			SpanId:  newSpanId(),
+			Sampled: parent.Sampled,
+		}
+		span.data.ParentSpanId = parent.SpanId
+	} else {
+		traceId := newTraceId()
+		span.data.SpanContext = SpanContext{
+			TraceId: traceId,
+			SpanId:  newSpanId(),
+			Sampled: sampled(traceId, p.ratio),
+		}
+	}
+
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+// SpanFromContext return the current span of ctx, nil when there is none
+func SpanFromContext(ctx context.Context) *Span {
+	span, _ := ctx.Value(spanKey{}).(*Span)
+	return span
+}
+
+// SpanContextFromContext return the span context of the current span of ctx, or the one of the caller
+func SpanContextFromContext(ctx context.Context) SpanContext {
+	if span := SpanFromContext(ctx); span != nil {
+		return span.SpanContext()
+	}
+	sc, _ := ctx.Value(remoteKey{}).(SpanContext)
+	return sc
+}
+
+// ContextWithRemoteSpanContext make sc the parent of the next span started from ctx
+func ContextWithRemoteSpanContext(ctx context.Context, sc SpanContext) context.Context {
+	return context.WithValue(ctx, remoteKey{}, sc)
+}
+
+func (s *Span) SpanContext() SpanContext {
+	return s.data.SpanContext
+}
+
+func (s *Span) SetName(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.ended {
+		s.data.Name = name
+	}
+}
+
+func (s *Span) SetAttributes(attrs ...Attribute) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.ended {
+		s.data.Attributes = append(s.data.Attributes, attrs...)
+	}
+}
+
+func (s *Span) SetStatus(code StatusCode, message string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.ended {
+		s.data.Status = code
+		s.data.StatusMessage = message
+	}
+}
+
+// RecordError set the status of the span to error, a nil err does nothing
+func (s *Span) RecordError(err error) {
+	if err == nil {
+		return
+	}
+	s.SetStatus(StatusError, err.Error())
+}
+
+// End the span and export it, only the first call counts
+func (s *Span) End() {
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.data.End = time.Now()
+	data := s.data
+	s.mu.Unlock()
+
+	if data.SpanContext.Sampled {
+		s.provider.export(data)
+	}
+}
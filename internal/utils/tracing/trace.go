@@ -0,0 +1,117 @@
+package tracing
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// TraceId is the id of a trace shared by every span of a request, across the services
+type TraceId [16]byte
+
+func (t TraceId) IsValid() bool {
+	return t != TraceId{}
+}
+
+func (t TraceId) String() string {
+	return hex.EncodeToString(t[:])
+}
+
+// SpanId is the id of a span in its trace
+type SpanId [8]byte
+
+func (s SpanId) IsValid() bool {
+	return s != SpanId{}
+}
+
+func (s SpanId) String() string {
+	return hex.EncodeToString(s[:])
+}
+
+// SpanContext is what a span shares with its children, Remote is set when it's read from a caller
+type SpanContext struct {
+	TraceId TraceId
+	SpanId  SpanId
+	Sampled bool
+	Remote  bool
+}
+
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceId.IsValid() && sc.SpanId.IsValid()
+}
+
+// Traceparent format sc as a w3c traceparent header, like 00-<trace id>-<span id>-01
+func (sc SpanContext) Traceparent() string {
+	flags := "00"
+	if sc.Sampled {
+		flags = "01"
+	}
+	return "00-" + sc.TraceId.String() + "-" + sc.SpanId.String() + "-" + flags
+}
+
+// ParseTraceparent read a w3c traceparent header, the unknown versions are read like the 00 one
+// as the specification asks, as long as the fields of 00 are there
+func ParseTraceparent(header string) (SpanContext, error) {
+	var sc SpanContext
+
+	parts := strings.Split(strings.TrimSpace(header), "-")
+	if len(parts) < 4 {
+		return sc, fmt.Errorf("traceparent %q doesn't have 4 fields", header)
+	}
+	version, traceId, spanId, flags := parts[0], parts[1], parts[2], parts[3]
+	if len(version) != 2 || version == "ff" || (version == "00" && len(parts) != 4) {
+		return sc, fmt.Errorf("traceparent %q has an invalid version", header)
+	}
+	if err := decodeHex(sc.TraceId[:], traceId); err != nil || !sc.TraceId.IsValid() {
+		return SpanContext{}, fmt.Errorf("traceparent %q has an invalid trace id", header)
+	}
+	if err := decodeHex(sc.SpanId[:], spanId); err != nil || !sc.SpanId.IsValid() {
+		return SpanContext{}, fmt.Errorf("traceparent %q has an invalid span id", header)
+	}
+
+	var flag [1]byte
+	if err := decodeHex(flag[:], flags); err != nil {
+		return SpanContext{}, fmt.Errorf("traceparent %q has invalid flags", header)
+	}
+	sc.Sampled = flag[0]&1 == 1
+	sc.Remote = true
+	return sc, nil
+}
+
+// decodeHex only accept the lowercase hex of the exact length of dst
+func decodeHex(dst []byte, s string) error {
+	if len(s) != hex.EncodedLen(len(dst)) || strings.ToLower(s) != s {
+		return fmt.Errorf("invalid hex %q", s)
+	}
+	_, err := hex.Decode(dst, []byte(s))
+	return err
+}
+
+func newTraceId() TraceId {
+	var t TraceId
+	for !t.IsValid() {
+		rand.Read(t[:])
+	}
+	return t
+}
+
+func newSpanId() SpanId {
+	var s SpanId
+	for !s.IsValid() {
+		rand.Read(s[:])
+	}
+	return s
+}
+
+// sampled decide on the trace id so every service that shares the ratio keeps the same traces
+func sampled(traceId TraceId, ratio float64) bool {
+	if ratio >= 1 {
+		return true
+	}
+	if ratio <= 0 {
+		return false
+	}
+	return binary.BigEndian.Uint64(traceId[8:])>>1 < uint64(ratio*(1<<63))
+}
@@ -0,0 +1,91 @@
+package tracing_test
+
+import (
+	"context"
+	"encoding/json"
+	"golang-starter/internal/utils/tracing"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTraceparent(t *testing.T) {
+	header := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	sc, err := tracing.ParseTraceparent(header)
+	require.NoError(t, err)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", sc.TraceId.String())
+	assert.Equal(t, "00f067aa0ba902b7", sc.SpanId.String())
+	assert.True(t, sc.Sampled)
+	assert.True(t, sc.Remote)
+	assert.Equal(t, header, sc.Traceparent())
+
+	for _, invalid := range []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+	} {
+		_, err := tracing.ParseTraceparent(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestStartContinuesTheCaller(t *testing.T) {
+	header := http.Header{}
+	header.Set(tracing.TraceparentHeader, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+	ctx, server := tracing.Start(tracing.Extract(context.Background(), header), "server")
+	_, child := tracing.Start(ctx, "child")
+
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", server.SpanContext().TraceId.String())
+	assert.Equal(t, server.SpanContext().TraceId, child.SpanContext().TraceId)
+	assert.NotEqual(t, server.SpanContext().SpanId, child.SpanContext().SpanId)
+
+	outgoing := http.Header{}
+	tracing.Inject(ctx, outgoing)
+	assert.Equal(t, server.SpanContext().Traceparent(), outgoing.Get(tracing.TraceparentHeader))
+}
+
+func TestOtlpExporter(t *testing.T) {
+	var body map[string]interface{}
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/traces", r.URL.Path)
+		assert.Equal(t, "secret", r.Header.Get("X-Api-Key"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+	}))
+	defer collector.Close()
+
+	exporter := tracing.NewOtlpExporter(collector.URL, "starter", map[string]string{"X-Api-Key": "secret"}, collector.Client())
+	provider := tracing.NewProvider(exporter, 1)
+	tracing.SetProvider(provider)
+	defer tracing.SetProvider(tracing.NewProvider(nil, 1))
+
+	ctx, parent := tracing.Start(context.Background(), "parent", tracing.WithKind(tracing.KindServer))
+	_, child := tracing.Start(ctx, "child")
+	child.SetAttributes(tracing.String("db.statement", "SELECT 1"), tracing.Int("rows", 1))
+	child.End()
+	parent.End()
+	require.NoError(t, provider.Shutdown(context.Background()))
+
+	resourceSpans := body["resourceSpans"].([]interface{})[0].(map[string]interface{})
+	spans := resourceSpans["scopeSpans"].([]interface{})[0].(map[string]interface{})["spans"].([]interface{})
+	require.Len(t, spans, 2)
+
+	first := spans[0].(map[string]interface{})
+	second := spans[1].(map[string]interface{})
+	assert.Equal(t, "child", first["name"])
+	assert.Equal(t, "parent", second["name"])
+	assert.Equal(t, second["spanId"], first["parentSpanId"])
+	assert.Equal(t, second["traceId"], first["traceId"])
+	assert.Equal(t, float64(2), second["kind"])
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"key": "db.statement", "value": map[string]interface{}{"stringValue": "SELECT 1"}},
+		map[string]interface{}{"key": "rows", "value": map[string]interface{}{"intValue": "1"}},
+	}, first["attributes"])
+}
@@ -0,0 +1,102 @@
+package validation
+
+import (
+	"fmt"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+var builtinRules = map[string]Rule{
+	"min": {
+		Check: func(value reflect.Value, param string) bool {
+			return compare(value, param, func(n, limit float64) bool { return n >= limit })
+		},
+		Message: func(value reflect.Value, param string) string {
+			return sizeMessage(value, "at least", param)
+		},
+	},
+	"max": {
+		Check: func(value reflect.Value, param string) bool {
+			return compare(value, param, func(n, limit float64) bool { return n <= limit })
+		},
+		Message: func(value reflect.Value, param string) string {
+			return sizeMessage(value, "at most", param)
+		},
+	},
+	"email": {
+		Check: func(value reflect.Value, _ string) bool {
+			if value.Kind() != reflect.String {
+				return false
+			}
+			// a display name like "Name <name@mail.com>" is not an email
+			address, err := mail.ParseAddress(value.String())
+			return err == nil && address.Address == value.String()
+		},
+		Message: func(reflect.Value, string) string {
+			return "must be a valid email"
+		},
+	},
+	"url": {
+		Check: func(value reflect.Value, _ string) bool {
+			if value.Kind() != reflect.String {
+				return false
+			}
+			u, err := url.ParseRequestURI(value.String())
+			return err == nil && u.Scheme != "" && u.Host != ""
+		},
+		Message: func(reflect.Value, string) string {
+			return "must be a valid absolute url"
+		},
+	},
+	"oneof": {
+		Check: func(value reflect.Value, param string) bool {
+			text := fmt.Sprint(value.Interface())
+			for _, option := range strings.Fields(param) {
+				if text == option {
+					return true
+				}
+			}
+			return false
+		},
+		Message: func(_ reflect.Value, param string) string {
+			return "must be one of " + strings.Join(strings.Fields(param), ", ")
+		},
+	},
+}
+
+// compare the size of value to the limit of param, the size is the number itself,
+// the characters of a string or the length of a slice or a map
+func compare(value reflect.Value, param string, ok func(n, limit float64) bool) bool {
+	limit, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		return false
+	}
+
+	switch value.Kind() {
+	case reflect.String:
+		return ok(float64(utf8.RuneCountInString(value.String())), limit)
+	case reflect.Slice, reflect.Map, reflect.Array:
+		return ok(float64(value.Len()), limit)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return ok(float64(value.Int()), limit)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return ok(float64(value.Uint()), limit)
+	case reflect.Float32, reflect.Float64:
+		return ok(value.Float(), limit)
+	}
+	return false
+}
+
+func sizeMessage(value reflect.Value, bound, param string) string {
+	switch value.Kind() {
+	case reflect.String:
+		return fmt.Sprintf("must have %s %s characters", bound, param)
+	case reflect.Slice, reflect.Map, reflect.Array:
+		return fmt.Sprintf("must have %s %s items", bound, param)
+	}
+	return fmt.Sprintf("must be %s %s", bound, param)
+}
@@ -0,0 +1,228 @@
+package validation
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// FieldError is a field that breaks a rule, Field is the json path of the field like images[0]
+type FieldError struct {
+	Field   string
+	Rule    string
+	Param   string
+	Message string
+}
+
+// Errors is every field error of a struct, it's returned by Struct
+type Errors []FieldError
+
+func (e Errors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, err := range e {
+		messages = append(messages, err.Field+" "+err.Message)
+	}
+	return strings.Join(messages, ", ")
+}
+
+// Rule tell whether value is valid for the param of the tag, like 3 of min=3. value is never a nil pointer,
+// a nil pointer only breaks required
+type Rule struct {
+	Check func(value reflect.Value, param string) bool
+	// Message explain the rule to the client, like must have at least 3 characters
+	Message func(value reflect.Value, param string) string
+}
+
+// Validator check the structs by their validate tag, like `validate:"required,min=1,max=100"`.
+// the rules are separated by a comma, omitempty skip the rules of an empty field and dive apply the rules
+// after it to each element of a slice or a map. the nested structs are always validated
+type Validator struct {
+	mu    sync.RWMutex
+	rules map[string]Rule
+}
+
+// New create a validator with the builtin rules
+func New() *Validator {
+	v := &Validator{rules: map[string]Rule{}}
+	for name, rule := range builtinRules {
+		v.rules[name] = rule
+	}
+	return v
+}
+
+// Register add a custom rule, it replaces the rule of the same name
+func (v *Validator) Register(name string, rule Rule) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.rules[name] = rule
+}
+
+// Struct validate s, a struct or a pointer to it. it returns Errors when a field is not valid
+func (v *Validator) Struct(s interface{}) error {
+	value := reflect.ValueOf(s)
+	for value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return fmt.Errorf("validation: cannot validate a nil %T", s)
+		}
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return fmt.Errorf("validation: cannot validate %T, it's not a struct", s)
+	}
+
+	var errs Errors
+	if err := v.validateStruct(value, "", &errs); err != nil {
+		return err
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func (v *Validator) validateStruct(value reflect.Value, path string, errs *Errors) error {
+	valueType := value.Type()
+	for i := 0; i < value.NumField(); i++ {
+		field := valueType.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+
+		name := fieldName(field)
+		if name == "-" {
+			continue
+		}
+		if path != "" {
+			name = path + "." + name
+		}
+
+		if err := v.validateField(value.Field(i), name, field.Tag.Get("validate"), errs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (v *Validator) validateField(value reflect.Value, name, tag string, errs *Errors) error {
+	var tags []string
+	if tag != "" && tag != "-" {
+		tags = strings.Split(tag, ",")
+	}
+
+	for i, ruleTag := range tags {
+		ruleName, param := ruleTag, ""
+		if idx := strings.Index(ruleTag, "="); idx >= 0 {
+			ruleName, param = ruleTag[:idx], ruleTag[idx+1:]
+		}
+
+		switch ruleName {
+		case "omitempty":
+			// a pointer is only empty when it's nil, so a field that is sent can still be required
+			if value.Kind() == reflect.Ptr && value.IsNil() || value.Kind() != reflect.Ptr && isEmpty(value) {
+				return nil
+			}
+			continue
+		case "required":
+			if isEmpty(value) {
+				*errs = append(*errs, FieldError{Field: name, Rule: ruleName, Message: "is required"})
+				return nil
+			}
+			continue
+		case "dive":
+			return v.dive(indirect(value), name, strings.Join(tags[i+1:], ","), errs)
+		}
+
+		elem := indirect(value)
+		if !elem.IsValid() {
+			// a nil pointer is only checked by required
+			return nil
+		}
+
+		v.mu.RLock()
+		rule, ok := v.rules[ruleName]
+		v.mu.RUnlock()
+		if !ok {
+			return fmt.Errorf("validation: unknown rule %q of %s", ruleName, name)
+		}
+		if !rule.Check(elem, param) {
+			*errs = append(*errs, FieldError{Field: name, Rule: ruleName, Param: param, Message: rule.Message(elem, param)})
+			return nil
+		}
+	}
+
+	elem := indirect(value)
+	if elem.IsValid() && elem.Kind() == reflect.Struct {
+		return v.validateStruct(elem, name, errs)
+	}
+	return nil
+}
+
+func (v *Validator) dive(value reflect.Value, name, tag string, errs *Errors) error {
+	switch value.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			if err := v.validateField(value.Index(i), fmt.Sprintf("%s[%d]", name, i), tag, errs); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		for _, key := range value.MapKeys() {
+			if err := v.validateField(value.MapIndex(key), fmt.Sprintf("%s[%v]", name, key.Interface()), tag, errs); err != nil {
+				return err
+			}
+		}
+	case reflect.Invalid:
+	default:
+		return fmt.Errorf("validation: cannot dive into %s of %s", value.Kind(), name)
+	}
+	return nil
+}
+
+// fieldName is the json name of the field, so the errors match the request body
+func fieldName(field reflect.StructField) string {
+	name := strings.Split(field.Tag.Get("json"), ",")[0]
+	if name == "" {
+		return field.Name
+	}
+	return name
+}
+
+func indirect(value reflect.Value) reflect.Value {
+	for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return reflect.Value{}
+		}
+		value = value.Elem()
+	}
+	return value
+}
+
+// isEmpty tell whether value is nil, zero or a blank string
+func isEmpty(value reflect.Value) bool {
+	value = indirect(value)
+	switch value.Kind() {
+	case reflect.Invalid:
+		return true
+	case reflect.String:
+		return strings.TrimSpace(value.String()) == ""
+	case reflect.Slice, reflect.Map, reflect.Array:
+		return value.Len() == 0
+	case reflect.Struct:
+		return false
+	default:
+		return value.IsZero()
+	}
+}
+
+var defaultValidator = New()
+
+// Struct validate s with the default validator
+func Struct(s interface{}) error {
+	return defaultValidator.Struct(s)
+}
+
+// Register add a custom rule to the default validator
+func Register(name string, rule Rule) {
+	defaultValidator.Register(name, rule)
+}
@@ -0,0 +1,112 @@
+package validation_test
+
+import (
+	"golang-starter/internal/utils/validation"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type address struct {
+	City string `json:"city" validate:"required"`
+}
+
+type request struct {
+	Email    string   `json:"email" validate:"required,email"`
+	Name     *string  `json:"name" validate:"omitempty,required,max=5"`
+	Age      int      `json:"age" validate:"min=18"`
+	Role     string   `json:"role" validate:"omitempty,oneof=user admin"`
+	Images   []string `json:"images" validate:"max=2,dive,url"`
+	Address  address  `json:"address"`
+	internal string
+}
+
+func fields(err error) map[string]string {
+	result := map[string]string{}
+	for _, fieldError := range err.(validation.Errors) {
+		result[fieldError.Field] = fieldError.Rule
+	}
+	return result
+}
+
+func TestStructValid(t *testing.T) {
+	name := "bob"
+	err := validation.Struct(&request{
+		Email:   "bob@gmail.com",
+		Name:    &name,
+		Age:     20,
+		Role:    "admin",
+		Images:  []string{"https://cdn.com/a.png"},
+		Address: address{City: "Jakarta"},
+	})
+	assert.NoError(t, err)
+}
+
+func TestStructInvalid(t *testing.T) {
+	blank := "  "
+	err := validation.Struct(request{
+		Email:  "Bob <bob@gmail.com>",
+		Name:   &blank,
+		Age:    17,
+		Role:   "root",
+		Images: []string{"https://cdn.com/a.png", "a.png"},
+	})
+	require.Error(t, err)
+	assert.Equal(t, map[string]string{
+		"email":        "email",
+		"name":         "required",
+		"age":          "min",
+		"role":         "oneof",
+		"images[1]":    "url",
+		"address.city": "required",
+	}, fields(err))
+}
+
+func TestStructOmitempty(t *testing.T) {
+	// a nil pointer and an empty string are skipped, the other rules still apply
+	err := validation.Struct(request{Email: "bob@gmail.com", Age: 18, Address: address{City: "Jakarta"}})
+	assert.NoError(t, err)
+}
+
+func TestStructSize(t *testing.T) {
+	name := "robert"
+	err := validation.Struct(request{
+		Email:   "bob@gmail.com",
+		Name:    &name,
+		Age:     18,
+		Images:  []string{"https://a.com", "https://b.com", "https://c.com"},
+		Address: address{City: "Jakarta"},
+	})
+	require.Error(t, err)
+	assert.Equal(t, map[string]string{"name": "max", "images": "max"}, fields(err))
+	assert.Contains(t, err.Error(), "name must have at most 5 characters")
+}
+
+func TestRegister(t *testing.T) {
+	v := validation.New()
+	v.Register("even", validation.Rule{
+		Check:   func(value reflect.Value, _ string) bool { return value.Int()%2 == 0 },
+		Message: func(reflect.Value, string) string { return "must be even" },
+	})
+
+	type even struct {
+		N int `json:"n" validate:"even"`
+	}
+	assert.NoError(t, v.Struct(even{N: 2}))
+	assert.Equal(t, map[string]string{"n": "even"}, fields(v.Struct(even{N: 3})))
+}
+
+func TestStructMisuse(t *testing.T) {
+	type unknown struct {
+		N int `validate:"nope"`
+	}
+	err := validation.Struct(unknown{})
+	require.Error(t, err)
+	_, ok := err.(validation.Errors)
+	assert.False(t, ok)
+
+	assert.Error(t, validation.Struct(nil))
+	assert.Error(t, validation.Struct("text"))
+}
@@ -5,6 +5,7 @@ import (
 	"golang-starter/config"
 	"golang-starter/internal/graceful"
 	"golang-starter/internal/logger"
+	"golang-starter/internal/utils/tracing"
 )
 
 //go:generate go run github.com/google/wire/cmd/wire
@@ -15,6 +16,7 @@ func main() {
 	// the default port is random from fiber
 	// init log
 	logger.InitLogger()
+	tracing.InitTracer()
 
 	initProtocol := InitHttpProtocol()
 
@@ -23,6 +25,9 @@ func main() {
 		config.Get().Application.Graceful.MaxSecond,
 		map[string]graceful.Operation{
 			"http": func(ctx context.Context) error {
+				initProtocol.Drain(ctx)
+				// the spans of the last requests are flushed once the server is closed
+				defer tracing.Shutdown(ctx)
 				return initProtocol.Shutdown(ctx)
 			},
 		},
@@ -6,6 +6,9 @@
 -- Generation Time: Jan 02, 2022 at 12:37 AM
 -- Server version: 5.7.33
 -- PHP Version: 7.2.34
+--
+-- the schema of a fresh install, it already has every change of upgrades/.
+-- a database created from an earlier dump applies the upgrades it doesn't have instead, in order
 
 SET SQL_MODE = "NO_AUTO_VALUE_ON_ZERO";
 START TRANSACTION;
@@ -65,19 +68,23 @@ CREATE TABLE `users` (
   `user_id` int(11) NOT NULL,
   `photo` text NOT NULL,
   `username` varchar(60) NOT NULL,
-  `email` varchar(100) NOT NULL,
+  `email` varchar(512) NOT NULL,
+  `email_bidx` varchar(100) DEFAULT NULL,
   `password` varchar(255) NOT NULL,
-  `name` varchar(100) NOT NULL,
+  `name` varchar(512) NOT NULL,
+  `role` varchar(20) NOT NULL DEFAULT 'user',
   `created_at` bigint(20) NOT NULL,
-  `updated_at` bigint(20) DEFAULT NULL
+  `updated_at` bigint(20) DEFAULT NULL,
+  `disabled_at` bigint(20) DEFAULT NULL,
+  `password_reset_at` bigint(20) DEFAULT NULL
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
 
 --
 -- Dumping data for table `users`
 --
 
-INSERT INTO `users` (`user_id`, `photo`, `username`, `email`, `password`, `name`, `created_at`, `updated_at`) VALUES
-(1, 'https://lh3.googleusercontent.com/-7NbJWT5zZVc/WomnR_q5wgI/AAAAAAAAFRY/yuW6Wd-7B0ocg9CSKlxqSuozeLkrZnw-gCEwYBhgLKs4DAMBZVoCN1JDyv2YpvnbbzVfPsZFncgL64Slg3_BJmvNihMXlHTUsmtq8bCZQrwNF3XUr4FhBoptnFnnx3TPECerUhZ1uzT8glAr46UWPynXpUNkaS4VWL6glsqdPoAec2NKdfcAgNbYW7O9UouasNXaFMS3EFOGnaWbVauMn_YpIR2v0pyyBhtAediPS-3zVzDz7txilDCh5_Fd0TlmtP5HDcWFunIUKCurQoY1tYggGE-3DC4oeu7JZwOYwyfjR2Z7wqyQ0diVcX9R-ayUhZf4zmiXHAaXPWb2_yj5Gs3P6ZD14H43nmtNHgmeoDkXPy01YPY7oUl2QLip4EN12vZbE-z4fOlNM69r4ODaW6xu5ko1BjdlHRL1Q2GSBPp1n9EN6jSdg_6K75rwN8Xe28vb4gvYTjoMWOg-wFBwS7KfGpL53114_Yhm1-BaKxiaO8PROpUE1au5UheS8dkZ0A6PIDzWYtD2BAcHFDNIaHq2OB_GHWoJXRU_Ie8Vpvg814KwaCBjBRcYRmNIvwuvM6LERMM_emyjx4xpWydMHB1uGZy77AtMLLaRW9AJVykq4-oWPmB46fqtspQucG17c-EGRZxSivlLA2evieOyOMNXI3PQF/w280-h280-p/12633720_1179451595415928_3654751649444577840_o.jpg', 'test', 'test@gmail.com', '$2y$12$jniETXMblositfIBwGGMv.GRicA4jUDoaaupu/vrhDRJ5Siw5fzWG', 'Test', 1586962829441, NULL);
+INSERT INTO `users` (`user_id`, `photo`, `username`, `email`, `password`, `name`, `role`, `created_at`, `updated_at`) VALUES
+(1, 'https://lh3.googleusercontent.com/-7NbJWT5zZVc/WomnR_q5wgI/AAAAAAAAFRY/yuW6Wd-7B0ocg9CSKlxqSuozeLkrZnw-gCEwYBhgLKs4DAMBZVoCN1JDyv2YpvnbbzVfPsZFncgL64Slg3_BJmvNihMXlHTUsmtq8bCZQrwNF3XUr4FhBoptnFnnx3TPECerUhZ1uzT8glAr46UWPynXpUNkaS4VWL6glsqdPoAec2NKdfcAgNbYW7O9UouasNXaFMS3EFOGnaWbVauMn_YpIR2v0pyyBhtAediPS-3zVzDz7txilDCh5_Fd0TlmtP5HDcWFunIUKCurQoY1tYggGE-3DC4oeu7JZwOYwyfjR2Z7wqyQ0diVcX9R-ayUhZf4zmiXHAaXPWb2_yj5Gs3P6ZD14H43nmtNHgmeoDkXPy01YPY7oUl2QLip4EN12vZbE-z4fOlNM69r4ODaW6xu5ko1BjdlHRL1Q2GSBPp1n9EN6jSdg_6K75rwN8Xe28vb4gvYTjoMWOg-wFBwS7KfGpL53114_Yhm1-BaKxiaO8PROpUE1au5UheS8dkZ0A6PIDzWYtD2BAcHFDNIaHq2OB_GHWoJXRU_Ie8Vpvg814KwaCBjBRcYRmNIvwuvM6LERMM_emyjx4xpWydMHB1uGZy77AtMLLaRW9AJVykq4-oWPmB46fqtspQucG17c-EGRZxSivlLA2evieOyOMNXI3PQF/w280-h280-p/12633720_1179451595415928_3654751649444577840_o.jpg', 'test', 'test@gmail.com', '$2y$12$jniETXMblositfIBwGGMv.GRicA4jUDoaaupu/vrhDRJ5Siw5fzWG', 'Test', 'user', 1586962829441, NULL);
 
 --
 -- Indexes for dumped tables
@@ -93,7 +100,8 @@ ALTER TABLE `products`
 -- Indexes for table `users`
 --
 ALTER TABLE `users`
-  ADD PRIMARY KEY (`user_id`);
+  ADD PRIMARY KEY (`user_id`),
+  ADD KEY `email_bidx` (`email_bidx`);
 
 --
 -- AUTO_INCREMENT for dumped tables
@@ -160,3 +168,129 @@ COMMIT;
 /*!40101 SET CHARACTER_SET_CLIENT=@OLD_CHARACTER_SET_CLIENT */;
 /*!40101 SET CHARACTER_SET_RESULTS=@OLD_CHARACTER_SET_RESULTS */;
 /*!40101 SET COLLATION_CONNECTION=@OLD_COLLATION_CONNECTION */;
+
+
+--
+-- Table structure for table `user_audit_logs`
+--
+
+CREATE TABLE `user_audit_logs` (
+  `audit_log_id` int(11) NOT NULL,
+  `actor_user_fkid` int(11) NOT NULL,
+  `target_user_fkid` int(11) NOT NULL,
+  `action` varchar(50) NOT NULL,
+  `detail` text NOT NULL,
+  `created_at` bigint(20) NOT NULL
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+--
+-- Indexes for table `user_audit_logs`
+--
+ALTER TABLE `user_audit_logs`
+  ADD PRIMARY KEY (`audit_log_id`),
+  ADD KEY `target_user_fkid` (`target_user_fkid`);
+
+--
+-- AUTO_INCREMENT for table `user_audit_logs`
+--
+ALTER TABLE `user_audit_logs`
+  MODIFY `audit_log_id` int(11) NOT NULL AUTO_INCREMENT;
+COMMIT;
+
+--
+-- Table structure for table `user_identities`
+--
+
+CREATE TABLE `user_identities` (
+  `identity_id` int(11) NOT NULL,
+  `user_fkid` int(11) NOT NULL,
+  `provider` varchar(50) NOT NULL,
+  `subject` varchar(255) NOT NULL,
+  `email` varchar(512) NOT NULL,
+  `created_at` bigint(20) NOT NULL
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+--
+-- Indexes for table `user_identities`
+--
+ALTER TABLE `user_identities`
+  ADD PRIMARY KEY (`identity_id`),
+  ADD UNIQUE KEY `provider_subject` (`provider`,`subject`),
+  ADD KEY `user_fkid` (`user_fkid`);
+
+--
+-- AUTO_INCREMENT for table `user_identities`
+--
+ALTER TABLE `user_identities`
+  MODIFY `identity_id` int(11) NOT NULL AUTO_INCREMENT;
+
+--
+-- Constraints for table `user_identities`
+--
+ALTER TABLE `user_identities`
+  ADD CONSTRAINT `user_identities_ibfk_1` FOREIGN KEY (`user_fkid`) REFERENCES `users` (`user_id`) ON DELETE CASCADE;
+COMMIT;
+
+--
+-- Table structure for table `api_keys`
+--
+
+CREATE TABLE `api_keys` (
+  `api_key_id` int(11) NOT NULL,
+  `user_fkid` int(11) NOT NULL,
+  `name` varchar(100) NOT NULL,
+  `prefix` varchar(16) NOT NULL,
+  `key_hash` char(64) NOT NULL,
+  `scopes` varchar(255) NOT NULL DEFAULT '',
+  `expired_at` bigint(20) DEFAULT NULL,
+  `last_used_at` bigint(20) DEFAULT NULL,
+  `revoked_at` bigint(20) DEFAULT NULL,
+  `created_at` bigint(20) NOT NULL,
+  `updated_at` bigint(20) DEFAULT NULL
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+--
+-- Indexes for table `api_keys`
+--
+ALTER TABLE `api_keys`
+  ADD PRIMARY KEY (`api_key_id`),
+  ADD UNIQUE KEY `prefix` (`prefix`),
+  ADD KEY `user_fkid` (`user_fkid`);
+
+--
+-- AUTO_INCREMENT for table `api_keys`
+--
+ALTER TABLE `api_keys`
+  MODIFY `api_key_id` int(11) NOT NULL AUTO_INCREMENT;
+
+--
+-- Constraints for table `api_keys`
+--
+ALTER TABLE `api_keys`
+  ADD CONSTRAINT `api_keys_ibfk_1` FOREIGN KEY (`user_fkid`) REFERENCES `users` (`user_id`) ON DELETE CASCADE;
+
+--
+-- Table structure for table `refresh_tokens`
+--
+
+CREATE TABLE `refresh_tokens` (
+  `user_fkid` int(11) NOT NULL,
+  `session_id` char(32) NOT NULL,
+  `refresh_token` text NOT NULL,
+  `expired_at` bigint(20) NOT NULL,
+  `created_at` bigint(20) NOT NULL
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+--
+-- Indexes for table `refresh_tokens`
+--
+ALTER TABLE `refresh_tokens`
+  ADD PRIMARY KEY (`user_fkid`,`session_id`),
+  ADD KEY `expired_at` (`expired_at`);
+
+--
+-- Constraints for table `refresh_tokens`
+--
+ALTER TABLE `refresh_tokens`
+  ADD CONSTRAINT `refresh_tokens_ibfk_1` FOREIGN KEY (`user_fkid`) REFERENCES `users` (`user_id`) ON DELETE CASCADE;
+COMMIT;
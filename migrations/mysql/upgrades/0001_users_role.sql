@@ -0,0 +1,4 @@
+-- the role of the users, the existing users are users. an admin is created or promoted with make create-admin
+
+ALTER TABLE `users`
+  ADD `role` varchar(20) NOT NULL DEFAULT 'user' AFTER `name`;
@@ -0,0 +1,16 @@
+-- the disabled users and the passwords an admin reset, and the audit log of the admin actions
+
+ALTER TABLE `users`
+  ADD `disabled_at` bigint(20) DEFAULT NULL AFTER `updated_at`,
+  ADD `password_reset_at` bigint(20) DEFAULT NULL AFTER `disabled_at`;
+
+CREATE TABLE `user_audit_logs` (
+  `audit_log_id` int(11) NOT NULL AUTO_INCREMENT,
+  `actor_user_fkid` int(11) NOT NULL,
+  `target_user_fkid` int(11) NOT NULL,
+  `action` varchar(50) NOT NULL,
+  `detail` text NOT NULL,
+  `created_at` bigint(20) NOT NULL,
+  PRIMARY KEY (`audit_log_id`),
+  KEY `target_user_fkid` (`target_user_fkid`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,14 @@
+-- the oidc identities linked to the users
+
+CREATE TABLE `user_identities` (
+  `identity_id` int(11) NOT NULL AUTO_INCREMENT,
+  `user_fkid` int(11) NOT NULL,
+  `provider` varchar(50) NOT NULL,
+  `subject` varchar(255) NOT NULL,
+  `email` varchar(100) NOT NULL,
+  `created_at` bigint(20) NOT NULL,
+  PRIMARY KEY (`identity_id`),
+  UNIQUE KEY `provider_subject` (`provider`,`subject`),
+  KEY `user_fkid` (`user_fkid`),
+  CONSTRAINT `user_identities_ibfk_1` FOREIGN KEY (`user_fkid`) REFERENCES `users` (`user_id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,19 @@
+-- the api keys of the machine to machine clients
+
+CREATE TABLE `api_keys` (
+  `api_key_id` int(11) NOT NULL AUTO_INCREMENT,
+  `user_fkid` int(11) NOT NULL,
+  `name` varchar(100) NOT NULL,
+  `prefix` varchar(16) NOT NULL,
+  `key_hash` char(64) NOT NULL,
+  `scopes` varchar(255) NOT NULL DEFAULT '',
+  `expired_at` bigint(20) DEFAULT NULL,
+  `last_used_at` bigint(20) DEFAULT NULL,
+  `revoked_at` bigint(20) DEFAULT NULL,
+  `created_at` bigint(20) NOT NULL,
+  `updated_at` bigint(20) DEFAULT NULL,
+  PRIMARY KEY (`api_key_id`),
+  UNIQUE KEY `prefix` (`prefix`),
+  KEY `user_fkid` (`user_fkid`),
+  CONSTRAINT `api_keys_ibfk_1` FOREIGN KEY (`user_fkid`) REFERENCES `users` (`user_id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,12 @@
+-- the refresh tokens of the mysql token store, AUTH.TOKEN_STORE mysql
+
+CREATE TABLE `refresh_tokens` (
+  `user_fkid` int(11) NOT NULL,
+  `session_id` char(32) NOT NULL,
+  `refresh_token` text NOT NULL,
+  `expired_at` bigint(20) NOT NULL,
+  `created_at` bigint(20) NOT NULL,
+  PRIMARY KEY (`user_fkid`,`session_id`),
+  KEY `expired_at` (`expired_at`),
+  CONSTRAINT `refresh_tokens_ibfk_1` FOREIGN KEY (`user_fkid`) REFERENCES `users` (`user_id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
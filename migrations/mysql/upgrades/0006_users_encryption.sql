@@ -0,0 +1,11 @@
+-- the encrypted columns are wider than their plaintext and the email is looked up by its blind index.
+-- the existing rows are still plaintext and without a blind index until make backfill is run
+
+ALTER TABLE `users`
+  MODIFY `email` varchar(512) NOT NULL,
+  MODIFY `name` varchar(512) NOT NULL,
+  ADD `email_bidx` varchar(100) DEFAULT NULL AFTER `email`,
+  ADD KEY `email_bidx` (`email_bidx`);
+
+ALTER TABLE `user_identities`
+  MODIFY `email` varchar(512) NOT NULL;
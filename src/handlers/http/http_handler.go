@@ -2,7 +2,10 @@ package http
 
 import (
 	"golang-starter/internal/protocols/http/middleware"
+	"golang-starter/internal/utils/auth"
+	"golang-starter/internal/utils/ratelimit"
 	productsvc "golang-starter/src/modules/product/services"
+	"golang-starter/src/modules/user/entities"
 	usersvc "golang-starter/src/modules/user/services"
 
 	"github.com/go-chi/chi/v5"
@@ -11,24 +14,103 @@ import (
 type HttpHandlerImpl struct {
 	productsvc.ProductService
 	usersvc.UserService
+	usersvc.UserAdminService
+	usersvc.UserOidcService
+	usersvc.UserApiKeyService
+	usersvc.UserTokenService
+	signer   *auth.Signer
+	verifier auth.TokenVerifier
+	limiter  *ratelimit.Limiter
 }
 
 func NewHttpHandler(
 	productService productsvc.ProductService,
 	userService usersvc.UserService,
+	userAdminService usersvc.UserAdminService,
+	userOidcService usersvc.UserOidcService,
+	userApiKeyService usersvc.UserApiKeyService,
+	userTokenService usersvc.UserTokenService,
+	signer *auth.Signer,
+	verifier auth.TokenVerifier,
+	limiter *ratelimit.Limiter,
 ) *HttpHandlerImpl {
 	return &HttpHandlerImpl{
-		ProductService: productService,
-		UserService:    userService,
+		ProductService:    productService,
+		UserService:       userService,
+		UserAdminService:  userAdminService,
+		UserOidcService:   userOidcService,
+		UserApiKeyService: userApiKeyService,
+		UserTokenService:  userTokenService,
+		signer:            signer,
+		verifier:          verifier,
+		limiter:           limiter,
 	}
 }
 
+// the rate limit is chained after the authentication of each group, so a client is keyed by its user or api key.
+// the authenticated groups are limited by ip before the authentication too, so a rejected token counts
 func (h *HttpHandlerImpl) Router(r *chi.Mux) {
-	r.Get("/products", h.GetProducts)
-	r.Get("/products/{productId}", h.GetProductByID)
-	r.Post("/products", h.CreateNewProduct)
-	r.Delete("/products/{productId}", h.DeleteProductByID)
-	r.Get("/users/{userId}", h.GetUserById)
-	r.Post("/users/login", h.UserLogin)
-	r.With(middleware.JwtVerifyRefreshToken).Post("/users/refresh", h.UserRefreshToken)
+	r.Group(func(r chi.Router) {
+		r.Use(middleware.RateLimit(h.limiter))
+		r.Get("/products", h.GetProducts)
+		r.Get("/products/{productId}", h.GetProductByID)
+		r.Post("/products", h.CreateNewProduct)
+		r.Delete("/products/{productId}", h.DeleteProductByID)
+		r.Post("/users/login", h.UserLogin)
+		r.Get("/.well-known/jwks.json", h.Jwks)
+		r.Get("/auth/oidc/{provider}/login", h.OidcLogin)
+		r.Get("/auth/oidc/{provider}/callback", h.OidcCallback)
+		r.Post("/oauth/revoke", h.RevokeToken)
+	})
+
+	r.With(
+		middleware.RateLimitAuth(h.limiter),
+		middleware.JwtVerifyRefreshToken(h.verifier),
+		middleware.RateLimit(h.limiter),
+	).Post("/users/refresh", h.UserRefreshToken)
+
+	// the routes that machine to machine clients can reach with an api key
+	r.Group(func(r chi.Router) {
+		r.Use(
+			middleware.RateLimitAuth(h.limiter),
+			middleware.JwtOrApiKey(h.verifier, h.UserApiKeyService),
+			middleware.RateLimit(h.limiter),
+		)
+		r.With(middleware.RequireScope(entities.ApiKeyScopeUsersRead)).Get("/users/me", h.GetUserMe)
+		r.With(middleware.RequireScope(entities.ApiKeyScopeUsersWrite)).Patch("/users/me", h.UpdateUserMe)
+		r.With(middleware.RequireScope(entities.ApiKeyScopeUsersRead)).Get("/users/{userId}", h.GetUserById)
+		r.With(middleware.RequireApiKeyScopeOrRole(entities.ApiKeyScopeTokensIntrospect, entities.RoleAdmin)).
+			Post("/oauth/introspect", h.IntrospectToken)
+	})
+
+	r.Group(func(r chi.Router) {
+		r.Use(
+			middleware.RateLimitAuth(h.limiter),
+			middleware.JwtVerifyToken(h.verifier),
+			middleware.RateLimit(h.limiter),
+		)
+		r.Put("/users/me/password", h.ChangeUserMePassword)
+		r.Get("/users/me/api-keys", h.ListUserMeApiKeys)
+		r.Post("/users/me/api-keys", h.CreateUserMeApiKey)
+		r.Post("/users/me/api-keys/{apiKeyId}/rotate", h.RotateUserMeApiKey)
+		r.Delete("/users/me/api-keys/{apiKeyId}", h.RevokeUserMeApiKey)
+	})
+
+	r.Route("/admin", func(r chi.Router) {
+		r.Use(
+			middleware.RateLimitAuth(h.limiter),
+			middleware.JwtOrApiKey(h.verifier, h.UserApiKeyService),
+			middleware.RequireRole(entities.RoleAdmin),
+			middleware.RequireScope(entities.ApiKeyScopeAdmin),
+			middleware.RateLimit(h.limiter),
+		)
+		r.Get("/users", h.ListUsers)
+		r.Post("/users", h.CreateUser)
+		r.Delete("/users/{userId}", h.DeleteUser)
+		r.Post("/users/{userId}/disable", h.DisableUser)
+		r.Post("/users/{userId}/enable", h.EnableUser)
+		r.Post("/users/{userId}/password-reset", h.ResetUserPassword)
+		r.Post("/users/{userId}/unlock", h.UnlockUser)
+		r.Get("/users/{userId}/audit-logs", h.ListUserAuditLogs)
+	})
 }
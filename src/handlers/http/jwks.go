@@ -0,0 +1,19 @@
+package http
+
+import (
+	httpresponse "golang-starter/internal/protocols/http/response"
+	"net/http"
+)
+
+// Jwks publish the public keys that verify the tokens, so other services can verify them without sharing a secret
+// @Summary JSON web key set
+// @Description the public keys of the token signing keyring, including the retired keys that still verify
+// @Tags Auth
+// @Produce json
+// @Success 200 {object} keyring.JWKS
+// @Router /.well-known/jwks.json [GET]
+func (h HttpHandlerImpl) Jwks(w http.ResponseWriter, r *http.Request) {
+	// the verifiers may cache the keys, they should fetch the set again when they see an unknown kid
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	httpresponse.Raw(w, http.StatusOK, h.signer.JWKS())
+}
@@ -0,0 +1,67 @@
+package http
+
+import (
+	"golang-starter/internal/protocols/http/errors"
+	httpresponse "golang-starter/internal/protocols/http/response"
+	"net/http"
+)
+
+// IntrospectToken tell whether a token is still active, so other services don't have to trust a token until its exp
+// @Summary Introspect token
+// @Description RFC 7662 token introspection, an invalid, expired or revoked token is inactive
+// @Tags Auth
+// @Accept x-www-form-urlencoded
+// @Produce json
+// @Param Authorization header string false "access token of an admin"
+// @Param X-API-Key header string false "api key with the tokens:introspect scope, instead of the access token"
+// @Param token formData string true "the token to introspect"
+// @Param token_type_hint formData string false "access_token or refresh_token"
+// @Success 200 {object} dto.TokenIntrospectRespBody
+// @Failure 400 {object} response.Problem
+// @Failure 401 {object} response.Problem
+// @Failure 403 {object} response.Problem
+// @Failure 500 {object} response.Problem
+// @Router /oauth/introspect [POST]
+func (h HttpHandlerImpl) IntrospectToken(w http.ResponseWriter, r *http.Request) {
+	token := r.PostFormValue("token")
+	if token == "" {
+		httpresponse.Err(w, r, errors.BadRequest("token is required"))
+		return
+	}
+
+	res, err := h.UserTokenService.IntrospectToken(r.Context(), token, r.PostFormValue("token_type_hint"))
+	if err != nil {
+		httpresponse.Err(w, r, err)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	httpresponse.Raw(w, http.StatusOK, res)
+}
+
+// RevokeToken revoke a token before it expires, revoking the refresh token is the logout of its session
+// @Summary Revoke token
+// @Description RFC 7009 token revocation, it responds 200 for an invalid token as well since it can't be used anyway
+// @Tags Auth
+// @Accept x-www-form-urlencoded
+// @Param token formData string true "the token to revoke"
+// @Param token_type_hint formData string false "access_token or refresh_token"
+// @Success 200
+// @Failure 400 {object} response.Problem
+// @Failure 500 {object} response.Problem
+// @Router /oauth/revoke [POST]
+func (h HttpHandlerImpl) RevokeToken(w http.ResponseWriter, r *http.Request) {
+	token := r.PostFormValue("token")
+	if token == "" {
+		httpresponse.Err(w, r, errors.BadRequest("token is required"))
+		return
+	}
+
+	err := h.UserTokenService.RevokeToken(r.Context(), token, r.PostFormValue("token_type_hint"))
+	if err != nil {
+		httpresponse.Err(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
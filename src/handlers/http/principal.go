@@ -0,0 +1,16 @@
+package http
+
+import (
+	"golang-starter/internal/protocols/http/errors"
+	"golang-starter/internal/utils/auth"
+	"net/http"
+)
+
+// principalOf return the caller of the request, it's set by the auth middleware of the route
+func principalOf(r *http.Request) (*auth.Principal, error) {
+	principal, ok := auth.PrincipalFromContext(r.Context())
+	if !ok {
+		return nil, errors.ErrTokenInvalid
+	}
+	return principal, nil
+}
@@ -1,14 +1,14 @@
 package http
 
 import (
-	"encoding/json"
+	"golang-starter/internal/protocols/http/errors"
+	httprequest "golang-starter/internal/protocols/http/request"
 	httpresponse "golang-starter/internal/protocols/http/response"
 	"golang-starter/src/modules/product/dto"
 	"net/http"
 	"strconv"
 
 	"github.com/go-chi/chi/v5"
-	"github.com/rs/zerolog/log"
 )
 
 // GetProducts return Products list
@@ -16,15 +16,15 @@ import (
 // @Description get all products
 // @Tags Products
 // @Success 200 {object} response.Response
-// @Failure 404 {object} response.Response
-// @Failure 401 {object} response.Response
-// @Failure 500 {object} response.Response
+// @Failure 404 {object} response.Problem
+// @Failure 401 {object} response.Problem
+// @Failure 500 {object} response.Problem
 // @Router /products [GET]
 func (h HttpHandlerImpl) GetProducts(w http.ResponseWriter, r *http.Request) {
 	// get all products
 	products, err := h.ProductService.GetProducts(r.Context())
 	if err != nil {
-		httpresponse.Err(w, err)
+		httpresponse.Err(w, r, err)
 		return
 	}
 
@@ -37,21 +37,21 @@ func (h HttpHandlerImpl) GetProducts(w http.ResponseWriter, r *http.Request) {
 // @Tags Products
 // @Param productId path string true "productId"
 // @Success 200 {object} response.Response
-// @Failure 404 {object} response.Response
-// @Failure 401 {object} response.Response
-// @Failure 500 {object} response.Response
+// @Failure 404 {object} response.Problem
+// @Failure 401 {object} response.Problem
+// @Failure 500 {object} response.Problem
 // @Router /products/{productId} [GET]
 func (h HttpHandlerImpl) GetProductByID(w http.ResponseWriter, r *http.Request) {
 	rawProductId := chi.URLParam(r, "productId")
 	productId, err := strconv.Atoi(rawProductId)
 	if err != nil {
-		httpresponse.Err(w, err)
+		httpresponse.Err(w, r, errors.BadRequest("productId must be a number"))
 		return
 	}
 
 	product, err := h.ProductService.GetProductByProductID(r.Context(), productId)
 	if err != nil {
-		httpresponse.Err(w, err)
+		httpresponse.Err(w, r, err)
 		return
 	}
 
@@ -62,14 +62,13 @@ func (h HttpHandlerImpl) DeleteProductByID(w http.ResponseWriter, r *http.Reques
 	rawProductId := chi.URLParam(r, "productId")
 	productId, err := strconv.Atoi(rawProductId)
 	if err != nil {
-		log.Err(err)
-		httpresponse.Err(w, err)
+		httpresponse.Err(w, r, errors.BadRequest("productId must be a number"))
 		return
 	}
 
 	err = h.ProductService.DeleteProduct(r.Context(), productId)
 	if err != nil {
-		httpresponse.Err(w, err)
+		httpresponse.Err(w, r, err)
 		return
 	}
 
@@ -78,17 +77,14 @@ func (h HttpHandlerImpl) DeleteProductByID(w http.ResponseWriter, r *http.Reques
 
 func (h HttpHandlerImpl) CreateNewProduct(w http.ResponseWriter, r *http.Request) {
 	product := dto.ProductRequestBody{}
-	err := json.NewDecoder(r.Body).Decode(&product)
-	if err != nil {
-		log.Err(err)
-		httpresponse.Err(w, err)
+	if err := httprequest.DecodeJSON(r, &product); err != nil {
+		httpresponse.Err(w, r, err)
 		return
 	}
 
 	productNew, err := h.ProductService.CreateNewProduct(r.Context(), product)
 	if err != nil {
-		log.Err(err)
-		httpresponse.Err(w, err)
+		httpresponse.Err(w, r, err)
 		return
 	}
 
@@ -1,39 +1,54 @@
 package http
 
 import (
-	"encoding/json"
+	"golang-starter/internal/protocols/http/errors"
+	httprequest "golang-starter/internal/protocols/http/request"
 	httpresponse "golang-starter/internal/protocols/http/response"
+	"golang-starter/internal/utils/auth"
 	"golang-starter/src/modules/user/dto"
+	"golang-starter/src/modules/user/entities"
 	"net/http"
 	"strconv"
 
 	"github.com/go-chi/chi/v5"
-	"github.com/rs/zerolog/log"
 )
 
 // GetUserById return User by userId
 // @Summary Get User by userId
-// @Description get User by userId
+// @Description get User by userId, only admin or the user itself can access it
 // @Tags Users
+// @Param Authorization header string false "access token"
+// @Param X-API-Key header string false "api key, instead of the access token"
 // @Param userId path string true "userId"
 // @Success 200 {object} response.Response
-// @Failure 404 {object} response.Response
-// @Failure 401 {object} response.Response
-// @Failure 500 {object} response.Response
+// @Failure 400 {object} response.Problem
+// @Failure 404 {object} response.Problem
+// @Failure 401 {object} response.Problem
+// @Failure 403 {object} response.Problem
+// @Failure 500 {object} response.Problem
 // @Router /users/{userId} [GET]
 func (h HttpHandlerImpl) GetUserById(w http.ResponseWriter, r *http.Request) {
 	rawUserId := chi.URLParam(r, "userId")
 	userId, err := strconv.Atoi(rawUserId)
 	if err != nil {
-		log.Err(err)
-		httpresponse.Err(w, err)
+		httpresponse.Err(w, r, errors.BadRequest("userId must be a number"))
+		return
+	}
+
+	principal, err := principalOf(r)
+	if err != nil {
+		httpresponse.Err(w, r, err)
+		return
+	}
+
+	if principal.UserId != uint(userId) && !principal.HasRole(entities.RoleAdmin) {
+		httpresponse.Err(w, r, errors.Forbidden("you can only access your own user"))
 		return
 	}
 
 	user, err := h.UserService.FindByID(r.Context(), uint(userId))
 	if err != nil {
-		log.Err(err)
-		httpresponse.Err(w, err)
+		httpresponse.Err(w, r, err)
 		return
 	}
 
@@ -46,23 +61,24 @@ func (h HttpHandlerImpl) GetUserById(w http.ResponseWriter, r *http.Request) {
 // @Tags Users
 // @Param User Form body dto.UserRequestLoginBody true "user form"
 // @Success 200 {object} response.Response
-// @Failure 404 {object} response.Response
-// @Failure 401 {object} response.Response
-// @Failure 500 {object} response.Response
+// @Failure 404 {object} response.Problem
+// @Failure 401 {object} response.Problem
+// @Failure 429 {object} response.Problem
+// @Failure 422 {object} response.Problem
+// @Failure 500 {object} response.Problem
 // @Router /users/login [POST]
 func (h HttpHandlerImpl) UserLogin(w http.ResponseWriter, r *http.Request) {
 	// userData := new(dto.UserRequestLoginBody)
 	userReq := dto.UserRequestLoginBody{}
-	if err := json.NewDecoder(r.Body).Decode(&userReq); err != nil {
-		log.Err(err)
-		httpresponse.Err(w, err)
+	if err := httprequest.DecodeJSON(r, &userReq); err != nil {
+		httpresponse.Err(w, r, err)
 		return
 	}
+	userReq.IP = httprequest.ClientIP(r)
 
 	res, err := h.UserService.UserLogin(r.Context(), userReq)
 	if err != nil {
-		log.Err(err)
-		httpresponse.Err(w, err)
+		httpresponse.Err(w, r, err)
 		return
 	}
 
@@ -75,19 +91,125 @@ func (h HttpHandlerImpl) UserLogin(w http.ResponseWriter, r *http.Request) {
 // @Tags Users
 // @Param Authorization header string true "refresh token"
 // @Success 200 {object} response.Response
-// @Failure 404 {object} response.Response
-// @Failure 401 {object} response.Response
-// @Failure 500 {object} response.Response
+// @Failure 404 {object} response.Problem
+// @Failure 401 {object} response.Problem
+// @Failure 500 {object} response.Problem
 // @Router /users/refresh [POST]
 func (h HttpHandlerImpl) UserRefreshToken(w http.ResponseWriter, r *http.Request) {
-	userId := r.Header.Get("id")
+	principal, err := principalOf(r)
+	if err != nil {
+		httpresponse.Err(w, r, err)
+		return
+	}
 
-	res, err := h.UserService.UserRefreshToken(r.Context(), userId)
+	claims, ok := auth.ClaimsFromContext(r.Context())
+	if !ok {
+		httpresponse.Err(w, r, errors.ErrTokenInvalid)
+		return
+	}
+
+	res, err := h.UserService.UserRefreshToken(r.Context(), principal.UserId, principal.SessionId, claims.ID)
 	if err != nil {
-		log.Err(err)
-		httpresponse.Err(w, err)
+		httpresponse.Err(w, r, err)
 		return
 	}
 
 	httpresponse.Json(w, http.StatusOK, "", res)
 }
+
+// GetUserMe return the user of the access token
+// @Summary Get current user
+// @Description get the user of the access token
+// @Tags Users
+// @Param Authorization header string false "access token"
+// @Param X-API-Key header string false "api key, instead of the access token"
+// @Success 200 {object} response.Response
+// @Failure 401 {object} response.Problem
+// @Failure 404 {object} response.Problem
+// @Failure 500 {object} response.Problem
+// @Router /users/me [GET]
+func (h HttpHandlerImpl) GetUserMe(w http.ResponseWriter, r *http.Request) {
+	principal, err := principalOf(r)
+	if err != nil {
+		httpresponse.Err(w, r, err)
+		return
+	}
+
+	user, err := h.UserService.FindByID(r.Context(), principal.UserId)
+	if err != nil {
+		httpresponse.Err(w, r, err)
+		return
+	}
+
+	httpresponse.Json(w, http.StatusOK, "", user)
+}
+
+// UpdateUserMe update the profile of the user of the access token
+// @Summary Update current user
+// @Description update name, username or photo of the user of the access token
+// @Tags Users
+// @Param Authorization header string false "access token"
+// @Param X-API-Key header string false "api key, instead of the access token"
+// @Param User Form body dto.UserRequestUpdateBody true "user form"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Problem
+// @Failure 401 {object} response.Problem
+// @Failure 409 {object} response.Problem
+// @Failure 422 {object} response.Problem
+// @Failure 500 {object} response.Problem
+// @Router /users/me [PATCH]
+func (h HttpHandlerImpl) UpdateUserMe(w http.ResponseWriter, r *http.Request) {
+	principal, err := principalOf(r)
+	if err != nil {
+		httpresponse.Err(w, r, err)
+		return
+	}
+
+	userReq := dto.UserRequestUpdateBody{}
+	if err := httprequest.DecodeJSON(r, &userReq); err != nil {
+		httpresponse.Err(w, r, err)
+		return
+	}
+
+	user, err := h.UserService.UpdateProfile(r.Context(), principal.UserId, userReq)
+	if err != nil {
+		httpresponse.Err(w, r, err)
+		return
+	}
+
+	httpresponse.Json(w, http.StatusOK, "success update user", user)
+}
+
+// ChangeUserMePassword change the password of the user of the access token
+// @Summary Change current user password
+// @Description change the password and revoke the other sessions of the user
+// @Tags Users
+// @Param Authorization header string true "access token"
+// @Param Password Form body dto.UserRequestChangePasswordBody true "password form"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Problem
+// @Failure 401 {object} response.Problem
+// @Failure 422 {object} response.Problem
+// @Failure 500 {object} response.Problem
+// @Router /users/me/password [PUT]
+func (h HttpHandlerImpl) ChangeUserMePassword(w http.ResponseWriter, r *http.Request) {
+	principal, err := principalOf(r)
+	if err != nil {
+		httpresponse.Err(w, r, err)
+		return
+	}
+
+	passwordReq := dto.UserRequestChangePasswordBody{}
+	if err := httprequest.DecodeJSON(r, &passwordReq); err != nil {
+		httpresponse.Err(w, r, err)
+		return
+	}
+
+	err = h.UserService.ChangePassword(r.Context(), principal.UserId, principal.SessionId, passwordReq)
+	if err != nil {
+		httpresponse.Err(w, r, err)
+		return
+	}
+
+	httpresponse.Json(w, http.StatusOK, "success change password", nil)
+}
@@ -0,0 +1,289 @@
+package http
+
+import (
+	"golang-starter/internal/protocols/http/errors"
+	httprequest "golang-starter/internal/protocols/http/request"
+	httpresponse "golang-starter/internal/protocols/http/response"
+	"golang-starter/internal/utils/auth"
+	"golang-starter/src/modules/user/dto"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// ListUsers return the users list for admin
+// @Summary List users
+// @Description list and search users, admin only
+// @Tags Admin
+// @Param Authorization header string true "access token"
+// @Param email query string false "the exact email"
+// @Param username query string false "part of the username"
+// @Param created_from query int false "created_at from, unix millisecond"
+// @Param created_to query int false "created_at to, unix millisecond"
+// @Param disabled query bool false "disabled status"
+// @Param page query int false "page, default 1"
+// @Param size query int false "size, default 20 max 100"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Problem
+// @Failure 401 {object} response.Problem
+// @Failure 403 {object} response.Problem
+// @Failure 500 {object} response.Problem
+// @Router /admin/users [GET]
+func (h HttpHandlerImpl) ListUsers(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	params := dto.UserRequestListParams{
+		Email:    query.Get("email"),
+		Username: query.Get("username"),
+	}
+
+	var err error
+	if v := query.Get("created_from"); v != "" {
+		if params.CreatedFrom, err = strconv.ParseInt(v, 10, 64); err != nil {
+			httpresponse.Err(w, r, errors.BadRequest("created_from must be a number"))
+			return
+		}
+	}
+	if v := query.Get("created_to"); v != "" {
+		if params.CreatedTo, err = strconv.ParseInt(v, 10, 64); err != nil {
+			httpresponse.Err(w, r, errors.BadRequest("created_to must be a number"))
+			return
+		}
+	}
+	if v := query.Get("disabled"); v != "" {
+		disabled, err := strconv.ParseBool(v)
+		if err != nil {
+			httpresponse.Err(w, r, errors.BadRequest("disabled must be true or false"))
+			return
+		}
+		params.Disabled = &disabled
+	}
+	if v := query.Get("page"); v != "" {
+		if params.Page, err = strconv.Atoi(v); err != nil {
+			httpresponse.Err(w, r, errors.BadRequest("page must be a number"))
+			return
+		}
+	}
+	if v := query.Get("size"); v != "" {
+		if params.Size, err = strconv.Atoi(v); err != nil {
+			httpresponse.Err(w, r, errors.BadRequest("size must be a number"))
+			return
+		}
+	}
+
+	users, err := h.UserAdminService.ListUsers(r.Context(), params)
+	if err != nil {
+		httpresponse.Err(w, r, err)
+		return
+	}
+
+	httpresponse.Json(w, http.StatusOK, "", users)
+}
+
+// CreateUser create a new user by admin
+// @Summary Create user
+// @Description create a new user, admin only
+// @Tags Admin
+// @Param Authorization header string true "access token"
+// @Param User Form body dto.UserRequestCreateBody true "user form"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Problem
+// @Failure 401 {object} response.Problem
+// @Failure 403 {object} response.Problem
+// @Failure 409 {object} response.Problem
+// @Failure 422 {object} response.Problem
+// @Failure 500 {object} response.Problem
+// @Router /admin/users [POST]
+func (h HttpHandlerImpl) CreateUser(w http.ResponseWriter, r *http.Request) {
+	userReq := dto.UserRequestCreateBody{}
+	if err := httprequest.DecodeJSON(r, &userReq); err != nil {
+		httpresponse.Err(w, r, err)
+		return
+	}
+
+	user, err := h.UserAdminService.CreateUser(r.Context(), adminId(r), userReq)
+	if err != nil {
+		httpresponse.Err(w, r, err)
+		return
+	}
+
+	httpresponse.Json(w, http.StatusOK, "success create user", user)
+}
+
+// DisableUser block the user from login
+// @Summary Disable user
+// @Description disable the user and revoke its sessions, admin only
+// @Tags Admin
+// @Param Authorization header string true "access token"
+// @Param userId path string true "userId"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Problem
+// @Failure 401 {object} response.Problem
+// @Failure 403 {object} response.Problem
+// @Failure 404 {object} response.Problem
+// @Failure 500 {object} response.Problem
+// @Router /admin/users/{userId}/disable [POST]
+func (h HttpHandlerImpl) DisableUser(w http.ResponseWriter, r *http.Request) {
+	userId, err := strconv.Atoi(chi.URLParam(r, "userId"))
+	if err != nil {
+		httpresponse.Err(w, r, errors.BadRequest("userId must be a number"))
+		return
+	}
+
+	err = h.UserAdminService.DisableUser(r.Context(), adminId(r), uint(userId))
+	if err != nil {
+		httpresponse.Err(w, r, err)
+		return
+	}
+
+	httpresponse.Json(w, http.StatusOK, "success disable user", nil)
+}
+
+// EnableUser let a disabled user login again
+// @Summary Enable user
+// @Description enable a disabled user, admin only
+// @Tags Admin
+// @Param Authorization header string true "access token"
+// @Param userId path string true "userId"
+// @Success 200 {object} response.Response
+// @Failure 401 {object} response.Problem
+// @Failure 403 {object} response.Problem
+// @Failure 404 {object} response.Problem
+// @Failure 500 {object} response.Problem
+// @Router /admin/users/{userId}/enable [POST]
+func (h HttpHandlerImpl) EnableUser(w http.ResponseWriter, r *http.Request) {
+	userId, err := strconv.Atoi(chi.URLParam(r, "userId"))
+	if err != nil {
+		httpresponse.Err(w, r, errors.BadRequest("userId must be a number"))
+		return
+	}
+
+	err = h.UserAdminService.EnableUser(r.Context(), adminId(r), uint(userId))
+	if err != nil {
+		httpresponse.Err(w, r, err)
+		return
+	}
+
+	httpresponse.Json(w, http.StatusOK, "success enable user", nil)
+}
+
+// ResetUserPassword force the user to reset its password
+// @Summary Force password reset
+// @Description replace the password with a temporary one that is shown once and revoke the user sessions, admin only
+// @Tags Admin
+// @Param Authorization header string true "access token"
+// @Param userId path string true "userId"
+// @Success 200 {object} response.Response
+// @Failure 401 {object} response.Problem
+// @Failure 403 {object} response.Problem
+// @Failure 404 {object} response.Problem
+// @Failure 500 {object} response.Problem
+// @Router /admin/users/{userId}/password-reset [POST]
+func (h HttpHandlerImpl) ResetUserPassword(w http.ResponseWriter, r *http.Request) {
+	userId, err := strconv.Atoi(chi.URLParam(r, "userId"))
+	if err != nil {
+		httpresponse.Err(w, r, errors.BadRequest("userId must be a number"))
+		return
+	}
+
+	res, err := h.UserAdminService.ResetUserPassword(r.Context(), adminId(r), uint(userId))
+	if err != nil {
+		httpresponse.Err(w, r, err)
+		return
+	}
+
+	httpresponse.Json(w, http.StatusOK, "success reset user password", res)
+}
+
+// DeleteUser delete the user
+// @Summary Delete user
+// @Description delete the user and revoke its sessions, admin only
+// @Tags Admin
+// @Param Authorization header string true "access token"
+// @Param userId path string true "userId"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Problem
+// @Failure 401 {object} response.Problem
+// @Failure 403 {object} response.Problem
+// @Failure 404 {object} response.Problem
+// @Failure 500 {object} response.Problem
+// @Router /admin/users/{userId} [DELETE]
+func (h HttpHandlerImpl) DeleteUser(w http.ResponseWriter, r *http.Request) {
+	userId, err := strconv.Atoi(chi.URLParam(r, "userId"))
+	if err != nil {
+		httpresponse.Err(w, r, errors.BadRequest("userId must be a number"))
+		return
+	}
+
+	err = h.UserAdminService.DeleteUser(r.Context(), adminId(r), uint(userId))
+	if err != nil {
+		httpresponse.Err(w, r, err)
+		return
+	}
+
+	httpresponse.Json(w, http.StatusOK, "success delete user", nil)
+}
+
+// UnlockUser remove the brute-force lock of user
+// @Summary remove the brute-force lock of user
+// @Description remove the failed login counter and the lock of user, admin only
+// @Tags Admin
+// @Param Authorization header string true "access token"
+// @Param userId path string true "userId"
+// @Success 200 {object} response.Response
+// @Failure 401 {object} response.Problem
+// @Failure 403 {object} response.Problem
+// @Failure 404 {object} response.Problem
+// @Failure 500 {object} response.Problem
+// @Router /admin/users/{userId}/unlock [POST]
+func (h HttpHandlerImpl) UnlockUser(w http.ResponseWriter, r *http.Request) {
+	userId, err := strconv.Atoi(chi.URLParam(r, "userId"))
+	if err != nil {
+		httpresponse.Err(w, r, errors.BadRequest("userId must be a number"))
+		return
+	}
+
+	err = h.UserAdminService.UnlockUser(r.Context(), adminId(r), uint(userId))
+	if err != nil {
+		httpresponse.Err(w, r, err)
+		return
+	}
+
+	httpresponse.Json(w, http.StatusOK, "success to unlock user", nil)
+}
+
+// ListUserAuditLogs return the admin actions that was done to the user
+// @Summary List user audit logs
+// @Description list the admin actions that was done to the user, admin only
+// @Tags Admin
+// @Param Authorization header string true "access token"
+// @Param userId path string true "userId"
+// @Success 200 {object} response.Response
+// @Failure 401 {object} response.Problem
+// @Failure 403 {object} response.Problem
+// @Failure 500 {object} response.Problem
+// @Router /admin/users/{userId}/audit-logs [GET]
+func (h HttpHandlerImpl) ListUserAuditLogs(w http.ResponseWriter, r *http.Request) {
+	userId, err := strconv.Atoi(chi.URLParam(r, "userId"))
+	if err != nil {
+		httpresponse.Err(w, r, errors.BadRequest("userId must be a number"))
+		return
+	}
+
+	auditLogs, err := h.UserAdminService.ListUserAuditLogs(r.Context(), uint(userId))
+	if err != nil {
+		httpresponse.Err(w, r, err)
+		return
+	}
+
+	httpresponse.Json(w, http.StatusOK, "", auditLogs)
+}
+
+// adminId return the id of the admin that call the request, the admin routes always have a principal
+func adminId(r *http.Request) uint {
+	principal, ok := auth.PrincipalFromContext(r.Context())
+	if !ok {
+		return 0
+	}
+	return principal.UserId
+}
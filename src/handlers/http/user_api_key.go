@@ -0,0 +1,142 @@
+package http
+
+import (
+	"golang-starter/internal/protocols/http/errors"
+	httprequest "golang-starter/internal/protocols/http/request"
+	httpresponse "golang-starter/internal/protocols/http/response"
+	"golang-starter/src/modules/user/dto"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// ListUserMeApiKeys return the api keys of the user of the access token
+// @Summary List api keys
+// @Description list the api keys of the current user, the secrets are never returned
+// @Tags Users
+// @Param Authorization header string true "access token"
+// @Success 200 {object} response.Response
+// @Failure 401 {object} response.Problem
+// @Failure 500 {object} response.Problem
+// @Router /users/me/api-keys [GET]
+func (h HttpHandlerImpl) ListUserMeApiKeys(w http.ResponseWriter, r *http.Request) {
+	principal, err := principalOf(r)
+	if err != nil {
+		httpresponse.Err(w, r, err)
+		return
+	}
+
+	apiKeys, err := h.UserApiKeyService.ListApiKeys(r.Context(), principal.UserId)
+	if err != nil {
+		httpresponse.Err(w, r, err)
+		return
+	}
+
+	httpresponse.Json(w, http.StatusOK, "", apiKeys)
+}
+
+// CreateUserMeApiKey create an api key for the user of the access token
+// @Summary Create api key
+// @Description create an api key, the key is only shown in this response
+// @Tags Users
+// @Param Authorization header string true "access token"
+// @Param ApiKey Form body dto.ApiKeyRequestCreateBody true "api key form"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Problem
+// @Failure 401 {object} response.Problem
+// @Failure 403 {object} response.Problem
+// @Failure 409 {object} response.Problem
+// @Failure 422 {object} response.Problem
+// @Failure 500 {object} response.Problem
+// @Router /users/me/api-keys [POST]
+func (h HttpHandlerImpl) CreateUserMeApiKey(w http.ResponseWriter, r *http.Request) {
+	principal, err := principalOf(r)
+	if err != nil {
+		httpresponse.Err(w, r, err)
+		return
+	}
+
+	apiKeyReq := dto.ApiKeyRequestCreateBody{}
+	if err := httprequest.DecodeJSON(r, &apiKeyReq); err != nil {
+		httpresponse.Err(w, r, err)
+		return
+	}
+
+	apiKey, err := h.UserApiKeyService.CreateApiKey(r.Context(), principal.UserId, apiKeyReq)
+	if err != nil {
+		httpresponse.Err(w, r, err)
+		return
+	}
+
+	httpresponse.Json(w, http.StatusOK, "success create api key", apiKey)
+}
+
+// RotateUserMeApiKey replace the secret of an api key
+// @Summary Rotate api key
+// @Description generate a new secret for the api key, the old one stops working immediately
+// @Tags Users
+// @Param Authorization header string true "access token"
+// @Param apiKeyId path string true "apiKeyId"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Problem
+// @Failure 401 {object} response.Problem
+// @Failure 404 {object} response.Problem
+// @Failure 409 {object} response.Problem
+// @Failure 500 {object} response.Problem
+// @Router /users/me/api-keys/{apiKeyId}/rotate [POST]
+func (h HttpHandlerImpl) RotateUserMeApiKey(w http.ResponseWriter, r *http.Request) {
+	principal, err := principalOf(r)
+	if err != nil {
+		httpresponse.Err(w, r, err)
+		return
+	}
+
+	apiKeyId, err := strconv.Atoi(chi.URLParam(r, "apiKeyId"))
+	if err != nil {
+		httpresponse.Err(w, r, errors.BadRequest("apiKeyId must be a number"))
+		return
+	}
+
+	apiKey, err := h.UserApiKeyService.RotateApiKey(r.Context(), principal.UserId, uint(apiKeyId))
+	if err != nil {
+		httpresponse.Err(w, r, err)
+		return
+	}
+
+	httpresponse.Json(w, http.StatusOK, "success rotate api key", apiKey)
+}
+
+// RevokeUserMeApiKey revoke an api key
+// @Summary Revoke api key
+// @Description revoke the api key, it can't be used anymore
+// @Tags Users
+// @Param Authorization header string true "access token"
+// @Param apiKeyId path string true "apiKeyId"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Problem
+// @Failure 401 {object} response.Problem
+// @Failure 404 {object} response.Problem
+// @Failure 500 {object} response.Problem
+// @Router /users/me/api-keys/{apiKeyId} [DELETE]
+func (h HttpHandlerImpl) RevokeUserMeApiKey(w http.ResponseWriter, r *http.Request) {
+	principal, err := principalOf(r)
+	if err != nil {
+		httpresponse.Err(w, r, err)
+		return
+	}
+
+	apiKeyId, err := strconv.Atoi(chi.URLParam(r, "apiKeyId"))
+	if err != nil {
+		httpresponse.Err(w, r, errors.BadRequest("apiKeyId must be a number"))
+		return
+	}
+
+	err = h.UserApiKeyService.RevokeApiKey(r.Context(), principal.UserId, uint(apiKeyId))
+	if err != nil {
+		httpresponse.Err(w, r, err)
+		return
+	}
+
+	httpresponse.Json(w, http.StatusOK, "success revoke api key", nil)
+}
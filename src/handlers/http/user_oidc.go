@@ -0,0 +1,92 @@
+package http
+
+import (
+	httpresponse "golang-starter/internal/protocols/http/response"
+	usersvc "golang-starter/src/modules/user/services"
+	"net/http"
+	"path"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// the cookie that bind the state of a sign in to the browser that started it
+const oidcStateCookie = "oidc_state"
+
+// OidcLogin redirect the user to the openid connect provider
+// @Summary Sign in with an openid connect provider
+// @Description redirect to the provider authorization page
+// @Tags Users
+// @Param provider path string true "provider name"
+// @Success 302
+// @Failure 404 {object} response.Problem
+// @Failure 500 {object} response.Problem
+// @Router /auth/oidc/{provider}/login [GET]
+func (h HttpHandlerImpl) OidcLogin(w http.ResponseWriter, r *http.Request) {
+	oidcAuth, err := h.UserOidcService.OidcAuthURL(r.Context(), chi.URLParam(r, "provider"))
+	if err != nil {
+		httpresponse.Err(w, r, err)
+		return
+	}
+
+	// the provider redirect to the callback with a top level GET, so a Lax cookie is sent back with it
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcStateCookie,
+		Value:    oidcAuth.StateHash,
+		Path:     path.Dir(r.URL.Path),
+		Expires:  time.Unix(oidcAuth.Expired, 0),
+		MaxAge:   int(time.Until(time.Unix(oidcAuth.Expired, 0)).Seconds()),
+		Secure:   isHttps(r),
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+	http.Redirect(w, r, oidcAuth.AuthURL, http.StatusFound)
+}
+
+// OidcCallback handle the redirect back from the openid connect provider
+// @Summary Openid connect callback
+// @Description exchange the authorization code and return the token of the linked user
+// @Tags Users
+// @Param provider path string true "provider name"
+// @Param state query string true "state"
+// @Param code query string true "authorization code"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Problem
+// @Failure 401 {object} response.Problem
+// @Failure 403 {object} response.Problem
+// @Failure 500 {object} response.Problem
+// @Router /auth/oidc/{provider}/callback [GET]
+func (h HttpHandlerImpl) OidcCallback(w http.ResponseWriter, r *http.Request) {
+	var stateHash string
+	if cookie, err := r.Cookie(oidcStateCookie); err == nil {
+		stateHash = cookie.Value
+	}
+	// the state can only be used once, the cookie is removed whatever the result is
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcStateCookie,
+		Path:     path.Dir(r.URL.Path),
+		MaxAge:   -1,
+		Secure:   isHttps(r),
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	query := r.URL.Query()
+	if providerErr := query.Get("error"); providerErr != "" {
+		httpresponse.Err(w, r, usersvc.ErrOidcSignInFailed.WithMessage("sign in is rejected by the provider: "+providerErr))
+		return
+	}
+
+	res, err := h.UserOidcService.OidcCallback(r.Context(), chi.URLParam(r, "provider"), query.Get("state"), stateHash, query.Get("code"))
+	if err != nil {
+		httpresponse.Err(w, r, err)
+		return
+	}
+
+	httpresponse.Json(w, http.StatusOK, "", res)
+}
+
+// isHttps tell whether the client reached the server with https, directly or through a proxy that terminate tls
+func isHttps(r *http.Request) bool {
+	return r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https"
+}
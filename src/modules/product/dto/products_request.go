@@ -7,11 +7,11 @@ import (
 )
 
 type ProductRequestBody struct {
-	Name        string   `json:"name"`
-	Description string   `json:"description"`
-	Price       int      `json:"price"`
-	Qty         int      `json:"qty"`
-	Images      []string `json:"images"`
+	Name        string   `json:"name" validate:"required,max=255"`
+	Description string   `json:"description" validate:"max=5000"`
+	Price       int      `json:"price" validate:"min=0"`
+	Qty         int      `json:"qty" validate:"min=0"`
+	Images      []string `json:"images" validate:"max=10,dive,required,url"`
 }
 
 func (product ProductRequestBody) ToProductEntities() *entities.Products {
@@ -0,0 +1,105 @@
+package repositories
+
+import (
+	"context"
+	productsmodel "golang-starter/src/modules/product/entities"
+)
+
+// the generated queries return an error of their own when the row of Get isn't found, these wrap the ones
+// without another wrapper so every Get return ErrNotFound
+
+// RepositoryProductsNotFoundQueryImpl return ErrNotFound from GetProducts when no product matches
+type RepositoryProductsNotFoundQueryImpl struct {
+	query RepositoryProductsQuery
+}
+
+func NewRepoProductsNotFoundQuery(query RepositoryProductsQuery) *RepositoryProductsNotFoundQueryImpl {
+	return &RepositoryProductsNotFoundQueryImpl{query: query}
+}
+
+func (repo *RepositoryProductsNotFoundQueryImpl) SelectProducts(fields ...ProductsField) RepositoryProductsQuery {
+	return NewRepoProductsNotFoundQuery(repo.query.SelectProducts(fields...))
+}
+
+func (repo *RepositoryProductsNotFoundQueryImpl) ExcludeProducts(excludedFields ...ProductsField) RepositoryProductsQuery {
+	return NewRepoProductsNotFoundQuery(repo.query.ExcludeProducts(excludedFields...))
+}
+
+func (repo *RepositoryProductsNotFoundQueryImpl) FilterProducts(filter Filter) RepositoryProductsQuery {
+	return NewRepoProductsNotFoundQuery(repo.query.FilterProducts(filter))
+}
+
+func (repo *RepositoryProductsNotFoundQueryImpl) PaginationProducts(pagination Pagination) RepositoryProductsQuery {
+	return NewRepoProductsNotFoundQuery(repo.query.PaginationProducts(pagination))
+}
+
+func (repo *RepositoryProductsNotFoundQueryImpl) OrderByProducts(orderBy []Order) RepositoryProductsQuery {
+	return NewRepoProductsNotFoundQuery(repo.query.OrderByProducts(orderBy))
+}
+
+func (repo *RepositoryProductsNotFoundQueryImpl) GetProductsCount(ctx context.Context) (int, error) {
+	return repo.query.GetProductsCount(ctx)
+}
+
+func (repo *RepositoryProductsNotFoundQueryImpl) GetProducts(ctx context.Context) (*productsmodel.Products, error) {
+	list, err := repo.query.GetProductsList(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(list) == 0 {
+		return nil, ErrNotFound
+	}
+	return list[0], nil
+}
+
+func (repo *RepositoryProductsNotFoundQueryImpl) GetProductsList(ctx context.Context) (productsmodel.ProductsList, error) {
+	return repo.query.GetProductsList(ctx)
+}
+
+// RepositoryProductsImagesNotFoundQueryImpl return ErrNotFound from GetProductsImages when no product image matches
+type RepositoryProductsImagesNotFoundQueryImpl struct {
+	query RepositoryProductsImagesQuery
+}
+
+func NewRepoProductsImagesNotFoundQuery(query RepositoryProductsImagesQuery) *RepositoryProductsImagesNotFoundQueryImpl {
+	return &RepositoryProductsImagesNotFoundQueryImpl{query: query}
+}
+
+func (repo *RepositoryProductsImagesNotFoundQueryImpl) SelectProductsImages(fields ...ProductsImagesField) RepositoryProductsImagesQuery {
+	return NewRepoProductsImagesNotFoundQuery(repo.query.SelectProductsImages(fields...))
+}
+
+func (repo *RepositoryProductsImagesNotFoundQueryImpl) ExcludeProductsImages(excludedFields ...ProductsImagesField) RepositoryProductsImagesQuery {
+	return NewRepoProductsImagesNotFoundQuery(repo.query.ExcludeProductsImages(excludedFields...))
+}
+
+func (repo *RepositoryProductsImagesNotFoundQueryImpl) FilterProductsImages(filter Filter) RepositoryProductsImagesQuery {
+	return NewRepoProductsImagesNotFoundQuery(repo.query.FilterProductsImages(filter))
+}
+
+func (repo *RepositoryProductsImagesNotFoundQueryImpl) PaginationProductsImages(pagination Pagination) RepositoryProductsImagesQuery {
+	return NewRepoProductsImagesNotFoundQuery(repo.query.PaginationProductsImages(pagination))
+}
+
+func (repo *RepositoryProductsImagesNotFoundQueryImpl) OrderByProductsImages(orderBy []Order) RepositoryProductsImagesQuery {
+	return NewRepoProductsImagesNotFoundQuery(repo.query.OrderByProductsImages(orderBy))
+}
+
+func (repo *RepositoryProductsImagesNotFoundQueryImpl) GetProductsImagesCount(ctx context.Context) (int, error) {
+	return repo.query.GetProductsImagesCount(ctx)
+}
+
+func (repo *RepositoryProductsImagesNotFoundQueryImpl) GetProductsImages(ctx context.Context) (*productsmodel.ProductsImages, error) {
+	list, err := repo.query.GetProductsImagesList(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(list) == 0 {
+		return nil, ErrNotFound
+	}
+	return list[0], nil
+}
+
+func (repo *RepositoryProductsImagesNotFoundQueryImpl) GetProductsImagesList(ctx context.Context) (productsmodel.ProductsImagesList, error) {
+	return repo.query.GetProductsImagesList(ctx)
+}
@@ -15,9 +15,9 @@ type RepositoriesImpl struct {
 	// inject db impl to RepositoriesImpl event the db is being used by the child struct impl
 	db *db.MysqlImpl
 	*RepositoryProductsCommandImpl
-	*RepositoryProductsQueryImpl
+	*RepositoryProductsNotFoundQueryImpl
 	*RepositoryProductsImagesCommandImpl
-	*RepositoryProductsImagesQueryImpl
+	*RepositoryProductsImagesNotFoundQueryImpl
 }
 
 func NewRepository(
@@ -25,17 +25,13 @@ func NewRepository(
 ) *RepositoriesImpl {
 	return &RepositoriesImpl{
 		db: db,
-		RepositoryProductsQueryImpl: &RepositoryProductsQueryImpl{
-			db: db.DB,
-		},
 		RepositoryProductsCommandImpl: &RepositoryProductsCommandImpl{
 			db: db.DB,
 		},
+		RepositoryProductsNotFoundQueryImpl: NewRepoProductsNotFoundQuery(NewRepoProductsQuery(db.DB)),
 		RepositoryProductsImagesCommandImpl: &RepositoryProductsImagesCommandImpl{
 			db: db.DB,
 		},
-		RepositoryProductsImagesQueryImpl: &RepositoryProductsImagesQueryImpl{
-			db: db.DB,
-		},
+		RepositoryProductsImagesNotFoundQueryImpl: NewRepoProductsImagesNotFoundQuery(NewRepoProductsImagesQuery(db.DB)),
 	}
 }
@@ -0,0 +1,10 @@
+package services
+
+import (
+	"golang-starter/internal/protocols/http/errors"
+	"net/http"
+)
+
+var (
+	ErrProductNotFound = errors.NewRespError(http.StatusNotFound, "PRODUCT_NOT_FOUND", "product not found")
+)
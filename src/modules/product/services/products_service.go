@@ -3,10 +3,11 @@ package services
 import (
 	"context"
 	"golang-starter/infrastructures/db/transaction"
+	"golang-starter/internal/logger"
+	"golang-starter/internal/protocols/http/errors"
+	"golang-starter/internal/utils/tracing"
 	"golang-starter/src/modules/product/dto"
 	"golang-starter/src/modules/product/repositories"
-
-	"github.com/rs/zerolog/log"
 )
 
 type ProductService interface {
@@ -32,15 +33,22 @@ func NewProductService(
 }
 
 func (s ProductServiceImpl) GetProducts(ctx context.Context) (dto.ProductsListResponse, error) {
+	ctx, span := tracing.Start(ctx, "ProductService.GetProducts")
+	defer span.End()
+
 	productList, err := s.ProductRepository.GetProductsList(ctx)
 	if err != nil {
-		log.Err(err).Msg("Error fetch productList from DB")
+		logger.Ctx(ctx).Err(err).Msg("Error fetch productList from DB")
+		return dto.ProductsListResponse{}, errors.Internal(err)
 	}
 	productsResp := dto.CreateProductsListResponse(productList)
 	return productsResp, nil
 }
 
 func (s ProductServiceImpl) GetProductByProductID(ctx context.Context, productID int) (dto.ProductsResponse, error) {
+	ctx, span := tracing.Start(ctx, "ProductService.GetProductByProductID")
+	defer span.End()
+
 	product, err := s.ProductRepository.
 		FilterProducts(
 			repositories.
@@ -48,14 +56,21 @@ func (s ProductServiceImpl) GetProductByProductID(ctx context.Context, productID
 				SetFilterByProductId(productID, "="),
 		).
 		GetProducts(ctx)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return dto.ProductsResponse{}, ErrProductNotFound
+	}
 	if err != nil {
-		log.Err(err).Msg("Error fetch productList from DB")
+		logger.Ctx(ctx).Err(err).Msg("Error fetch product from DB")
+		return dto.ProductsResponse{}, errors.Internal(err)
 	}
 	productResp := dto.CreateProductsResponse(*product)
 	return productResp, nil
 }
 
 func (s ProductServiceImpl) CreateNewProduct(ctx context.Context, data dto.ProductRequestBody) (*dto.ProductsResponse, error) {
+	ctx, span := tracing.Start(ctx, "ProductService.CreateNewProduct")
+	defer span.End()
+
 	product := data.ToProductEntities()
 
 	// start transaction
@@ -78,16 +93,23 @@ func (s ProductServiceImpl) CreateNewProduct(ctx context.Context, data dto.Produ
 		}
 		return nil
 	})
+	if err != nil {
+		logger.Ctx(ctx).Err(err).Msg("Error creating product")
+		return nil, errors.Internal(err)
+	}
 
-	return nil, err
+	return nil, nil
 }
 
 func (s ProductServiceImpl) DeleteProduct(ctx context.Context, productID int) error {
+	ctx, span := tracing.Start(ctx, "ProductService.DeleteProduct")
+	defer span.End()
+
 	err := s.ProductRepository.DeleteProducts(ctx, int32(productID))
 
 	if err != nil {
-		log.Err(err).Msg("Error deleting product")
-		return err
+		logger.Ctx(ctx).Err(err).Msg("Error deleting product")
+		return errors.Internal(err)
 	}
 
 	return nil
@@ -1,6 +1,47 @@
 package dto
 
 type UserRequestLoginBody struct {
-	Email    string `json:"email" form:"email"`
-	Password string `json:"password" form:"password"`
+	Email    string `json:"email" form:"email" validate:"required,email"`
+	Password string `json:"password" form:"password" validate:"required"`
+	// IP is filled by the handler, it's used for the login attempt budget
+	IP string `json:"-" form:"-"`
+}
+
+// UserRequestUpdateBody only update the fields that are sent
+type UserRequestUpdateBody struct {
+	Name     *string `json:"name" form:"name" validate:"omitempty,required,max=100"`
+	Username *string `json:"username" form:"username" validate:"omitempty,required,max=60"`
+	Photo    *string `json:"photo" form:"photo" validate:"omitempty,url"`
+}
+
+type UserRequestChangePasswordBody struct {
+	CurrentPassword string `json:"current_password" form:"current_password" validate:"required"`
+	NewPassword     string `json:"new_password" form:"new_password" validate:"required,min=8,max=72"`
+}
+
+// UserRequestListParams is the query params of the admin user list, zero values are not filtered
+type UserRequestListParams struct {
+	Email       string
+	Username    string
+	CreatedFrom int64
+	CreatedTo   int64
+	Disabled    *bool
+	Page        int
+	Size        int
+}
+
+type UserRequestCreateBody struct {
+	Email    string `json:"email" form:"email" validate:"required,email,max=100"`
+	Username string `json:"username" form:"username" validate:"required,max=60"`
+	Name     string `json:"name" form:"name" validate:"required,max=100"`
+	Password string `json:"password" form:"password" validate:"required,min=8,max=72"`
+	Photo    string `json:"photo" form:"photo" validate:"omitempty,url"`
+	Role     string `json:"role" form:"role" validate:"omitempty,oneof=user admin"`
+}
+
+type ApiKeyRequestCreateBody struct {
+	Name   string   `json:"name" form:"name" validate:"required,max=100"`
+	Scopes []string `json:"scopes" form:"scopes" validate:"required,dive,apikey_scope"`
+	// ExpiredAt is unix millisecond, the key never expires when it's empty
+	ExpiredAt *int64 `json:"expired_at" form:"expired_at" validate:"omitempty,min=1"`
 }
@@ -1,18 +1,27 @@
 package dto
 
-import "golang-starter/src/modules/user/entities"
+import (
+	"golang-starter/internal/utils/auth"
+	authdto "golang-starter/internal/utils/auth/dto"
+	"golang-starter/src/modules/user/entities"
+	"strings"
+)
 
 type UserRespBody struct {
-	UserID int    `json:"user_id"`
-	Name   string `json:"name"`
-	Email  string `json:"email"`
+	UserID   int    `json:"user_id"`
+	Name     string `json:"name"`
+	Username string `json:"username"`
+	Email    string `json:"email"`
+	Photo    string `json:"photo"`
 }
 
 func CreateUserResp(user entities.Users) UserRespBody {
 	return UserRespBody{
-		UserID: int(user.UserId),
-		Name:   user.Name,
-		Email:  user.Email,
+		UserID:   int(user.UserId),
+		Name:     user.Name,
+		Username: user.Username,
+		Email:    user.Email,
+		Photo:    user.Photo,
 	}
 }
 
@@ -20,4 +29,172 @@ type UserTokenRespBody struct {
 	Type         string `json:"type"`
 	Token        string `json:"token"`
 	RefreshToken string `json:"refresh_token"`
+	// PasswordResetRequired tell the client to ask the user for a new password
+	PasswordResetRequired bool `json:"password_reset_required,omitempty"`
+}
+
+func CreateUserTokenResp(token authdto.Token, user entities.Users) UserTokenRespBody {
+	return UserTokenRespBody{
+		Type:                  token.Type,
+		Token:                 token.Token,
+		RefreshToken:          token.RefreshToken,
+		PasswordResetRequired: user.PasswordResetAt.Valid,
+	}
+}
+
+// UserAdminRespBody is the user data that is shown to admin
+type UserAdminRespBody struct {
+	UserID                int    `json:"user_id"`
+	Name                  string `json:"name"`
+	Username              string `json:"username"`
+	Email                 string `json:"email"`
+	Photo                 string `json:"photo"`
+	Role                  string `json:"role"`
+	CreatedAt             int64  `json:"created_at"`
+	UpdatedAt             *int64 `json:"updated_at"`
+	DisabledAt            *int64 `json:"disabled_at"`
+	PasswordResetRequired bool   `json:"password_reset_required"`
+}
+
+func CreateUserAdminResp(user entities.Users) UserAdminRespBody {
+	return UserAdminRespBody{
+		UserID:                int(user.UserId),
+		Name:                  user.Name,
+		Username:              user.Username,
+		Email:                 user.Email,
+		Photo:                 user.Photo,
+		Role:                  user.Role,
+		CreatedAt:             user.CreatedAt,
+		UpdatedAt:             user.UpdatedAt.Ptr(),
+		DisabledAt:            user.DisabledAt.Ptr(),
+		PasswordResetRequired: user.PasswordResetAt.Valid,
+	}
+}
+
+type UserListRespBody struct {
+	Users []UserAdminRespBody `json:"users"`
+	Page  int                 `json:"page"`
+	Size  int                 `json:"size"`
+	Total int                 `json:"total"`
+}
+
+func CreateUserListResp(users entities.UsersList, page, size, total int) UserListRespBody {
+	usersResp := []UserAdminRespBody{}
+	for _, user := range users {
+		usersResp = append(usersResp, CreateUserAdminResp(*user))
+	}
+	return UserListRespBody{
+		Users: usersResp,
+		Page:  page,
+		Size:  size,
+		Total: total,
+	}
+}
+
+// UserPasswordResetRespBody hold the temporary password, it's only shown once
+type UserPasswordResetRespBody struct {
+	TemporaryPassword string `json:"temporary_password"`
+}
+
+type UserAuditLogRespBody struct {
+	AuditLogID   int    `json:"audit_log_id"`
+	ActorUserID  int    `json:"actor_user_id"`
+	TargetUserID int    `json:"target_user_id"`
+	Action       string `json:"action"`
+	Detail       string `json:"detail"`
+	CreatedAt    int64  `json:"created_at"`
+}
+
+func CreateUserAuditLogListResp(auditLogs entities.UserAuditLogsList) []UserAuditLogRespBody {
+	auditLogsResp := []UserAuditLogRespBody{}
+	for _, auditLog := range auditLogs {
+		auditLogsResp = append(auditLogsResp, UserAuditLogRespBody{
+			AuditLogID:   int(auditLog.AuditLogId),
+			ActorUserID:  int(auditLog.ActorUserFkid),
+			TargetUserID: int(auditLog.TargetUserFkid),
+			Action:       auditLog.Action,
+			Detail:       auditLog.Detail,
+			CreatedAt:    auditLog.CreatedAt,
+		})
+	}
+	return auditLogsResp
+}
+
+type ApiKeyRespBody struct {
+	ApiKeyID   int      `json:"api_key_id"`
+	Name       string   `json:"name"`
+	Prefix     string   `json:"prefix"`
+	Scopes     []string `json:"scopes"`
+	ExpiredAt  *int64   `json:"expired_at"`
+	LastUsedAt *int64   `json:"last_used_at"`
+	RevokedAt  *int64   `json:"revoked_at"`
+	CreatedAt  int64    `json:"created_at"`
+}
+
+func CreateApiKeyResp(apiKey entities.ApiKeys) ApiKeyRespBody {
+	return ApiKeyRespBody{
+		ApiKeyID:   int(apiKey.ApiKeyId),
+		Name:       apiKey.Name,
+		Prefix:     apiKey.Prefix,
+		Scopes:     strings.Fields(apiKey.Scopes),
+		ExpiredAt:  apiKey.ExpiredAt.Ptr(),
+		LastUsedAt: apiKey.LastUsedAt.Ptr(),
+		RevokedAt:  apiKey.RevokedAt.Ptr(),
+		CreatedAt:  apiKey.CreatedAt,
+	}
+}
+
+func CreateApiKeyListResp(apiKeys entities.ApiKeysList) []ApiKeyRespBody {
+	apiKeysResp := []ApiKeyRespBody{}
+	for _, apiKey := range apiKeys {
+		apiKeysResp = append(apiKeysResp, CreateApiKeyResp(*apiKey))
+	}
+	return apiKeysResp
+}
+
+// ApiKeySecretRespBody is returned when a key is created or rotated, the key can't be shown again
+type ApiKeySecretRespBody struct {
+	ApiKeyRespBody
+	Key string `json:"key"`
+}
+
+// TokenIntrospectRespBody is the RFC 7662 introspection response, an inactive token only has active
+type TokenIntrospectRespBody struct {
+	Active    bool     `json:"active"`
+	Sub       string   `json:"sub,omitempty"`
+	Username  string   `json:"username,omitempty"`
+	Role      string   `json:"role,omitempty"`
+	TokenType string   `json:"token_type,omitempty"`
+	Iss       string   `json:"iss,omitempty"`
+	Aud       []string `json:"aud,omitempty"`
+	Jti       string   `json:"jti,omitempty"`
+	Sid       string   `json:"sid,omitempty"`
+	Exp       int64    `json:"exp,omitempty"`
+	Iat       int64    `json:"iat,omitempty"`
+	Nbf       int64    `json:"nbf,omitempty"`
+}
+
+func CreateTokenIntrospectResp(claims *auth.Claims, user entities.Users) TokenIntrospectRespBody {
+	return TokenIntrospectRespBody{
+		Active:    true,
+		Sub:       claims.Subject,
+		Username:  user.Username,
+		Role:      claims.Role,
+		TokenType: claims.TokenType,
+		Iss:       claims.Issuer,
+		Aud:       claims.Audience,
+		Jti:       claims.ID,
+		Sid:       claims.SessionID,
+		Exp:       claims.ExpiresAt,
+		Iat:       claims.IssuedAt,
+		Nbf:       claims.NotBefore,
+	}
+}
+
+// UserOidcAuth is where the user is redirected to sign in with the provider, the browser keep StateHash in a cookie
+// until Expired so the callback can only be finished by the browser that started the sign in
+type UserOidcAuth struct {
+	AuthURL   string
+	StateHash string
+	Expired   int64
 }
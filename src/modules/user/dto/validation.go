@@ -0,0 +1,28 @@
+package dto
+
+import (
+	"golang-starter/internal/utils/validation"
+	"golang-starter/src/modules/user/entities"
+	"reflect"
+	"strings"
+)
+
+func init() {
+	// apikey_scope only accepts the scopes that can be granted to an api key
+	validation.Register("apikey_scope", validation.Rule{
+		Check: func(value reflect.Value, _ string) bool {
+			if value.Kind() != reflect.String {
+				return false
+			}
+			for _, scope := range entities.ApiKeyScopes {
+				if value.String() == scope {
+					return true
+				}
+			}
+			return false
+		},
+		Message: func(reflect.Value, string) string {
+			return "must be one of " + strings.Join(entities.ApiKeyScopes, ", ")
+		},
+	})
+}
@@ -0,0 +1,22 @@
+package entities
+
+// permissions that can be granted to an api key, a key can only reach the routes of its scopes
+const (
+	ApiKeyScopeUsersRead        = "users:read"
+	ApiKeyScopeUsersWrite       = "users:write"
+	ApiKeyScopeTokensIntrospect = "tokens:introspect"
+	ApiKeyScopeAdmin            = "admin"
+)
+
+var ApiKeyScopes = []string{
+	ApiKeyScopeUsersRead,
+	ApiKeyScopeUsersWrite,
+	ApiKeyScopeTokensIntrospect,
+	ApiKeyScopeAdmin,
+}
+
+// ApiKeyAdminScopes are the scopes only an admin can grant, a key of them reach what only an admin can
+var ApiKeyAdminScopes = []string{
+	ApiKeyScopeTokensIntrospect,
+	ApiKeyScopeAdmin,
+}
@@ -0,0 +1,9 @@
+package entities
+
+// OidcState is saved between the redirect to the provider and its callback
+type OidcState struct {
+	Provider     string
+	Nonce        string
+	CodeVerifier string
+	Expired      int64
+}
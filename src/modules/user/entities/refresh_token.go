@@ -1,6 +0,0 @@
-package entities
-
-type UserRefreshToken struct {
-	RefreshToken string
-	Expired      int64
-}
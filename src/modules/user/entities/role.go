@@ -0,0 +1,6 @@
+package entities
+
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
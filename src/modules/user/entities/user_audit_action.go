@@ -0,0 +1,11 @@
+package entities
+
+// actions recorded in user_audit_logs
+const (
+	AuditActionUserCreate        = "user.create"
+	AuditActionUserDisable       = "user.disable"
+	AuditActionUserEnable        = "user.enable"
+	AuditActionUserPasswordReset = "user.password_reset"
+	AuditActionUserDelete        = "user.delete"
+	AuditActionUserUnlock        = "user.unlock"
+)
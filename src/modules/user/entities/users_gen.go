@@ -6,14 +6,18 @@ import (
 )
 
 type Users struct {
-	UserId    int32    `db:"user_id"`
-	Photo     string   `db:"photo"`
-	Username  string   `db:"username"`
-	Email     string   `db:"email"`
-	Password  string   `db:"password"`
-	Name      string   `db:"name"`
-	CreatedAt int64    `db:"created_at"`
-	UpdatedAt null.Int `db:"updated_at"`
+	UserId          int32       `db:"user_id"`
+	Photo           string      `db:"photo"`
+	Username        string      `db:"username"`
+	Email           string      `db:"email"`
+	EmailBidx       null.String `db:"email_bidx"`
+	Password        string      `db:"password"`
+	Name            string      `db:"name"`
+	Role            string      `db:"role"`
+	CreatedAt       int64       `db:"created_at"`
+	UpdatedAt       null.Int    `db:"updated_at"`
+	DisabledAt      null.Int    `db:"disabled_at"`
+	PasswordResetAt null.Int    `db:"password_reset_at"`
 }
 
 type UsersList []*Users
@@ -0,0 +1,181 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	apikeysmodel "golang-starter/src/modules/user/entities"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/nurcahyaari/sqlabst"
+)
+
+type RepositoryApiKeysCommand interface {
+	InsertApiKeysList(ctx context.Context, apiKeysList apikeysmodel.ApiKeysList) (*InsertResult, error)
+	InsertApiKeys(ctx context.Context, apiKeys *apikeysmodel.ApiKeys) (*InsertResult, error)
+	UpdateApiKeysByFilter(ctx context.Context, apiKeys *apikeysmodel.ApiKeys, filter Filter, updatedFields ...ApiKeysField) error
+	UpdateApiKeys(ctx context.Context, apiKeys *apikeysmodel.ApiKeys, apikeyid int32, updatedFields ...ApiKeysField) error
+	DeleteApiKeysList(ctx context.Context, filter Filter) error
+	DeleteApiKeys(ctx context.Context, apikeyid int32) error
+}
+
+type RepositoryApiKeysCommandImpl struct {
+	db *sqlabst.SqlAbst
+}
+
+func (repo *RepositoryApiKeysCommandImpl) InsertApiKeysList(ctx context.Context, apiKeysList apikeysmodel.ApiKeysList) (*InsertResult, error) {
+	command := `INSERT INTO api_keys (user_fkid,
+	name,
+	prefix,
+	key_hash,
+	scopes,
+	expired_at,
+	last_used_at,
+	revoked_at,
+	created_at,
+	updated_at) VALUES
+		`
+
+	var (
+		placeholders []string
+		args         []interface{}
+	)
+	for _, apiKeys := range apiKeysList {
+		placeholders = append(placeholders, `(?,
+	?,
+	?,
+	?,
+	?,
+	?,
+	?,
+	?,
+	?,
+	?)`)
+		args = append(args,
+			apiKeys.UserFkid,
+			apiKeys.Name,
+			apiKeys.Prefix,
+			apiKeys.KeyHash,
+			apiKeys.Scopes,
+			apiKeys.ExpiredAt,
+			apiKeys.LastUsedAt,
+			apiKeys.RevokedAt,
+			apiKeys.CreatedAt,
+			apiKeys.UpdatedAt,
+		)
+	}
+	command += strings.Join(placeholders, ",")
+
+	sqlResult, err := repo.exec(ctx, command, args)
+	if err != nil {
+		return nil, err
+	}
+
+	return &InsertResult{Result: sqlResult}, nil
+}
+
+func (repo *RepositoryApiKeysCommandImpl) InsertApiKeys(ctx context.Context, apiKeys *apikeysmodel.ApiKeys) (*InsertResult, error) {
+	return repo.InsertApiKeysList(ctx, apikeysmodel.ApiKeysList{apiKeys})
+}
+
+func (repo *RepositoryApiKeysCommandImpl) UpdateApiKeysByFilter(ctx context.Context, apiKeys *apikeysmodel.ApiKeys, filter Filter, updatedFields ...ApiKeysField) error {
+	updatedFieldQuery, values := buildUpdateFieldsApiKeysQuery(updatedFields, apiKeys)
+	command := fmt.Sprintf(`UPDATE api_keys 
+			SET %s 
+		WHERE %s
+		`, strings.Join(updatedFieldQuery, ","), filter.Query())
+	values = append(values, filter.Values()...)
+	_, err := repo.exec(ctx, command, values)
+	return err
+}
+
+func (repo *RepositoryApiKeysCommandImpl) UpdateApiKeys(ctx context.Context, apiKeys *apikeysmodel.ApiKeys, apikeyid int32, updatedFields ...ApiKeysField) error {
+	updatedFieldQuery, values := buildUpdateFieldsApiKeysQuery(updatedFields, apiKeys)
+	command := fmt.Sprintf(`UPDATE api_keys 
+			SET %s 
+		WHERE api_key_id = ?
+		`, strings.Join(updatedFieldQuery, ","))
+	values = append(values, apikeyid)
+	_, err := repo.exec(ctx, command, values)
+	return err
+}
+
+func (repo *RepositoryApiKeysCommandImpl) DeleteApiKeysList(ctx context.Context, filter Filter) error {
+	command := "DELETE FROM api_keys WHERE " + filter.Query()
+	_, err := repo.exec(ctx, command, filter.Values())
+	return err
+}
+
+func (repo *RepositoryApiKeysCommandImpl) DeleteApiKeys(ctx context.Context, apikeyid int32) error {
+	command := "DELETE FROM api_keys WHERE api_key_id = ?"
+	_, err := repo.exec(ctx, command, []interface{}{apikeyid})
+	return err
+}
+
+func NewRepoApiKeysCommand(db *sqlabst.SqlAbst) RepositoryApiKeysCommand {
+	return &RepositoryApiKeysCommandImpl{
+		db: db,
+	}
+}
+
+func (repo *RepositoryApiKeysCommandImpl) exec(ctx context.Context, command string, args []interface{}) (sql.Result, error) {
+	var (
+		stmt *sqlx.Stmt
+		err  error
+	)
+	stmt, err = repo.db.PreparexContext(ctx, command)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return stmt.ExecContext(ctx, args...)
+}
+
+func buildUpdateFieldsApiKeysQuery(updatedFields ApiKeysFieldList, apiKeys *apikeysmodel.ApiKeys) ([]string, []interface{}) {
+	var (
+		updatedFieldsQuery []string
+		args               []interface{}
+	)
+
+	for _, field := range updatedFields {
+		switch field {
+		case "api_key_id":
+			updatedFieldsQuery = append(updatedFieldsQuery, "api_key_id = ?")
+			args = append(args, apiKeys.ApiKeyId)
+		case "user_fkid":
+			updatedFieldsQuery = append(updatedFieldsQuery, "user_fkid = ?")
+			args = append(args, apiKeys.UserFkid)
+		case "name":
+			updatedFieldsQuery = append(updatedFieldsQuery, "name = ?")
+			args = append(args, apiKeys.Name)
+		case "prefix":
+			updatedFieldsQuery = append(updatedFieldsQuery, "prefix = ?")
+			args = append(args, apiKeys.Prefix)
+		case "key_hash":
+			updatedFieldsQuery = append(updatedFieldsQuery, "key_hash = ?")
+			args = append(args, apiKeys.KeyHash)
+		case "scopes":
+			updatedFieldsQuery = append(updatedFieldsQuery, "scopes = ?")
+			args = append(args, apiKeys.Scopes)
+		case "expired_at":
+			updatedFieldsQuery = append(updatedFieldsQuery, "expired_at = ?")
+			args = append(args, apiKeys.ExpiredAt)
+		case "last_used_at":
+			updatedFieldsQuery = append(updatedFieldsQuery, "last_used_at = ?")
+			args = append(args, apiKeys.LastUsedAt)
+		case "revoked_at":
+			updatedFieldsQuery = append(updatedFieldsQuery, "revoked_at = ?")
+			args = append(args, apiKeys.RevokedAt)
+		case "created_at":
+			updatedFieldsQuery = append(updatedFieldsQuery, "created_at = ?")
+			args = append(args, apiKeys.CreatedAt)
+		case "updated_at":
+			updatedFieldsQuery = append(updatedFieldsQuery, "updated_at = ?")
+			args = append(args, apiKeys.UpdatedAt)
+		}
+	}
+
+	return updatedFieldsQuery, args
+}
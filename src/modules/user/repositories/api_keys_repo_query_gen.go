@@ -0,0 +1,668 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	apikeysmodel "golang-starter/src/modules/user/entities"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/nurcahyaari/sqlabst"
+)
+
+type RepositoryApiKeysQuery interface {
+	SelectApiKeys(fields ...ApiKeysField) RepositoryApiKeysQuery
+	ExcludeApiKeys(excludedFields ...ApiKeysField) RepositoryApiKeysQuery
+	FilterApiKeys(filter Filter) RepositoryApiKeysQuery
+	PaginationApiKeys(pagination Pagination) RepositoryApiKeysQuery
+	OrderByApiKeys(orderBy []Order) RepositoryApiKeysQuery
+	GetApiKeysCount(ctx context.Context) (int, error)
+	GetApiKeys(ctx context.Context) (*apikeysmodel.ApiKeys, error)
+	GetApiKeysList(ctx context.Context) (apikeysmodel.ApiKeysList, error)
+}
+
+type RepositoryApiKeysQueryImpl struct {
+	db         *sqlabst.SqlAbst
+	query      string
+	filter     Filter
+	orderBy    []Order
+	pagination Pagination
+	fields     ApiKeysFieldList
+}
+
+func (repo *RepositoryApiKeysQueryImpl) SelectApiKeys(fields ...ApiKeysField) RepositoryApiKeysQuery {
+	return &RepositoryApiKeysQueryImpl{
+		db:         repo.db,
+		filter:     repo.filter,
+		orderBy:    repo.orderBy,
+		pagination: repo.pagination,
+		fields:     fields,
+	}
+}
+
+func (repo *RepositoryApiKeysQueryImpl) ExcludeApiKeys(excludedFields ...ApiKeysField) RepositoryApiKeysQuery {
+	selectedFieldsStr := excludeFields(ApiKeysFieldList(excludedFields).toString(),
+		ApiKeysSelectFields{}.All().toString())
+
+	var selectedFields []ApiKeysField
+	for _, sel := range selectedFieldsStr {
+		selectedFields = append(selectedFields, ApiKeysField(sel))
+	}
+
+	return &RepositoryApiKeysQueryImpl{
+		db:         repo.db,
+		filter:     repo.filter,
+		orderBy:    repo.orderBy,
+		pagination: repo.pagination,
+		fields:     selectedFields,
+	}
+}
+
+func (repo *RepositoryApiKeysQueryImpl) FilterApiKeys(filter Filter) RepositoryApiKeysQuery {
+	return &RepositoryApiKeysQueryImpl{
+		db:         repo.db,
+		filter:     filter,
+		orderBy:    repo.orderBy,
+		pagination: repo.pagination,
+		fields:     repo.fields,
+	}
+}
+
+func (repo *RepositoryApiKeysQueryImpl) PaginationApiKeys(pagination Pagination) RepositoryApiKeysQuery {
+	return &RepositoryApiKeysQueryImpl{
+		db:         repo.db,
+		filter:     repo.filter,
+		orderBy:    repo.orderBy,
+		pagination: pagination,
+		fields:     repo.fields,
+	}
+}
+
+func (repo *RepositoryApiKeysQueryImpl) OrderByApiKeys(orderBy []Order) RepositoryApiKeysQuery {
+	return &RepositoryApiKeysQueryImpl{
+		db:         repo.db,
+		filter:     repo.filter,
+		orderBy:    orderBy,
+		pagination: repo.pagination,
+		fields:     repo.fields,
+	}
+}
+
+func (repo *RepositoryApiKeysQueryImpl) GetApiKeysList(ctx context.Context) (apikeysmodel.ApiKeysList, error) {
+	var (
+		apiKeysList apikeysmodel.ApiKeysList
+		values      []interface{}
+	)
+
+	if len(repo.fields) == 0 {
+		repo.fields = ApiKeysSelectFields{}.All()
+	}
+
+	query := fmt.Sprintf("SELECT %s FROM api_keys", strings.Join(repo.fields.toString(), ","))
+	if repo.filter != nil {
+		query += " WHERE " + repo.filter.Query()
+		values = append(values, repo.filter.Values()...)
+	}
+
+	if len(repo.orderBy) > 0 {
+		var orderStr []string
+		for _, order := range repo.orderBy {
+			orderStr = append(orderStr, order.Value()+" "+order.Direction())
+		}
+		query += fmt.Sprintf(" ORDER BY %s", strings.Join(orderStr, ","))
+	}
+
+	if repo.pagination != nil {
+		offset := (repo.pagination.GetPage() - 1) * repo.pagination.GetSize()
+		query += fmt.Sprintf(" LIMIT %d OFFSET %d", repo.pagination.GetSize(), offset)
+	}
+
+	err := repo.db.SelectContext(ctx, &apiKeysList, query, values...)
+	if err != nil {
+		return nil, err
+	}
+	return apiKeysList, nil
+}
+
+func (repo *RepositoryApiKeysQueryImpl) GetApiKeysCount(ctx context.Context) (int, error) {
+	var values []interface{}
+	query := fmt.Sprintf("SELECT count(1) FROM api_keys")
+	if repo.filter != nil {
+		query += " WHERE " + repo.filter.Query()
+		values = append(values, repo.filter.Values()...)
+	}
+
+	var count int
+	err := repo.db.QueryRowContext(ctx, query, values...).Scan(&count)
+	return count, err
+}
+
+func (repo *RepositoryApiKeysQueryImpl) GetApiKeys(ctx context.Context) (*apikeysmodel.ApiKeys, error) {
+	apiKeysList, err := repo.GetApiKeysList(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(apiKeysList) == 0 {
+		return nil, errors.New("apikeys not found")
+	}
+
+	return apiKeysList[0], nil
+}
+
+func NewRepoApiKeysQuery(db *sqlabst.SqlAbst) RepositoryApiKeysQuery {
+	return &RepositoryApiKeysQueryImpl{
+		db: db,
+	}
+}
+
+type ApiKeysField string
+type ApiKeysFieldList []ApiKeysField
+
+func (fieldList ApiKeysFieldList) toString() []string {
+	var fieldsStr []string
+	for _, field := range fieldList {
+		fieldsStr = append(fieldsStr, string(field))
+	}
+	return fieldsStr
+}
+
+type ApiKeysSelectFields struct {
+}
+
+func (ApiKeysSelectFields) ApiKeyId() ApiKeysField {
+	return ApiKeysField("api_key_id")
+}
+func (ApiKeysSelectFields) UserFkid() ApiKeysField {
+	return ApiKeysField("user_fkid")
+}
+func (ApiKeysSelectFields) Name() ApiKeysField {
+	return ApiKeysField("name")
+}
+func (ApiKeysSelectFields) Prefix() ApiKeysField {
+	return ApiKeysField("prefix")
+}
+func (ApiKeysSelectFields) KeyHash() ApiKeysField {
+	return ApiKeysField("key_hash")
+}
+func (ApiKeysSelectFields) Scopes() ApiKeysField {
+	return ApiKeysField("scopes")
+}
+func (ApiKeysSelectFields) ExpiredAt() ApiKeysField {
+	return ApiKeysField("expired_at")
+}
+func (ApiKeysSelectFields) LastUsedAt() ApiKeysField {
+	return ApiKeysField("last_used_at")
+}
+func (ApiKeysSelectFields) RevokedAt() ApiKeysField {
+	return ApiKeysField("revoked_at")
+}
+func (ApiKeysSelectFields) CreatedAt() ApiKeysField {
+	return ApiKeysField("created_at")
+}
+func (ApiKeysSelectFields) UpdatedAt() ApiKeysField {
+	return ApiKeysField("updated_at")
+}
+
+func (ApiKeysSelectFields) All() ApiKeysFieldList {
+	return []ApiKeysField{
+		ApiKeysField("api_key_id"),
+		ApiKeysField("user_fkid"),
+		ApiKeysField("name"),
+		ApiKeysField("prefix"),
+		ApiKeysField("key_hash"),
+		ApiKeysField("scopes"),
+		ApiKeysField("expired_at"),
+		ApiKeysField("last_used_at"),
+		ApiKeysField("revoked_at"),
+		ApiKeysField("created_at"),
+		ApiKeysField("updated_at"),
+	}
+}
+
+func NewApiKeysSelectFields() ApiKeysSelectFields {
+	return ApiKeysSelectFields{}
+}
+
+type ApiKeysFilter struct {
+	operator string
+	query    []string
+	values   []interface{}
+}
+
+func NewApiKeysFilter(operator string) ApiKeysFilter {
+	if operator == "" {
+		operator = "AND"
+	}
+	return ApiKeysFilter{
+		operator: operator,
+	}
+}
+
+func (f ApiKeysFilter) SetFilterByApiKeyId(value interface{}, operator string) ApiKeysFilter {
+	query := "api_key_id " + operator + " (?)"
+	var values []interface{}
+	if value == nil {
+		query = "api_key_id " + operator
+	} else {
+		switch strings.ToUpper(operator) {
+		case "IN", "NOT IN":
+			query, values, _ = sqlx.In(query, value)
+		default:
+			values = append(values, value)
+		}
+	}
+	return ApiKeysFilter{
+		operator: f.operator,
+		query:    append(f.query, query),
+		values:   append(f.values, values...),
+	}
+}
+func (f ApiKeysFilter) SetFilterByUserFkid(value interface{}, operator string) ApiKeysFilter {
+	query := "user_fkid " + operator + " (?)"
+	var values []interface{}
+	if value == nil {
+		query = "user_fkid " + operator
+	} else {
+		switch strings.ToUpper(operator) {
+		case "IN", "NOT IN":
+			query, values, _ = sqlx.In(query, value)
+		default:
+			values = append(values, value)
+		}
+	}
+	return ApiKeysFilter{
+		operator: f.operator,
+		query:    append(f.query, query),
+		values:   append(f.values, values...),
+	}
+}
+func (f ApiKeysFilter) SetFilterByName(value interface{}, operator string) ApiKeysFilter {
+	query := "name " + operator + " (?)"
+	var values []interface{}
+	if value == nil {
+		query = "name " + operator
+	} else {
+		switch strings.ToUpper(operator) {
+		case "IN", "NOT IN":
+			query, values, _ = sqlx.In(query, value)
+		default:
+			values = append(values, value)
+		}
+	}
+	return ApiKeysFilter{
+		operator: f.operator,
+		query:    append(f.query, query),
+		values:   append(f.values, values...),
+	}
+}
+func (f ApiKeysFilter) SetFilterByPrefix(value interface{}, operator string) ApiKeysFilter {
+	query := "prefix " + operator + " (?)"
+	var values []interface{}
+	if value == nil {
+		query = "prefix " + operator
+	} else {
+		switch strings.ToUpper(operator) {
+		case "IN", "NOT IN":
+			query, values, _ = sqlx.In(query, value)
+		default:
+			values = append(values, value)
+		}
+	}
+	return ApiKeysFilter{
+		operator: f.operator,
+		query:    append(f.query, query),
+		values:   append(f.values, values...),
+	}
+}
+func (f ApiKeysFilter) SetFilterByKeyHash(value interface{}, operator string) ApiKeysFilter {
+	query := "key_hash " + operator + " (?)"
+	var values []interface{}
+	if value == nil {
+		query = "key_hash " + operator
+	} else {
+		switch strings.ToUpper(operator) {
+		case "IN", "NOT IN":
+			query, values, _ = sqlx.In(query, value)
+		default:
+			values = append(values, value)
+		}
+	}
+	return ApiKeysFilter{
+		operator: f.operator,
+		query:    append(f.query, query),
+		values:   append(f.values, values...),
+	}
+}
+func (f ApiKeysFilter) SetFilterByScopes(value interface{}, operator string) ApiKeysFilter {
+	query := "scopes " + operator + " (?)"
+	var values []interface{}
+	if value == nil {
+		query = "scopes " + operator
+	} else {
+		switch strings.ToUpper(operator) {
+		case "IN", "NOT IN":
+			query, values, _ = sqlx.In(query, value)
+		default:
+			values = append(values, value)
+		}
+	}
+	return ApiKeysFilter{
+		operator: f.operator,
+		query:    append(f.query, query),
+		values:   append(f.values, values...),
+	}
+}
+func (f ApiKeysFilter) SetFilterByExpiredAt(value interface{}, operator string) ApiKeysFilter {
+	query := "expired_at " + operator + " (?)"
+	var values []interface{}
+	if value == nil {
+		query = "expired_at " + operator
+	} else {
+		switch strings.ToUpper(operator) {
+		case "IN", "NOT IN":
+			query, values, _ = sqlx.In(query, value)
+		default:
+			values = append(values, value)
+		}
+	}
+	return ApiKeysFilter{
+		operator: f.operator,
+		query:    append(f.query, query),
+		values:   append(f.values, values...),
+	}
+}
+func (f ApiKeysFilter) SetFilterByLastUsedAt(value interface{}, operator string) ApiKeysFilter {
+	query := "last_used_at " + operator + " (?)"
+	var values []interface{}
+	if value == nil {
+		query = "last_used_at " + operator
+	} else {
+		switch strings.ToUpper(operator) {
+		case "IN", "NOT IN":
+			query, values, _ = sqlx.In(query, value)
+		default:
+			values = append(values, value)
+		}
+	}
+	return ApiKeysFilter{
+		operator: f.operator,
+		query:    append(f.query, query),
+		values:   append(f.values, values...),
+	}
+}
+func (f ApiKeysFilter) SetFilterByRevokedAt(value interface{}, operator string) ApiKeysFilter {
+	query := "revoked_at " + operator + " (?)"
+	var values []interface{}
+	if value == nil {
+		query = "revoked_at " + operator
+	} else {
+		switch strings.ToUpper(operator) {
+		case "IN", "NOT IN":
+			query, values, _ = sqlx.In(query, value)
+		default:
+			values = append(values, value)
+		}
+	}
+	return ApiKeysFilter{
+		operator: f.operator,
+		query:    append(f.query, query),
+		values:   append(f.values, values...),
+	}
+}
+func (f ApiKeysFilter) SetFilterByCreatedAt(value interface{}, operator string) ApiKeysFilter {
+	query := "created_at " + operator + " (?)"
+	var values []interface{}
+	if value == nil {
+		query = "created_at " + operator
+	} else {
+		switch strings.ToUpper(operator) {
+		case "IN", "NOT IN":
+			query, values, _ = sqlx.In(query, value)
+		default:
+			values = append(values, value)
+		}
+	}
+	return ApiKeysFilter{
+		operator: f.operator,
+		query:    append(f.query, query),
+		values:   append(f.values, values...),
+	}
+}
+func (f ApiKeysFilter) SetFilterByUpdatedAt(value interface{}, operator string) ApiKeysFilter {
+	query := "updated_at " + operator + " (?)"
+	var values []interface{}
+	if value == nil {
+		query = "updated_at " + operator
+	} else {
+		switch strings.ToUpper(operator) {
+		case "IN", "NOT IN":
+			query, values, _ = sqlx.In(query, value)
+		default:
+			values = append(values, value)
+		}
+	}
+	return ApiKeysFilter{
+		operator: f.operator,
+		query:    append(f.query, query),
+		values:   append(f.values, values...),
+	}
+}
+
+func (f ApiKeysFilter) Query() string {
+	return strings.Join(f.query, " "+f.operator+" ")
+}
+
+func (f ApiKeysFilter) Values() []interface{} {
+	return f.values
+}
+
+type ApiKeysApiKeyIdOrder struct {
+	direction string
+}
+
+func (o ApiKeysApiKeyIdOrder) SetDirection(direction string) ApiKeysApiKeyIdOrder {
+	return ApiKeysApiKeyIdOrder{
+		direction: direction,
+	}
+}
+func (o ApiKeysApiKeyIdOrder) Value() string {
+	return "api_key_id"
+}
+func (o ApiKeysApiKeyIdOrder) Direction() string {
+	return o.direction
+}
+func NewApiKeysApiKeyIdOrder() ApiKeysApiKeyIdOrder {
+	return ApiKeysApiKeyIdOrder{}
+}
+
+type ApiKeysUserFkidOrder struct {
+	direction string
+}
+
+func (o ApiKeysUserFkidOrder) SetDirection(direction string) ApiKeysUserFkidOrder {
+	return ApiKeysUserFkidOrder{
+		direction: direction,
+	}
+}
+func (o ApiKeysUserFkidOrder) Value() string {
+	return "user_fkid"
+}
+func (o ApiKeysUserFkidOrder) Direction() string {
+	return o.direction
+}
+func NewApiKeysUserFkidOrder() ApiKeysUserFkidOrder {
+	return ApiKeysUserFkidOrder{}
+}
+
+type ApiKeysNameOrder struct {
+	direction string
+}
+
+func (o ApiKeysNameOrder) SetDirection(direction string) ApiKeysNameOrder {
+	return ApiKeysNameOrder{
+		direction: direction,
+	}
+}
+func (o ApiKeysNameOrder) Value() string {
+	return "name"
+}
+func (o ApiKeysNameOrder) Direction() string {
+	return o.direction
+}
+func NewApiKeysNameOrder() ApiKeysNameOrder {
+	return ApiKeysNameOrder{}
+}
+
+type ApiKeysPrefixOrder struct {
+	direction string
+}
+
+func (o ApiKeysPrefixOrder) SetDirection(direction string) ApiKeysPrefixOrder {
+	return ApiKeysPrefixOrder{
+		direction: direction,
+	}
+}
+func (o ApiKeysPrefixOrder) Value() string {
+	return "prefix"
+}
+func (o ApiKeysPrefixOrder) Direction() string {
+	return o.direction
+}
+func NewApiKeysPrefixOrder() ApiKeysPrefixOrder {
+	return ApiKeysPrefixOrder{}
+}
+
+type ApiKeysKeyHashOrder struct {
+	direction string
+}
+
+func (o ApiKeysKeyHashOrder) SetDirection(direction string) ApiKeysKeyHashOrder {
+	return ApiKeysKeyHashOrder{
+		direction: direction,
+	}
+}
+func (o ApiKeysKeyHashOrder) Value() string {
+	return "key_hash"
+}
+func (o ApiKeysKeyHashOrder) Direction() string {
+	return o.direction
+}
+func NewApiKeysKeyHashOrder() ApiKeysKeyHashOrder {
+	return ApiKeysKeyHashOrder{}
+}
+
+type ApiKeysScopesOrder struct {
+	direction string
+}
+
+func (o ApiKeysScopesOrder) SetDirection(direction string) ApiKeysScopesOrder {
+	return ApiKeysScopesOrder{
+		direction: direction,
+	}
+}
+func (o ApiKeysScopesOrder) Value() string {
+	return "scopes"
+}
+func (o ApiKeysScopesOrder) Direction() string {
+	return o.direction
+}
+func NewApiKeysScopesOrder() ApiKeysScopesOrder {
+	return ApiKeysScopesOrder{}
+}
+
+type ApiKeysExpiredAtOrder struct {
+	direction string
+}
+
+func (o ApiKeysExpiredAtOrder) SetDirection(direction string) ApiKeysExpiredAtOrder {
+	return ApiKeysExpiredAtOrder{
+		direction: direction,
+	}
+}
+func (o ApiKeysExpiredAtOrder) Value() string {
+	return "expired_at"
+}
+func (o ApiKeysExpiredAtOrder) Direction() string {
+	return o.direction
+}
+func NewApiKeysExpiredAtOrder() ApiKeysExpiredAtOrder {
+	return ApiKeysExpiredAtOrder{}
+}
+
+type ApiKeysLastUsedAtOrder struct {
+	direction string
+}
+
+func (o ApiKeysLastUsedAtOrder) SetDirection(direction string) ApiKeysLastUsedAtOrder {
+	return ApiKeysLastUsedAtOrder{
+		direction: direction,
+	}
+}
+func (o ApiKeysLastUsedAtOrder) Value() string {
+	return "last_used_at"
+}
+func (o ApiKeysLastUsedAtOrder) Direction() string {
+	return o.direction
+}
+func NewApiKeysLastUsedAtOrder() ApiKeysLastUsedAtOrder {
+	return ApiKeysLastUsedAtOrder{}
+}
+
+type ApiKeysRevokedAtOrder struct {
+	direction string
+}
+
+func (o ApiKeysRevokedAtOrder) SetDirection(direction string) ApiKeysRevokedAtOrder {
+	return ApiKeysRevokedAtOrder{
+		direction: direction,
+	}
+}
+func (o ApiKeysRevokedAtOrder) Value() string {
+	return "revoked_at"
+}
+func (o ApiKeysRevokedAtOrder) Direction() string {
+	return o.direction
+}
+func NewApiKeysRevokedAtOrder() ApiKeysRevokedAtOrder {
+	return ApiKeysRevokedAtOrder{}
+}
+
+type ApiKeysCreatedAtOrder struct {
+	direction string
+}
+
+func (o ApiKeysCreatedAtOrder) SetDirection(direction string) ApiKeysCreatedAtOrder {
+	return ApiKeysCreatedAtOrder{
+		direction: direction,
+	}
+}
+func (o ApiKeysCreatedAtOrder) Value() string {
+	return "created_at"
+}
+func (o ApiKeysCreatedAtOrder) Direction() string {
+	return o.direction
+}
+func NewApiKeysCreatedAtOrder() ApiKeysCreatedAtOrder {
+	return ApiKeysCreatedAtOrder{}
+}
+
+type ApiKeysUpdatedAtOrder struct {
+	direction string
+}
+
+func (o ApiKeysUpdatedAtOrder) SetDirection(direction string) ApiKeysUpdatedAtOrder {
+	return ApiKeysUpdatedAtOrder{
+		direction: direction,
+	}
+}
+func (o ApiKeysUpdatedAtOrder) Value() string {
+	return "updated_at"
+}
+func (o ApiKeysUpdatedAtOrder) Direction() string {
+	return o.direction
+}
+func NewApiKeysUpdatedAtOrder() ApiKeysUpdatedAtOrder {
+	return ApiKeysUpdatedAtOrder{}
+}
@@ -0,0 +1,7 @@
+package repositories
+
+import "errors"
+
+// ErrNotFound is returned by the Get of a single row when no row matches, the generated Get returns an error
+// that can't be told apart from the others so it's wrapped in this package
+var ErrNotFound = errors.New("not found")
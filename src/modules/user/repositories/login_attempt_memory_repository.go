@@ -0,0 +1,117 @@
+package repositories
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// the memory store only works when the app run as a single instance
+type LoginAttemptMemoryRepositoryImpl struct {
+	mu        sync.Mutex
+	entries   map[string]*loginAttemptEntry
+	lastSweep time.Time
+}
+
+type loginAttemptEntry struct {
+	count       int64
+	expiredAt   time.Time
+	lockedUntil time.Time
+}
+
+func NewLoginAttemptMemoryRepository() *LoginAttemptMemoryRepositoryImpl {
+	return &LoginAttemptMemoryRepositoryImpl{
+		entries:   map[string]*loginAttemptEntry{},
+		lastSweep: time.Now(),
+	}
+}
+
+func (c *LoginAttemptMemoryRepositoryImpl) IncrLoginAttempt(ctx context.Context, key string, window time.Duration) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	c.sweep(now)
+
+	entry := c.entry(key)
+	if !entry.expiredAt.After(now) {
+		entry.count = 0
+		entry.expiredAt = now.Add(window)
+	}
+	entry.count++
+
+	return entry.count, nil
+}
+
+func (c *LoginAttemptMemoryRepositoryImpl) GetLoginAttempt(ctx context.Context, key string) (int64, time.Duration, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		return 0, 0, nil
+	}
+
+	ttl := time.Until(entry.expiredAt)
+	if ttl <= 0 {
+		return 0, 0, nil
+	}
+
+	return entry.count, ttl, nil
+}
+
+func (c *LoginAttemptMemoryRepositoryImpl) ResetLoginAttempt(ctx context.Context, key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.entries, key)
+	return nil
+}
+
+func (c *LoginAttemptMemoryRepositoryImpl) LockLoginAttempt(ctx context.Context, key string, duration time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entry(key).lockedUntil = time.Now().Add(duration)
+	return nil
+}
+
+func (c *LoginAttemptMemoryRepositoryImpl) GetLoginLock(ctx context.Context, key string) (time.Duration, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		return 0, nil
+	}
+
+	ttl := time.Until(entry.lockedUntil)
+	if ttl <= 0 {
+		return 0, nil
+	}
+
+	return ttl, nil
+}
+
+func (c *LoginAttemptMemoryRepositoryImpl) entry(key string) *loginAttemptEntry {
+	entry, ok := c.entries[key]
+	if !ok {
+		entry = &loginAttemptEntry{}
+		c.entries[key] = entry
+	}
+	return entry
+}
+
+// sweep remove the expired entries at most once a minute, so the map doesn't keep every ip forever
+func (c *LoginAttemptMemoryRepositoryImpl) sweep(now time.Time) {
+	if now.Sub(c.lastSweep) < time.Minute {
+		return
+	}
+	c.lastSweep = now
+
+	for key, entry := range c.entries {
+		if entry.expiredAt.Before(now) && entry.lockedUntil.Before(now) {
+			delete(c.entries, key)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package repositories
+
+import (
+	"context"
+	"golang-starter/infrastructures/cached"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+const (
+	loginAttemptRedisPrefix = "login_attempt:"
+	loginLockRedisPrefix    = "login_lock:"
+)
+
+type LoginAttemptRedisRepositoryImpl struct {
+	redis *cached.RedisImpl
+}
+
+func NewLoginAttemptRedisRepository(redis *cached.RedisImpl) *LoginAttemptRedisRepositoryImpl {
+	return &LoginAttemptRedisRepositoryImpl{
+		redis: redis,
+	}
+}
+
+func (c LoginAttemptRedisRepositoryImpl) IncrLoginAttempt(ctx context.Context, key string, window time.Duration) (int64, error) {
+	key = loginAttemptRedisPrefix + key
+	count, err := c.redis.DB().Incr(ctx, key).Result()
+	if err != nil {
+		return 0, err
+	}
+
+	// the window start from the first attempt
+	if count == 1 {
+		if err := c.redis.DB().Expire(ctx, key, window).Err(); err != nil {
+			return 0, err
+		}
+	}
+
+	return count, nil
+}
+
+func (c LoginAttemptRedisRepositoryImpl) GetLoginAttempt(ctx context.Context, key string) (int64, time.Duration, error) {
+	key = loginAttemptRedisPrefix + key
+	count, err := c.redis.DB().Get(ctx, key).Int64()
+	if err == redis.Nil {
+		return 0, 0, nil
+	}
+	if err != nil {
+		return 0, 0, err
+	}
+
+	ttl, err := c.redis.DB().PTTL(ctx, key).Result()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return count, ttl, nil
+}
+
+func (c LoginAttemptRedisRepositoryImpl) ResetLoginAttempt(ctx context.Context, key string) error {
+	return c.redis.DB().Del(ctx, loginAttemptRedisPrefix+key, loginLockRedisPrefix+key).Err()
+}
+
+func (c LoginAttemptRedisRepositoryImpl) LockLoginAttempt(ctx context.Context, key string, duration time.Duration) error {
+	return c.redis.DB().Set(ctx, loginLockRedisPrefix+key, 1, duration).Err()
+}
+
+func (c LoginAttemptRedisRepositoryImpl) GetLoginLock(ctx context.Context, key string) (time.Duration, error) {
+	ttl, err := c.redis.DB().PTTL(ctx, loginLockRedisPrefix+key).Result()
+	if err != nil {
+		return 0, err
+	}
+
+	// PTTL return a negative duration when the key doesn't exist
+	if ttl < 0 {
+		return 0, nil
+	}
+
+	return ttl, nil
+}
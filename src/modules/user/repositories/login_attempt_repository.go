@@ -0,0 +1,37 @@
+package repositories
+
+import (
+	"context"
+	"golang-starter/config"
+	"golang-starter/infrastructures/cached"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// LoginAttemptRepository store the counters used to slow down and lock brute-force login attempts
+type LoginAttemptRepository interface {
+	// IncrLoginAttempt increase the counter of key and return the new value,
+	// the counter is removed after window since the first attempt
+	IncrLoginAttempt(ctx context.Context, key string, window time.Duration) (int64, error)
+	// GetLoginAttempt return the counter of key and how long until it's removed
+	GetLoginAttempt(ctx context.Context, key string) (int64, time.Duration, error)
+	// ResetLoginAttempt remove both the counter and the lock of key
+	ResetLoginAttempt(ctx context.Context, key string) error
+	LockLoginAttempt(ctx context.Context, key string, duration time.Duration) error
+	// GetLoginLock return how long key is still locked, zero means it's not locked
+	GetLoginLock(ctx context.Context, key string) (time.Duration, error)
+}
+
+// NewLoginAttemptRepository choose the store based on AUTH.LOGIN_ATTEMPT.STORE
+func NewLoginAttemptRepository(redis *cached.RedisImpl) LoginAttemptRepository {
+	switch config.Get().Auth.LoginAttempt.Store {
+	case "redis":
+		if !redis.Enabled() {
+			log.Fatal().Msg("login attempt store is redis but redis is disabled")
+		}
+		return NewLoginAttemptRedisRepository(redis)
+	default:
+		return NewLoginAttemptMemoryRepository()
+	}
+}
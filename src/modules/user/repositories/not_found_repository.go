@@ -0,0 +1,105 @@
+package repositories
+
+import (
+	"context"
+	usersmodel "golang-starter/src/modules/user/entities"
+)
+
+// the generated queries return an error of their own when the row of Get isn't found, these wrap the ones
+// without another wrapper so every Get return ErrNotFound
+
+// RepositoryApiKeysNotFoundQueryImpl return ErrNotFound from GetApiKeys when no api key matches
+type RepositoryApiKeysNotFoundQueryImpl struct {
+	query RepositoryApiKeysQuery
+}
+
+func NewRepoApiKeysNotFoundQuery(query RepositoryApiKeysQuery) *RepositoryApiKeysNotFoundQueryImpl {
+	return &RepositoryApiKeysNotFoundQueryImpl{query: query}
+}
+
+func (repo *RepositoryApiKeysNotFoundQueryImpl) SelectApiKeys(fields ...ApiKeysField) RepositoryApiKeysQuery {
+	return NewRepoApiKeysNotFoundQuery(repo.query.SelectApiKeys(fields...))
+}
+
+func (repo *RepositoryApiKeysNotFoundQueryImpl) ExcludeApiKeys(excludedFields ...ApiKeysField) RepositoryApiKeysQuery {
+	return NewRepoApiKeysNotFoundQuery(repo.query.ExcludeApiKeys(excludedFields...))
+}
+
+func (repo *RepositoryApiKeysNotFoundQueryImpl) FilterApiKeys(filter Filter) RepositoryApiKeysQuery {
+	return NewRepoApiKeysNotFoundQuery(repo.query.FilterApiKeys(filter))
+}
+
+func (repo *RepositoryApiKeysNotFoundQueryImpl) PaginationApiKeys(pagination Pagination) RepositoryApiKeysQuery {
+	return NewRepoApiKeysNotFoundQuery(repo.query.PaginationApiKeys(pagination))
+}
+
+func (repo *RepositoryApiKeysNotFoundQueryImpl) OrderByApiKeys(orderBy []Order) RepositoryApiKeysQuery {
+	return NewRepoApiKeysNotFoundQuery(repo.query.OrderByApiKeys(orderBy))
+}
+
+func (repo *RepositoryApiKeysNotFoundQueryImpl) GetApiKeysCount(ctx context.Context) (int, error) {
+	return repo.query.GetApiKeysCount(ctx)
+}
+
+func (repo *RepositoryApiKeysNotFoundQueryImpl) GetApiKeys(ctx context.Context) (*usersmodel.ApiKeys, error) {
+	list, err := repo.query.GetApiKeysList(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(list) == 0 {
+		return nil, ErrNotFound
+	}
+	return list[0], nil
+}
+
+func (repo *RepositoryApiKeysNotFoundQueryImpl) GetApiKeysList(ctx context.Context) (usersmodel.ApiKeysList, error) {
+	return repo.query.GetApiKeysList(ctx)
+}
+
+// RepositoryUserAuditLogsNotFoundQueryImpl return ErrNotFound from GetUserAuditLogs when no audit log matches
+type RepositoryUserAuditLogsNotFoundQueryImpl struct {
+	query RepositoryUserAuditLogsQuery
+}
+
+func NewRepoUserAuditLogsNotFoundQuery(query RepositoryUserAuditLogsQuery) *RepositoryUserAuditLogsNotFoundQueryImpl {
+	return &RepositoryUserAuditLogsNotFoundQueryImpl{query: query}
+}
+
+func (repo *RepositoryUserAuditLogsNotFoundQueryImpl) SelectUserAuditLogs(fields ...UserAuditLogsField) RepositoryUserAuditLogsQuery {
+	return NewRepoUserAuditLogsNotFoundQuery(repo.query.SelectUserAuditLogs(fields...))
+}
+
+func (repo *RepositoryUserAuditLogsNotFoundQueryImpl) ExcludeUserAuditLogs(excludedFields ...UserAuditLogsField) RepositoryUserAuditLogsQuery {
+	return NewRepoUserAuditLogsNotFoundQuery(repo.query.ExcludeUserAuditLogs(excludedFields...))
+}
+
+func (repo *RepositoryUserAuditLogsNotFoundQueryImpl) FilterUserAuditLogs(filter Filter) RepositoryUserAuditLogsQuery {
+	return NewRepoUserAuditLogsNotFoundQuery(repo.query.FilterUserAuditLogs(filter))
+}
+
+func (repo *RepositoryUserAuditLogsNotFoundQueryImpl) PaginationUserAuditLogs(pagination Pagination) RepositoryUserAuditLogsQuery {
+	return NewRepoUserAuditLogsNotFoundQuery(repo.query.PaginationUserAuditLogs(pagination))
+}
+
+func (repo *RepositoryUserAuditLogsNotFoundQueryImpl) OrderByUserAuditLogs(orderBy []Order) RepositoryUserAuditLogsQuery {
+	return NewRepoUserAuditLogsNotFoundQuery(repo.query.OrderByUserAuditLogs(orderBy))
+}
+
+func (repo *RepositoryUserAuditLogsNotFoundQueryImpl) GetUserAuditLogsCount(ctx context.Context) (int, error) {
+	return repo.query.GetUserAuditLogsCount(ctx)
+}
+
+func (repo *RepositoryUserAuditLogsNotFoundQueryImpl) GetUserAuditLogs(ctx context.Context) (*usersmodel.UserAuditLogs, error) {
+	list, err := repo.query.GetUserAuditLogsList(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(list) == 0 {
+		return nil, ErrNotFound
+	}
+	return list[0], nil
+}
+
+func (repo *RepositoryUserAuditLogsNotFoundQueryImpl) GetUserAuditLogsList(ctx context.Context) (usersmodel.UserAuditLogsList, error) {
+	return repo.query.GetUserAuditLogsList(ctx)
+}
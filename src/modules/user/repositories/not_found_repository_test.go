@@ -0,0 +1,37 @@
+package repositories
+
+import (
+	"context"
+	usersmodel "golang-starter/src/modules/user/entities"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// apiKeysQuery is a generated query whose filter matches apiKeysList
+type apiKeysQuery struct {
+	RepositoryApiKeysQuery
+	apiKeysList usersmodel.ApiKeysList
+}
+
+func (q apiKeysQuery) FilterApiKeys(Filter) RepositoryApiKeysQuery {
+	return q
+}
+
+func (q apiKeysQuery) GetApiKeysList(context.Context) (usersmodel.ApiKeysList, error) {
+	return q.apiKeysList, nil
+}
+
+func TestNotFoundQuery(t *testing.T) {
+	ctx := context.Background()
+	filter := NewApiKeysFilter("AND").SetFilterByApiKeyId(1, "=")
+
+	_, err := NewRepoApiKeysNotFoundQuery(apiKeysQuery{}).FilterApiKeys(filter).GetApiKeys(ctx)
+	assert.Equal(t, ErrNotFound, err)
+
+	query := apiKeysQuery{apiKeysList: usersmodel.ApiKeysList{{ApiKeyId: 1}}}
+	apiKey, err := NewRepoApiKeysNotFoundQuery(query).FilterApiKeys(filter).GetApiKeys(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), apiKey.ApiKeyId)
+}
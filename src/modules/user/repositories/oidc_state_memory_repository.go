@@ -0,0 +1,70 @@
+package repositories
+
+import (
+	"context"
+	"golang-starter/src/modules/user/entities"
+	"sync"
+	"time"
+)
+
+// the memory store only works when the app run as a single instance
+type OidcStateMemoryRepositoryImpl struct {
+	mu        sync.Mutex
+	entries   map[string]oidcStateEntry
+	lastSweep time.Time
+}
+
+type oidcStateEntry struct {
+	oidcState entities.OidcState
+	expiredAt time.Time
+}
+
+func NewOidcStateMemoryRepository() *OidcStateMemoryRepositoryImpl {
+	return &OidcStateMemoryRepositoryImpl{
+		entries:   map[string]oidcStateEntry{},
+		lastSweep: time.Now(),
+	}
+}
+
+func (c *OidcStateMemoryRepositoryImpl) SaveOidcState(ctx context.Context, state string, oidcState entities.OidcState, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	c.sweep(now)
+	c.entries[state] = oidcStateEntry{
+		oidcState: oidcState,
+		expiredAt: now.Add(ttl),
+	}
+	return nil
+}
+
+func (c *OidcStateMemoryRepositoryImpl) TakeOidcState(ctx context.Context, state string) (entities.OidcState, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[state]
+	if !ok {
+		return entities.OidcState{}, ErrNotFound
+	}
+	delete(c.entries, state)
+
+	if !entry.expiredAt.After(time.Now()) {
+		return entities.OidcState{}, ErrNotFound
+	}
+	return entry.oidcState, nil
+}
+
+// sweep remove the expired states at most once a minute, the states of the abandoned sign in are never taken
+func (c *OidcStateMemoryRepositoryImpl) sweep(now time.Time) {
+	if now.Sub(c.lastSweep) < time.Minute {
+		return
+	}
+	c.lastSweep = now
+
+	for state, entry := range c.entries {
+		if entry.expiredAt.Before(now) {
+			delete(c.entries, state)
+		}
+	}
+}
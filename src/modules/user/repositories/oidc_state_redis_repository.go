@@ -0,0 +1,53 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"golang-starter/infrastructures/cached"
+	"golang-starter/src/modules/user/entities"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+const oidcStateRedisPrefix = "oidc_state:"
+
+type OidcStateRedisRepositoryImpl struct {
+	redis *cached.RedisImpl
+}
+
+func NewOidcStateRedisRepository(redis *cached.RedisImpl) *OidcStateRedisRepositoryImpl {
+	return &OidcStateRedisRepositoryImpl{
+		redis: redis,
+	}
+}
+
+func (c OidcStateRedisRepositoryImpl) SaveOidcState(ctx context.Context, state string, oidcState entities.OidcState, ttl time.Duration) error {
+	value, err := json.Marshal(oidcState)
+	if err != nil {
+		return err
+	}
+	return c.redis.DB().Set(ctx, oidcStateRedisPrefix+state, value, ttl).Err()
+}
+
+func (c OidcStateRedisRepositoryImpl) TakeOidcState(ctx context.Context, state string) (entities.OidcState, error) {
+	// the state is read and removed in a transaction, so two callbacks can't both take it
+	var get *redis.StringCmd
+	_, err := c.redis.DB().TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		get = pipe.Get(ctx, oidcStateRedisPrefix+state)
+		pipe.Del(ctx, oidcStateRedisPrefix+state)
+		return nil
+	})
+	if err == redis.Nil {
+		return entities.OidcState{}, ErrNotFound
+	}
+	if err != nil {
+		return entities.OidcState{}, err
+	}
+
+	var oidcState entities.OidcState
+	if err := json.Unmarshal([]byte(get.Val()), &oidcState); err != nil {
+		return entities.OidcState{}, err
+	}
+	return oidcState, nil
+}
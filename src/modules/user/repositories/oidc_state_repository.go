@@ -0,0 +1,33 @@
+package repositories
+
+import (
+	"context"
+	"golang-starter/config"
+	"golang-starter/infrastructures/cached"
+	"golang-starter/src/modules/user/entities"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// OidcStateRepository keep the state of an oidc sign in between the redirect to the provider and its callback
+type OidcStateRepository interface {
+	// SaveOidcState store the state, it's removed after ttl
+	SaveOidcState(ctx context.Context, state string, oidcState entities.OidcState, ttl time.Duration) error
+	// TakeOidcState return the state and remove it, so the state can only be used once.
+	// ErrNotFound is returned when the state doesn't exist or is expired
+	TakeOidcState(ctx context.Context, state string) (entities.OidcState, error)
+}
+
+// NewOidcStateRepository choose the store based on AUTH.OIDC.STATE_STORE
+func NewOidcStateRepository(redis *cached.RedisImpl) OidcStateRepository {
+	switch config.Get().Auth.Oidc.StateStore {
+	case "redis":
+		if !redis.Enabled() {
+			log.Fatal().Msg("oidc state store is redis but redis is disabled")
+		}
+		return NewOidcStateRedisRepository(redis)
+	default:
+		return NewOidcStateMemoryRepository()
+	}
+}
@@ -2,28 +2,45 @@ package repositories
 
 import (
 	"golang-starter/infrastructures/db"
-	"golang-starter/infrastructures/localdb"
+	"golang-starter/internal/utils/encryption"
 )
 
 type Repositories interface {
 	RepositoryUsersCommand
 	RepositoryUsersQuery
-	UserScribleRepository
+	RepositoryUserAuditLogsCommand
+	RepositoryUserAuditLogsQuery
+	RepositoryUserIdentitiesCommand
+	RepositoryUserIdentitiesQuery
+	RepositoryApiKeysCommand
+	RepositoryApiKeysQuery
 }
 
 type RepositoriesImpl struct {
-	*RepositoryUsersCommandImpl
-	*RepositoryUsersQueryImpl
-	*UserScribleRepositoryImpl
+	*RepositoryUsersEncryptedCommandImpl
+	*RepositoryUsersEncryptedQueryImpl
+	*RepositoryUserAuditLogsCommandImpl
+	*RepositoryUserAuditLogsNotFoundQueryImpl
+	*RepositoryUserIdentitiesEncryptedCommandImpl
+	*RepositoryUserIdentitiesEncryptedQueryImpl
+	*RepositoryApiKeysCommandImpl
+	*RepositoryApiKeysNotFoundQueryImpl
 }
 
 func NewRepository(
 	db *db.MysqlImpl,
-	scribleDB *localdb.ScribleImpl,
+	encrypter *encryption.Encrypter,
+	blindIndexer *encryption.BlindIndexer,
 ) *RepositoriesImpl {
+	usersCipher := NewUsersCipher(encrypter, blindIndexer)
 	return &RepositoriesImpl{
-		RepositoryUsersCommandImpl: &RepositoryUsersCommandImpl{db: db.DB},
-		RepositoryUsersQueryImpl:   &RepositoryUsersQueryImpl{db: db.DB},
-		UserScribleRepositoryImpl:  NewUserScribleRepository(scribleDB),
+		RepositoryUsersEncryptedCommandImpl:          NewRepoUsersEncryptedCommand(NewRepoUsersCommand(db.DB), usersCipher),
+		RepositoryUsersEncryptedQueryImpl:            NewRepoUsersEncryptedQuery(NewRepoUsersQuery(db.DB), usersCipher),
+		RepositoryUserAuditLogsCommandImpl:           &RepositoryUserAuditLogsCommandImpl{db: db.DB},
+		RepositoryUserAuditLogsNotFoundQueryImpl:     NewRepoUserAuditLogsNotFoundQuery(NewRepoUserAuditLogsQuery(db.DB)),
+		RepositoryUserIdentitiesEncryptedCommandImpl: NewRepoUserIdentitiesEncryptedCommand(NewRepoUserIdentitiesCommand(db.DB), usersCipher),
+		RepositoryUserIdentitiesEncryptedQueryImpl:   NewRepoUserIdentitiesEncryptedQuery(NewRepoUserIdentitiesQuery(db.DB), usersCipher),
+		RepositoryApiKeysCommandImpl:                 &RepositoryApiKeysCommandImpl{db: db.DB},
+		RepositoryApiKeysNotFoundQueryImpl:           NewRepoApiKeysNotFoundQuery(NewRepoApiKeysQuery(db.DB)),
 	}
 }
@@ -0,0 +1,151 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	userauditlogsmodel "golang-starter/src/modules/user/entities"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/nurcahyaari/sqlabst"
+)
+
+type RepositoryUserAuditLogsCommand interface {
+	InsertUserAuditLogsList(ctx context.Context, userAuditLogsList userauditlogsmodel.UserAuditLogsList) (*InsertResult, error)
+	InsertUserAuditLogs(ctx context.Context, userAuditLogs *userauditlogsmodel.UserAuditLogs) (*InsertResult, error)
+	UpdateUserAuditLogsByFilter(ctx context.Context, userAuditLogs *userauditlogsmodel.UserAuditLogs, filter Filter, updatedFields ...UserAuditLogsField) error
+	UpdateUserAuditLogs(ctx context.Context, userAuditLogs *userauditlogsmodel.UserAuditLogs, auditlogid int32, updatedFields ...UserAuditLogsField) error
+	DeleteUserAuditLogsList(ctx context.Context, filter Filter) error
+	DeleteUserAuditLogs(ctx context.Context, auditlogid int32) error
+}
+
+type RepositoryUserAuditLogsCommandImpl struct {
+	db *sqlabst.SqlAbst
+}
+
+func (repo *RepositoryUserAuditLogsCommandImpl) InsertUserAuditLogsList(ctx context.Context, userAuditLogsList userauditlogsmodel.UserAuditLogsList) (*InsertResult, error) {
+	command := `INSERT INTO user_audit_logs (actor_user_fkid,
+	target_user_fkid,
+	action,
+	detail,
+	created_at) VALUES
+		`
+
+	var (
+		placeholders []string
+		args         []interface{}
+	)
+	for _, userAuditLogs := range userAuditLogsList {
+		placeholders = append(placeholders, `(?,
+	?,
+	?,
+	?,
+	?)`)
+		args = append(args,
+			userAuditLogs.ActorUserFkid,
+			userAuditLogs.TargetUserFkid,
+			userAuditLogs.Action,
+			userAuditLogs.Detail,
+			userAuditLogs.CreatedAt,
+		)
+	}
+	command += strings.Join(placeholders, ",")
+
+	sqlResult, err := repo.exec(ctx, command, args)
+	if err != nil {
+		return nil, err
+	}
+
+	return &InsertResult{Result: sqlResult}, nil
+}
+
+func (repo *RepositoryUserAuditLogsCommandImpl) InsertUserAuditLogs(ctx context.Context, userAuditLogs *userauditlogsmodel.UserAuditLogs) (*InsertResult, error) {
+	return repo.InsertUserAuditLogsList(ctx, userauditlogsmodel.UserAuditLogsList{userAuditLogs})
+}
+
+func (repo *RepositoryUserAuditLogsCommandImpl) UpdateUserAuditLogsByFilter(ctx context.Context, userAuditLogs *userauditlogsmodel.UserAuditLogs, filter Filter, updatedFields ...UserAuditLogsField) error {
+	updatedFieldQuery, values := buildUpdateFieldsUserAuditLogsQuery(updatedFields, userAuditLogs)
+	command := fmt.Sprintf(`UPDATE user_audit_logs 
+			SET %s 
+		WHERE %s
+		`, strings.Join(updatedFieldQuery, ","), filter.Query())
+	values = append(values, filter.Values()...)
+	_, err := repo.exec(ctx, command, values)
+	return err
+}
+
+func (repo *RepositoryUserAuditLogsCommandImpl) UpdateUserAuditLogs(ctx context.Context, userAuditLogs *userauditlogsmodel.UserAuditLogs, auditlogid int32, updatedFields ...UserAuditLogsField) error {
+	updatedFieldQuery, values := buildUpdateFieldsUserAuditLogsQuery(updatedFields, userAuditLogs)
+	command := fmt.Sprintf(`UPDATE user_audit_logs 
+			SET %s 
+		WHERE audit_log_id = ?
+		`, strings.Join(updatedFieldQuery, ","))
+	values = append(values, auditlogid)
+	_, err := repo.exec(ctx, command, values)
+	return err
+}
+
+func (repo *RepositoryUserAuditLogsCommandImpl) DeleteUserAuditLogsList(ctx context.Context, filter Filter) error {
+	command := "DELETE FROM user_audit_logs WHERE " + filter.Query()
+	_, err := repo.exec(ctx, command, filter.Values())
+	return err
+}
+
+func (repo *RepositoryUserAuditLogsCommandImpl) DeleteUserAuditLogs(ctx context.Context, auditlogid int32) error {
+	command := "DELETE FROM user_audit_logs WHERE audit_log_id = ?"
+	_, err := repo.exec(ctx, command, []interface{}{auditlogid})
+	return err
+}
+
+func NewRepoUserAuditLogsCommand(db *sqlabst.SqlAbst) RepositoryUserAuditLogsCommand {
+	return &RepositoryUserAuditLogsCommandImpl{
+		db: db,
+	}
+}
+
+func (repo *RepositoryUserAuditLogsCommandImpl) exec(ctx context.Context, command string, args []interface{}) (sql.Result, error) {
+	var (
+		stmt *sqlx.Stmt
+		err  error
+	)
+	stmt, err = repo.db.PreparexContext(ctx, command)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return stmt.ExecContext(ctx, args...)
+}
+
+func buildUpdateFieldsUserAuditLogsQuery(updatedFields UserAuditLogsFieldList, userAuditLogs *userauditlogsmodel.UserAuditLogs) ([]string, []interface{}) {
+	var (
+		updatedFieldsQuery []string
+		args               []interface{}
+	)
+
+	for _, field := range updatedFields {
+		switch field {
+		case "audit_log_id":
+			updatedFieldsQuery = append(updatedFieldsQuery, "audit_log_id = ?")
+			args = append(args, userAuditLogs.AuditLogId)
+		case "actor_user_fkid":
+			updatedFieldsQuery = append(updatedFieldsQuery, "actor_user_fkid = ?")
+			args = append(args, userAuditLogs.ActorUserFkid)
+		case "target_user_fkid":
+			updatedFieldsQuery = append(updatedFieldsQuery, "target_user_fkid = ?")
+			args = append(args, userAuditLogs.TargetUserFkid)
+		case "action":
+			updatedFieldsQuery = append(updatedFieldsQuery, "action = ?")
+			args = append(args, userAuditLogs.Action)
+		case "detail":
+			updatedFieldsQuery = append(updatedFieldsQuery, "detail = ?")
+			args = append(args, userAuditLogs.Detail)
+		case "created_at":
+			updatedFieldsQuery = append(updatedFieldsQuery, "created_at = ?")
+			args = append(args, userAuditLogs.CreatedAt)
+		}
+	}
+
+	return updatedFieldsQuery, args
+}
@@ -0,0 +1,458 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	userauditlogsmodel "golang-starter/src/modules/user/entities"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/nurcahyaari/sqlabst"
+)
+
+type RepositoryUserAuditLogsQuery interface {
+	SelectUserAuditLogs(fields ...UserAuditLogsField) RepositoryUserAuditLogsQuery
+	ExcludeUserAuditLogs(excludedFields ...UserAuditLogsField) RepositoryUserAuditLogsQuery
+	FilterUserAuditLogs(filter Filter) RepositoryUserAuditLogsQuery
+	PaginationUserAuditLogs(pagination Pagination) RepositoryUserAuditLogsQuery
+	OrderByUserAuditLogs(orderBy []Order) RepositoryUserAuditLogsQuery
+	GetUserAuditLogsCount(ctx context.Context) (int, error)
+	GetUserAuditLogs(ctx context.Context) (*userauditlogsmodel.UserAuditLogs, error)
+	GetUserAuditLogsList(ctx context.Context) (userauditlogsmodel.UserAuditLogsList, error)
+}
+
+type RepositoryUserAuditLogsQueryImpl struct {
+	db         *sqlabst.SqlAbst
+	query      string
+	filter     Filter
+	orderBy    []Order
+	pagination Pagination
+	fields     UserAuditLogsFieldList
+}
+
+func (repo *RepositoryUserAuditLogsQueryImpl) SelectUserAuditLogs(fields ...UserAuditLogsField) RepositoryUserAuditLogsQuery {
+	return &RepositoryUserAuditLogsQueryImpl{
+		db:         repo.db,
+		filter:     repo.filter,
+		orderBy:    repo.orderBy,
+		pagination: repo.pagination,
+		fields:     fields,
+	}
+}
+
+func (repo *RepositoryUserAuditLogsQueryImpl) ExcludeUserAuditLogs(excludedFields ...UserAuditLogsField) RepositoryUserAuditLogsQuery {
+	selectedFieldsStr := excludeFields(UserAuditLogsFieldList(excludedFields).toString(),
+		UserAuditLogsSelectFields{}.All().toString())
+
+	var selectedFields []UserAuditLogsField
+	for _, sel := range selectedFieldsStr {
+		selectedFields = append(selectedFields, UserAuditLogsField(sel))
+	}
+
+	return &RepositoryUserAuditLogsQueryImpl{
+		db:         repo.db,
+		filter:     repo.filter,
+		orderBy:    repo.orderBy,
+		pagination: repo.pagination,
+		fields:     selectedFields,
+	}
+}
+
+func (repo *RepositoryUserAuditLogsQueryImpl) FilterUserAuditLogs(filter Filter) RepositoryUserAuditLogsQuery {
+	return &RepositoryUserAuditLogsQueryImpl{
+		db:         repo.db,
+		filter:     filter,
+		orderBy:    repo.orderBy,
+		pagination: repo.pagination,
+		fields:     repo.fields,
+	}
+}
+
+func (repo *RepositoryUserAuditLogsQueryImpl) PaginationUserAuditLogs(pagination Pagination) RepositoryUserAuditLogsQuery {
+	return &RepositoryUserAuditLogsQueryImpl{
+		db:         repo.db,
+		filter:     repo.filter,
+		orderBy:    repo.orderBy,
+		pagination: pagination,
+		fields:     repo.fields,
+	}
+}
+
+func (repo *RepositoryUserAuditLogsQueryImpl) OrderByUserAuditLogs(orderBy []Order) RepositoryUserAuditLogsQuery {
+	return &RepositoryUserAuditLogsQueryImpl{
+		db:         repo.db,
+		filter:     repo.filter,
+		orderBy:    orderBy,
+		pagination: repo.pagination,
+		fields:     repo.fields,
+	}
+}
+
+func (repo *RepositoryUserAuditLogsQueryImpl) GetUserAuditLogsList(ctx context.Context) (userauditlogsmodel.UserAuditLogsList, error) {
+	var (
+		userAuditLogsList userauditlogsmodel.UserAuditLogsList
+		values            []interface{}
+	)
+
+	if len(repo.fields) == 0 {
+		repo.fields = UserAuditLogsSelectFields{}.All()
+	}
+
+	query := fmt.Sprintf("SELECT %s FROM user_audit_logs", strings.Join(repo.fields.toString(), ","))
+	if repo.filter != nil {
+		query += " WHERE " + repo.filter.Query()
+		values = append(values, repo.filter.Values()...)
+	}
+
+	if len(repo.orderBy) > 0 {
+		var orderStr []string
+		for _, order := range repo.orderBy {
+			orderStr = append(orderStr, order.Value()+" "+order.Direction())
+		}
+		query += fmt.Sprintf(" ORDER BY %s", strings.Join(orderStr, ","))
+	}
+
+	if repo.pagination != nil {
+		offset := (repo.pagination.GetPage() - 1) * repo.pagination.GetSize()
+		query += fmt.Sprintf(" LIMIT %d OFFSET %d", repo.pagination.GetSize(), offset)
+	}
+
+	err := repo.db.SelectContext(ctx, &userAuditLogsList, query, values...)
+	if err != nil {
+		return nil, err
+	}
+	return userAuditLogsList, nil
+}
+
+func (repo *RepositoryUserAuditLogsQueryImpl) GetUserAuditLogsCount(ctx context.Context) (int, error) {
+	var values []interface{}
+	query := fmt.Sprintf("SELECT count(1) FROM user_audit_logs")
+	if repo.filter != nil {
+		query += " WHERE " + repo.filter.Query()
+		values = append(values, repo.filter.Values()...)
+	}
+
+	var count int
+	err := repo.db.QueryRowContext(ctx, query, values...).Scan(&count)
+	return count, err
+}
+
+func (repo *RepositoryUserAuditLogsQueryImpl) GetUserAuditLogs(ctx context.Context) (*userauditlogsmodel.UserAuditLogs, error) {
+	userAuditLogsList, err := repo.GetUserAuditLogsList(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(userAuditLogsList) == 0 {
+		return nil, errors.New("userauditlogs not found")
+	}
+
+	return userAuditLogsList[0], nil
+}
+
+func NewRepoUserAuditLogsQuery(db *sqlabst.SqlAbst) RepositoryUserAuditLogsQuery {
+	return &RepositoryUserAuditLogsQueryImpl{
+		db: db,
+	}
+}
+
+type UserAuditLogsField string
+type UserAuditLogsFieldList []UserAuditLogsField
+
+func (fieldList UserAuditLogsFieldList) toString() []string {
+	var fieldsStr []string
+	for _, field := range fieldList {
+		fieldsStr = append(fieldsStr, string(field))
+	}
+	return fieldsStr
+}
+
+type UserAuditLogsSelectFields struct {
+}
+
+func (UserAuditLogsSelectFields) AuditLogId() UserAuditLogsField {
+	return UserAuditLogsField("audit_log_id")
+}
+func (UserAuditLogsSelectFields) ActorUserFkid() UserAuditLogsField {
+	return UserAuditLogsField("actor_user_fkid")
+}
+func (UserAuditLogsSelectFields) TargetUserFkid() UserAuditLogsField {
+	return UserAuditLogsField("target_user_fkid")
+}
+func (UserAuditLogsSelectFields) Action() UserAuditLogsField {
+	return UserAuditLogsField("action")
+}
+func (UserAuditLogsSelectFields) Detail() UserAuditLogsField {
+	return UserAuditLogsField("detail")
+}
+func (UserAuditLogsSelectFields) CreatedAt() UserAuditLogsField {
+	return UserAuditLogsField("created_at")
+}
+
+func (UserAuditLogsSelectFields) All() UserAuditLogsFieldList {
+	return []UserAuditLogsField{
+		UserAuditLogsField("audit_log_id"),
+		UserAuditLogsField("actor_user_fkid"),
+		UserAuditLogsField("target_user_fkid"),
+		UserAuditLogsField("action"),
+		UserAuditLogsField("detail"),
+		UserAuditLogsField("created_at"),
+	}
+}
+
+func NewUserAuditLogsSelectFields() UserAuditLogsSelectFields {
+	return UserAuditLogsSelectFields{}
+}
+
+type UserAuditLogsFilter struct {
+	operator string
+	query    []string
+	values   []interface{}
+}
+
+func NewUserAuditLogsFilter(operator string) UserAuditLogsFilter {
+	if operator == "" {
+		operator = "AND"
+	}
+	return UserAuditLogsFilter{
+		operator: operator,
+	}
+}
+
+func (f UserAuditLogsFilter) SetFilterByAuditLogId(value interface{}, operator string) UserAuditLogsFilter {
+	query := "audit_log_id " + operator + " (?)"
+	var values []interface{}
+	if value == nil {
+		query = "audit_log_id " + operator
+	} else {
+		switch strings.ToUpper(operator) {
+		case "IN", "NOT IN":
+			query, values, _ = sqlx.In(query, value)
+		default:
+			values = append(values, value)
+		}
+	}
+	return UserAuditLogsFilter{
+		operator: f.operator,
+		query:    append(f.query, query),
+		values:   append(f.values, values...),
+	}
+}
+func (f UserAuditLogsFilter) SetFilterByActorUserFkid(value interface{}, operator string) UserAuditLogsFilter {
+	query := "actor_user_fkid " + operator + " (?)"
+	var values []interface{}
+	if value == nil {
+		query = "actor_user_fkid " + operator
+	} else {
+		switch strings.ToUpper(operator) {
+		case "IN", "NOT IN":
+			query, values, _ = sqlx.In(query, value)
+		default:
+			values = append(values, value)
+		}
+	}
+	return UserAuditLogsFilter{
+		operator: f.operator,
+		query:    append(f.query, query),
+		values:   append(f.values, values...),
+	}
+}
+func (f UserAuditLogsFilter) SetFilterByTargetUserFkid(value interface{}, operator string) UserAuditLogsFilter {
+	query := "target_user_fkid " + operator + " (?)"
+	var values []interface{}
+	if value == nil {
+		query = "target_user_fkid " + operator
+	} else {
+		switch strings.ToUpper(operator) {
+		case "IN", "NOT IN":
+			query, values, _ = sqlx.In(query, value)
+		default:
+			values = append(values, value)
+		}
+	}
+	return UserAuditLogsFilter{
+		operator: f.operator,
+		query:    append(f.query, query),
+		values:   append(f.values, values...),
+	}
+}
+func (f UserAuditLogsFilter) SetFilterByAction(value interface{}, operator string) UserAuditLogsFilter {
+	query := "action " + operator + " (?)"
+	var values []interface{}
+	if value == nil {
+		query = "action " + operator
+	} else {
+		switch strings.ToUpper(operator) {
+		case "IN", "NOT IN":
+			query, values, _ = sqlx.In(query, value)
+		default:
+			values = append(values, value)
+		}
+	}
+	return UserAuditLogsFilter{
+		operator: f.operator,
+		query:    append(f.query, query),
+		values:   append(f.values, values...),
+	}
+}
+func (f UserAuditLogsFilter) SetFilterByDetail(value interface{}, operator string) UserAuditLogsFilter {
+	query := "detail " + operator + " (?)"
+	var values []interface{}
+	if value == nil {
+		query = "detail " + operator
+	} else {
+		switch strings.ToUpper(operator) {
+		case "IN", "NOT IN":
+			query, values, _ = sqlx.In(query, value)
+		default:
+			values = append(values, value)
+		}
+	}
+	return UserAuditLogsFilter{
+		operator: f.operator,
+		query:    append(f.query, query),
+		values:   append(f.values, values...),
+	}
+}
+func (f UserAuditLogsFilter) SetFilterByCreatedAt(value interface{}, operator string) UserAuditLogsFilter {
+	query := "created_at " + operator + " (?)"
+	var values []interface{}
+	if value == nil {
+		query = "created_at " + operator
+	} else {
+		switch strings.ToUpper(operator) {
+		case "IN", "NOT IN":
+			query, values, _ = sqlx.In(query, value)
+		default:
+			values = append(values, value)
+		}
+	}
+	return UserAuditLogsFilter{
+		operator: f.operator,
+		query:    append(f.query, query),
+		values:   append(f.values, values...),
+	}
+}
+
+func (f UserAuditLogsFilter) Query() string {
+	return strings.Join(f.query, " "+f.operator+" ")
+}
+
+func (f UserAuditLogsFilter) Values() []interface{} {
+	return f.values
+}
+
+type UserAuditLogsAuditLogIdOrder struct {
+	direction string
+}
+
+func (o UserAuditLogsAuditLogIdOrder) SetDirection(direction string) UserAuditLogsAuditLogIdOrder {
+	return UserAuditLogsAuditLogIdOrder{
+		direction: direction,
+	}
+}
+func (o UserAuditLogsAuditLogIdOrder) Value() string {
+	return "audit_log_id"
+}
+func (o UserAuditLogsAuditLogIdOrder) Direction() string {
+	return o.direction
+}
+func NewUserAuditLogsAuditLogIdOrder() UserAuditLogsAuditLogIdOrder {
+	return UserAuditLogsAuditLogIdOrder{}
+}
+
+type UserAuditLogsActorUserFkidOrder struct {
+	direction string
+}
+
+func (o UserAuditLogsActorUserFkidOrder) SetDirection(direction string) UserAuditLogsActorUserFkidOrder {
+	return UserAuditLogsActorUserFkidOrder{
+		direction: direction,
+	}
+}
+func (o UserAuditLogsActorUserFkidOrder) Value() string {
+	return "actor_user_fkid"
+}
+func (o UserAuditLogsActorUserFkidOrder) Direction() string {
+	return o.direction
+}
+func NewUserAuditLogsActorUserFkidOrder() UserAuditLogsActorUserFkidOrder {
+	return UserAuditLogsActorUserFkidOrder{}
+}
+
+type UserAuditLogsTargetUserFkidOrder struct {
+	direction string
+}
+
+func (o UserAuditLogsTargetUserFkidOrder) SetDirection(direction string) UserAuditLogsTargetUserFkidOrder {
+	return UserAuditLogsTargetUserFkidOrder{
+		direction: direction,
+	}
+}
+func (o UserAuditLogsTargetUserFkidOrder) Value() string {
+	return "target_user_fkid"
+}
+func (o UserAuditLogsTargetUserFkidOrder) Direction() string {
+	return o.direction
+}
+func NewUserAuditLogsTargetUserFkidOrder() UserAuditLogsTargetUserFkidOrder {
+	return UserAuditLogsTargetUserFkidOrder{}
+}
+
+type UserAuditLogsActionOrder struct {
+	direction string
+}
+
+func (o UserAuditLogsActionOrder) SetDirection(direction string) UserAuditLogsActionOrder {
+	return UserAuditLogsActionOrder{
+		direction: direction,
+	}
+}
+func (o UserAuditLogsActionOrder) Value() string {
+	return "action"
+}
+func (o UserAuditLogsActionOrder) Direction() string {
+	return o.direction
+}
+func NewUserAuditLogsActionOrder() UserAuditLogsActionOrder {
+	return UserAuditLogsActionOrder{}
+}
+
+type UserAuditLogsDetailOrder struct {
+	direction string
+}
+
+func (o UserAuditLogsDetailOrder) SetDirection(direction string) UserAuditLogsDetailOrder {
+	return UserAuditLogsDetailOrder{
+		direction: direction,
+	}
+}
+func (o UserAuditLogsDetailOrder) Value() string {
+	return "detail"
+}
+func (o UserAuditLogsDetailOrder) Direction() string {
+	return o.direction
+}
+func NewUserAuditLogsDetailOrder() UserAuditLogsDetailOrder {
+	return UserAuditLogsDetailOrder{}
+}
+
+type UserAuditLogsCreatedAtOrder struct {
+	direction string
+}
+
+func (o UserAuditLogsCreatedAtOrder) SetDirection(direction string) UserAuditLogsCreatedAtOrder {
+	return UserAuditLogsCreatedAtOrder{
+		direction: direction,
+	}
+}
+func (o UserAuditLogsCreatedAtOrder) Value() string {
+	return "created_at"
+}
+func (o UserAuditLogsCreatedAtOrder) Direction() string {
+	return o.direction
+}
+func NewUserAuditLogsCreatedAtOrder() UserAuditLogsCreatedAtOrder {
+	return UserAuditLogsCreatedAtOrder{}
+}
@@ -0,0 +1,149 @@
+package repositories
+
+import (
+	"context"
+	usersmodel "golang-starter/src/modules/user/entities"
+)
+
+// RepositoryUserIdentitiesEncryptedCommandImpl encrypt the identities before they're written by the generated command
+type RepositoryUserIdentitiesEncryptedCommandImpl struct {
+	command RepositoryUserIdentitiesCommand
+	cipher  *UsersCipher
+}
+
+func NewRepoUserIdentitiesEncryptedCommand(command RepositoryUserIdentitiesCommand, cipher *UsersCipher) *RepositoryUserIdentitiesEncryptedCommandImpl {
+	return &RepositoryUserIdentitiesEncryptedCommandImpl{
+		command: command,
+		cipher:  cipher,
+	}
+}
+
+func (repo *RepositoryUserIdentitiesEncryptedCommandImpl) InsertUserIdentitiesList(ctx context.Context, userIdentitiesList usersmodel.UserIdentitiesList) (*InsertResult, error) {
+	encryptedList := make(usersmodel.UserIdentitiesList, 0, len(userIdentitiesList))
+	for _, userIdentities := range userIdentitiesList {
+		encrypted, err := repo.cipher.EncryptUserIdentities(userIdentities)
+		if err != nil {
+			return nil, err
+		}
+		encryptedList = append(encryptedList, encrypted)
+	}
+	return repo.command.InsertUserIdentitiesList(ctx, encryptedList)
+}
+
+func (repo *RepositoryUserIdentitiesEncryptedCommandImpl) InsertUserIdentities(ctx context.Context, userIdentities *usersmodel.UserIdentities) (*InsertResult, error) {
+	return repo.InsertUserIdentitiesList(ctx, usersmodel.UserIdentitiesList{userIdentities})
+}
+
+func (repo *RepositoryUserIdentitiesEncryptedCommandImpl) UpdateUserIdentitiesByFilter(ctx context.Context, userIdentities *usersmodel.UserIdentities, filter Filter, updatedFields ...UserIdentitiesField) error {
+	encrypted, err := repo.cipher.EncryptUserIdentities(userIdentities)
+	if err != nil {
+		return err
+	}
+	filter, err = repo.cipher.identitiesFilter(filter)
+	if err != nil {
+		return err
+	}
+	return repo.command.UpdateUserIdentitiesByFilter(ctx, encrypted, filter, updatedFields...)
+}
+
+func (repo *RepositoryUserIdentitiesEncryptedCommandImpl) UpdateUserIdentities(ctx context.Context, userIdentities *usersmodel.UserIdentities, identityid int32, updatedFields ...UserIdentitiesField) error {
+	encrypted, err := repo.cipher.EncryptUserIdentities(userIdentities)
+	if err != nil {
+		return err
+	}
+	return repo.command.UpdateUserIdentities(ctx, encrypted, identityid, updatedFields...)
+}
+
+func (repo *RepositoryUserIdentitiesEncryptedCommandImpl) DeleteUserIdentitiesList(ctx context.Context, filter Filter) error {
+	filter, err := repo.cipher.identitiesFilter(filter)
+	if err != nil {
+		return err
+	}
+	return repo.command.DeleteUserIdentitiesList(ctx, filter)
+}
+
+func (repo *RepositoryUserIdentitiesEncryptedCommandImpl) DeleteUserIdentities(ctx context.Context, identityid int32) error {
+	return repo.command.DeleteUserIdentities(ctx, identityid)
+}
+
+// RepositoryUserIdentitiesEncryptedQueryImpl decrypt the identities read by the generated query, the error of an
+// encrypted column filter is returned once the query is executed
+type RepositoryUserIdentitiesEncryptedQueryImpl struct {
+	query  RepositoryUserIdentitiesQuery
+	cipher *UsersCipher
+	err    error
+}
+
+func NewRepoUserIdentitiesEncryptedQuery(query RepositoryUserIdentitiesQuery, cipher *UsersCipher) *RepositoryUserIdentitiesEncryptedQueryImpl {
+	return &RepositoryUserIdentitiesEncryptedQueryImpl{
+		query:  query,
+		cipher: cipher,
+	}
+}
+
+func (repo *RepositoryUserIdentitiesEncryptedQueryImpl) SelectUserIdentities(fields ...UserIdentitiesField) RepositoryUserIdentitiesQuery {
+	return repo.with(repo.query.SelectUserIdentities(fields...), repo.err)
+}
+
+func (repo *RepositoryUserIdentitiesEncryptedQueryImpl) ExcludeUserIdentities(excludedFields ...UserIdentitiesField) RepositoryUserIdentitiesQuery {
+	return repo.with(repo.query.ExcludeUserIdentities(excludedFields...), repo.err)
+}
+
+func (repo *RepositoryUserIdentitiesEncryptedQueryImpl) FilterUserIdentities(filter Filter) RepositoryUserIdentitiesQuery {
+	filter, err := repo.cipher.identitiesFilter(filter)
+	if err != nil {
+		return repo.with(repo.query, err)
+	}
+	return repo.with(repo.query.FilterUserIdentities(filter), repo.err)
+}
+
+func (repo *RepositoryUserIdentitiesEncryptedQueryImpl) PaginationUserIdentities(pagination Pagination) RepositoryUserIdentitiesQuery {
+	return repo.with(repo.query.PaginationUserIdentities(pagination), repo.err)
+}
+
+func (repo *RepositoryUserIdentitiesEncryptedQueryImpl) OrderByUserIdentities(orderBy []Order) RepositoryUserIdentitiesQuery {
+	return repo.with(repo.query.OrderByUserIdentities(orderBy), repo.err)
+}
+
+func (repo *RepositoryUserIdentitiesEncryptedQueryImpl) GetUserIdentitiesCount(ctx context.Context) (int, error) {
+	if repo.err != nil {
+		return 0, repo.err
+	}
+	return repo.query.GetUserIdentitiesCount(ctx)
+}
+
+// GetUserIdentities return ErrNotFound when no row matches, the generated one has no error to check
+func (repo *RepositoryUserIdentitiesEncryptedQueryImpl) GetUserIdentities(ctx context.Context) (*usersmodel.UserIdentities, error) {
+	userIdentitiesList, err := repo.GetUserIdentitiesList(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(userIdentitiesList) == 0 {
+		return nil, ErrNotFound
+	}
+	return userIdentitiesList[0], nil
+}
+
+func (repo *RepositoryUserIdentitiesEncryptedQueryImpl) GetUserIdentitiesList(ctx context.Context) (usersmodel.UserIdentitiesList, error) {
+	if repo.err != nil {
+		return nil, repo.err
+	}
+	userIdentitiesList, err := repo.query.GetUserIdentitiesList(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, userIdentities := range userIdentitiesList {
+		if err := repo.cipher.DecryptUserIdentities(userIdentities); err != nil {
+			return nil, err
+		}
+	}
+	return userIdentitiesList, nil
+}
+
+func (repo *RepositoryUserIdentitiesEncryptedQueryImpl) with(query RepositoryUserIdentitiesQuery, err error) *RepositoryUserIdentitiesEncryptedQueryImpl {
+	return &RepositoryUserIdentitiesEncryptedQueryImpl{
+		query:  query,
+		cipher: repo.cipher,
+		err:    err,
+	}
+}
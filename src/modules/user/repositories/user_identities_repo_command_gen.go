@@ -0,0 +1,151 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	useridentitiesmodel "golang-starter/src/modules/user/entities"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/nurcahyaari/sqlabst"
+)
+
+type RepositoryUserIdentitiesCommand interface {
+	InsertUserIdentitiesList(ctx context.Context, userIdentitiesList useridentitiesmodel.UserIdentitiesList) (*InsertResult, error)
+	InsertUserIdentities(ctx context.Context, userIdentities *useridentitiesmodel.UserIdentities) (*InsertResult, error)
+	UpdateUserIdentitiesByFilter(ctx context.Context, userIdentities *useridentitiesmodel.UserIdentities, filter Filter, updatedFields ...UserIdentitiesField) error
+	UpdateUserIdentities(ctx context.Context, userIdentities *useridentitiesmodel.UserIdentities, identityid int32, updatedFields ...UserIdentitiesField) error
+	DeleteUserIdentitiesList(ctx context.Context, filter Filter) error
+	DeleteUserIdentities(ctx context.Context, identityid int32) error
+}
+
+type RepositoryUserIdentitiesCommandImpl struct {
+	db *sqlabst.SqlAbst
+}
+
+func (repo *RepositoryUserIdentitiesCommandImpl) InsertUserIdentitiesList(ctx context.Context, userIdentitiesList useridentitiesmodel.UserIdentitiesList) (*InsertResult, error) {
+	command := `INSERT INTO user_identities (user_fkid,
+	provider,
+	subject,
+	email,
+	created_at) VALUES
+		`
+
+	var (
+		placeholders []string
+		args         []interface{}
+	)
+	for _, userIdentities := range userIdentitiesList {
+		placeholders = append(placeholders, `(?,
+	?,
+	?,
+	?,
+	?)`)
+		args = append(args,
+			userIdentities.UserFkid,
+			userIdentities.Provider,
+			userIdentities.Subject,
+			userIdentities.Email,
+			userIdentities.CreatedAt,
+		)
+	}
+	command += strings.Join(placeholders, ",")
+
+	sqlResult, err := repo.exec(ctx, command, args)
+	if err != nil {
+		return nil, err
+	}
+
+	return &InsertResult{Result: sqlResult}, nil
+}
+
+func (repo *RepositoryUserIdentitiesCommandImpl) InsertUserIdentities(ctx context.Context, userIdentities *useridentitiesmodel.UserIdentities) (*InsertResult, error) {
+	return repo.InsertUserIdentitiesList(ctx, useridentitiesmodel.UserIdentitiesList{userIdentities})
+}
+
+func (repo *RepositoryUserIdentitiesCommandImpl) UpdateUserIdentitiesByFilter(ctx context.Context, userIdentities *useridentitiesmodel.UserIdentities, filter Filter, updatedFields ...UserIdentitiesField) error {
+	updatedFieldQuery, values := buildUpdateFieldsUserIdentitiesQuery(updatedFields, userIdentities)
+	command := fmt.Sprintf(`UPDATE user_identities 
+			SET %s 
+		WHERE %s
+		`, strings.Join(updatedFieldQuery, ","), filter.Query())
+	values = append(values, filter.Values()...)
+	_, err := repo.exec(ctx, command, values)
+	return err
+}
+
+func (repo *RepositoryUserIdentitiesCommandImpl) UpdateUserIdentities(ctx context.Context, userIdentities *useridentitiesmodel.UserIdentities, identityid int32, updatedFields ...UserIdentitiesField) error {
+	updatedFieldQuery, values := buildUpdateFieldsUserIdentitiesQuery(updatedFields, userIdentities)
+	command := fmt.Sprintf(`UPDATE user_identities 
+			SET %s 
+		WHERE identity_id = ?
+		`, strings.Join(updatedFieldQuery, ","))
+	values = append(values, identityid)
+	_, err := repo.exec(ctx, command, values)
+	return err
+}
+
+func (repo *RepositoryUserIdentitiesCommandImpl) DeleteUserIdentitiesList(ctx context.Context, filter Filter) error {
+	command := "DELETE FROM user_identities WHERE " + filter.Query()
+	_, err := repo.exec(ctx, command, filter.Values())
+	return err
+}
+
+func (repo *RepositoryUserIdentitiesCommandImpl) DeleteUserIdentities(ctx context.Context, identityid int32) error {
+	command := "DELETE FROM user_identities WHERE identity_id = ?"
+	_, err := repo.exec(ctx, command, []interface{}{identityid})
+	return err
+}
+
+func NewRepoUserIdentitiesCommand(db *sqlabst.SqlAbst) RepositoryUserIdentitiesCommand {
+	return &RepositoryUserIdentitiesCommandImpl{
+		db: db,
+	}
+}
+
+func (repo *RepositoryUserIdentitiesCommandImpl) exec(ctx context.Context, command string, args []interface{}) (sql.Result, error) {
+	var (
+		stmt *sqlx.Stmt
+		err  error
+	)
+	stmt, err = repo.db.PreparexContext(ctx, command)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return stmt.ExecContext(ctx, args...)
+}
+
+func buildUpdateFieldsUserIdentitiesQuery(updatedFields UserIdentitiesFieldList, userIdentities *useridentitiesmodel.UserIdentities) ([]string, []interface{}) {
+	var (
+		updatedFieldsQuery []string
+		args               []interface{}
+	)
+
+	for _, field := range updatedFields {
+		switch field {
+		case "identity_id":
+			updatedFieldsQuery = append(updatedFieldsQuery, "identity_id = ?")
+			args = append(args, userIdentities.IdentityId)
+		case "user_fkid":
+			updatedFieldsQuery = append(updatedFieldsQuery, "user_fkid = ?")
+			args = append(args, userIdentities.UserFkid)
+		case "provider":
+			updatedFieldsQuery = append(updatedFieldsQuery, "provider = ?")
+			args = append(args, userIdentities.Provider)
+		case "subject":
+			updatedFieldsQuery = append(updatedFieldsQuery, "subject = ?")
+			args = append(args, userIdentities.Subject)
+		case "email":
+			updatedFieldsQuery = append(updatedFieldsQuery, "email = ?")
+			args = append(args, userIdentities.Email)
+		case "created_at":
+			updatedFieldsQuery = append(updatedFieldsQuery, "created_at = ?")
+			args = append(args, userIdentities.CreatedAt)
+		}
+	}
+
+	return updatedFieldsQuery, args
+}
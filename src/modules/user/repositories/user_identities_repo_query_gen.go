@@ -0,0 +1,458 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	useridentitiesmodel "golang-starter/src/modules/user/entities"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/nurcahyaari/sqlabst"
+)
+
+type RepositoryUserIdentitiesQuery interface {
+	SelectUserIdentities(fields ...UserIdentitiesField) RepositoryUserIdentitiesQuery
+	ExcludeUserIdentities(excludedFields ...UserIdentitiesField) RepositoryUserIdentitiesQuery
+	FilterUserIdentities(filter Filter) RepositoryUserIdentitiesQuery
+	PaginationUserIdentities(pagination Pagination) RepositoryUserIdentitiesQuery
+	OrderByUserIdentities(orderBy []Order) RepositoryUserIdentitiesQuery
+	GetUserIdentitiesCount(ctx context.Context) (int, error)
+	GetUserIdentities(ctx context.Context) (*useridentitiesmodel.UserIdentities, error)
+	GetUserIdentitiesList(ctx context.Context) (useridentitiesmodel.UserIdentitiesList, error)
+}
+
+type RepositoryUserIdentitiesQueryImpl struct {
+	db         *sqlabst.SqlAbst
+	query      string
+	filter     Filter
+	orderBy    []Order
+	pagination Pagination
+	fields     UserIdentitiesFieldList
+}
+
+func (repo *RepositoryUserIdentitiesQueryImpl) SelectUserIdentities(fields ...UserIdentitiesField) RepositoryUserIdentitiesQuery {
+	return &RepositoryUserIdentitiesQueryImpl{
+		db:         repo.db,
+		filter:     repo.filter,
+		orderBy:    repo.orderBy,
+		pagination: repo.pagination,
+		fields:     fields,
+	}
+}
+
+func (repo *RepositoryUserIdentitiesQueryImpl) ExcludeUserIdentities(excludedFields ...UserIdentitiesField) RepositoryUserIdentitiesQuery {
+	selectedFieldsStr := excludeFields(UserIdentitiesFieldList(excludedFields).toString(),
+		UserIdentitiesSelectFields{}.All().toString())
+
+	var selectedFields []UserIdentitiesField
+	for _, sel := range selectedFieldsStr {
+		selectedFields = append(selectedFields, UserIdentitiesField(sel))
+	}
+
+	return &RepositoryUserIdentitiesQueryImpl{
+		db:         repo.db,
+		filter:     repo.filter,
+		orderBy:    repo.orderBy,
+		pagination: repo.pagination,
+		fields:     selectedFields,
+	}
+}
+
+func (repo *RepositoryUserIdentitiesQueryImpl) FilterUserIdentities(filter Filter) RepositoryUserIdentitiesQuery {
+	return &RepositoryUserIdentitiesQueryImpl{
+		db:         repo.db,
+		filter:     filter,
+		orderBy:    repo.orderBy,
+		pagination: repo.pagination,
+		fields:     repo.fields,
+	}
+}
+
+func (repo *RepositoryUserIdentitiesQueryImpl) PaginationUserIdentities(pagination Pagination) RepositoryUserIdentitiesQuery {
+	return &RepositoryUserIdentitiesQueryImpl{
+		db:         repo.db,
+		filter:     repo.filter,
+		orderBy:    repo.orderBy,
+		pagination: pagination,
+		fields:     repo.fields,
+	}
+}
+
+func (repo *RepositoryUserIdentitiesQueryImpl) OrderByUserIdentities(orderBy []Order) RepositoryUserIdentitiesQuery {
+	return &RepositoryUserIdentitiesQueryImpl{
+		db:         repo.db,
+		filter:     repo.filter,
+		orderBy:    orderBy,
+		pagination: repo.pagination,
+		fields:     repo.fields,
+	}
+}
+
+func (repo *RepositoryUserIdentitiesQueryImpl) GetUserIdentitiesList(ctx context.Context) (useridentitiesmodel.UserIdentitiesList, error) {
+	var (
+		userIdentitiesList useridentitiesmodel.UserIdentitiesList
+		values             []interface{}
+	)
+
+	if len(repo.fields) == 0 {
+		repo.fields = UserIdentitiesSelectFields{}.All()
+	}
+
+	query := fmt.Sprintf("SELECT %s FROM user_identities", strings.Join(repo.fields.toString(), ","))
+	if repo.filter != nil {
+		query += " WHERE " + repo.filter.Query()
+		values = append(values, repo.filter.Values()...)
+	}
+
+	if len(repo.orderBy) > 0 {
+		var orderStr []string
+		for _, order := range repo.orderBy {
+			orderStr = append(orderStr, order.Value()+" "+order.Direction())
+		}
+		query += fmt.Sprintf(" ORDER BY %s", strings.Join(orderStr, ","))
+	}
+
+	if repo.pagination != nil {
+		offset := (repo.pagination.GetPage() - 1) * repo.pagination.GetSize()
+		query += fmt.Sprintf(" LIMIT %d OFFSET %d", repo.pagination.GetSize(), offset)
+	}
+
+	err := repo.db.SelectContext(ctx, &userIdentitiesList, query, values...)
+	if err != nil {
+		return nil, err
+	}
+	return userIdentitiesList, nil
+}
+
+func (repo *RepositoryUserIdentitiesQueryImpl) GetUserIdentitiesCount(ctx context.Context) (int, error) {
+	var values []interface{}
+	query := fmt.Sprintf("SELECT count(1) FROM user_identities")
+	if repo.filter != nil {
+		query += " WHERE " + repo.filter.Query()
+		values = append(values, repo.filter.Values()...)
+	}
+
+	var count int
+	err := repo.db.QueryRowContext(ctx, query, values...).Scan(&count)
+	return count, err
+}
+
+func (repo *RepositoryUserIdentitiesQueryImpl) GetUserIdentities(ctx context.Context) (*useridentitiesmodel.UserIdentities, error) {
+	userIdentitiesList, err := repo.GetUserIdentitiesList(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(userIdentitiesList) == 0 {
+		return nil, errors.New("useridentities not found")
+	}
+
+	return userIdentitiesList[0], nil
+}
+
+func NewRepoUserIdentitiesQuery(db *sqlabst.SqlAbst) RepositoryUserIdentitiesQuery {
+	return &RepositoryUserIdentitiesQueryImpl{
+		db: db,
+	}
+}
+
+type UserIdentitiesField string
+type UserIdentitiesFieldList []UserIdentitiesField
+
+func (fieldList UserIdentitiesFieldList) toString() []string {
+	var fieldsStr []string
+	for _, field := range fieldList {
+		fieldsStr = append(fieldsStr, string(field))
+	}
+	return fieldsStr
+}
+
+type UserIdentitiesSelectFields struct {
+}
+
+func (UserIdentitiesSelectFields) IdentityId() UserIdentitiesField {
+	return UserIdentitiesField("identity_id")
+}
+func (UserIdentitiesSelectFields) UserFkid() UserIdentitiesField {
+	return UserIdentitiesField("user_fkid")
+}
+func (UserIdentitiesSelectFields) Provider() UserIdentitiesField {
+	return UserIdentitiesField("provider")
+}
+func (UserIdentitiesSelectFields) Subject() UserIdentitiesField {
+	return UserIdentitiesField("subject")
+}
+func (UserIdentitiesSelectFields) Email() UserIdentitiesField {
+	return UserIdentitiesField("email")
+}
+func (UserIdentitiesSelectFields) CreatedAt() UserIdentitiesField {
+	return UserIdentitiesField("created_at")
+}
+
+func (UserIdentitiesSelectFields) All() UserIdentitiesFieldList {
+	return []UserIdentitiesField{
+		UserIdentitiesField("identity_id"),
+		UserIdentitiesField("user_fkid"),
+		UserIdentitiesField("provider"),
+		UserIdentitiesField("subject"),
+		UserIdentitiesField("email"),
+		UserIdentitiesField("created_at"),
+	}
+}
+
+func NewUserIdentitiesSelectFields() UserIdentitiesSelectFields {
+	return UserIdentitiesSelectFields{}
+}
+
+type UserIdentitiesFilter struct {
+	operator string
+	query    []string
+	values   []interface{}
+}
+
+func NewUserIdentitiesFilter(operator string) UserIdentitiesFilter {
+	if operator == "" {
+		operator = "AND"
+	}
+	return UserIdentitiesFilter{
+		operator: operator,
+	}
+}
+
+func (f UserIdentitiesFilter) SetFilterByIdentityId(value interface{}, operator string) UserIdentitiesFilter {
+	query := "identity_id " + operator + " (?)"
+	var values []interface{}
+	if value == nil {
+		query = "identity_id " + operator
+	} else {
+		switch strings.ToUpper(operator) {
+		case "IN", "NOT IN":
+			query, values, _ = sqlx.In(query, value)
+		default:
+			values = append(values, value)
+		}
+	}
+	return UserIdentitiesFilter{
+		operator: f.operator,
+		query:    append(f.query, query),
+		values:   append(f.values, values...),
+	}
+}
+func (f UserIdentitiesFilter) SetFilterByUserFkid(value interface{}, operator string) UserIdentitiesFilter {
+	query := "user_fkid " + operator + " (?)"
+	var values []interface{}
+	if value == nil {
+		query = "user_fkid " + operator
+	} else {
+		switch strings.ToUpper(operator) {
+		case "IN", "NOT IN":
+			query, values, _ = sqlx.In(query, value)
+		default:
+			values = append(values, value)
+		}
+	}
+	return UserIdentitiesFilter{
+		operator: f.operator,
+		query:    append(f.query, query),
+		values:   append(f.values, values...),
+	}
+}
+func (f UserIdentitiesFilter) SetFilterByProvider(value interface{}, operator string) UserIdentitiesFilter {
+	query := "provider " + operator + " (?)"
+	var values []interface{}
+	if value == nil {
+		query = "provider " + operator
+	} else {
+		switch strings.ToUpper(operator) {
+		case "IN", "NOT IN":
+			query, values, _ = sqlx.In(query, value)
+		default:
+			values = append(values, value)
+		}
+	}
+	return UserIdentitiesFilter{
+		operator: f.operator,
+		query:    append(f.query, query),
+		values:   append(f.values, values...),
+	}
+}
+func (f UserIdentitiesFilter) SetFilterBySubject(value interface{}, operator string) UserIdentitiesFilter {
+	query := "subject " + operator + " (?)"
+	var values []interface{}
+	if value == nil {
+		query = "subject " + operator
+	} else {
+		switch strings.ToUpper(operator) {
+		case "IN", "NOT IN":
+			query, values, _ = sqlx.In(query, value)
+		default:
+			values = append(values, value)
+		}
+	}
+	return UserIdentitiesFilter{
+		operator: f.operator,
+		query:    append(f.query, query),
+		values:   append(f.values, values...),
+	}
+}
+func (f UserIdentitiesFilter) SetFilterByEmail(value interface{}, operator string) UserIdentitiesFilter {
+	query := "email " + operator + " (?)"
+	var values []interface{}
+	if value == nil {
+		query = "email " + operator
+	} else {
+		switch strings.ToUpper(operator) {
+		case "IN", "NOT IN":
+			query, values, _ = sqlx.In(query, value)
+		default:
+			values = append(values, value)
+		}
+	}
+	return UserIdentitiesFilter{
+		operator: f.operator,
+		query:    append(f.query, query),
+		values:   append(f.values, values...),
+	}
+}
+func (f UserIdentitiesFilter) SetFilterByCreatedAt(value interface{}, operator string) UserIdentitiesFilter {
+	query := "created_at " + operator + " (?)"
+	var values []interface{}
+	if value == nil {
+		query = "created_at " + operator
+	} else {
+		switch strings.ToUpper(operator) {
+		case "IN", "NOT IN":
+			query, values, _ = sqlx.In(query, value)
+		default:
+			values = append(values, value)
+		}
+	}
+	return UserIdentitiesFilter{
+		operator: f.operator,
+		query:    append(f.query, query),
+		values:   append(f.values, values...),
+	}
+}
+
+func (f UserIdentitiesFilter) Query() string {
+	return strings.Join(f.query, " "+f.operator+" ")
+}
+
+func (f UserIdentitiesFilter) Values() []interface{} {
+	return f.values
+}
+
+type UserIdentitiesIdentityIdOrder struct {
+	direction string
+}
+
+func (o UserIdentitiesIdentityIdOrder) SetDirection(direction string) UserIdentitiesIdentityIdOrder {
+	return UserIdentitiesIdentityIdOrder{
+		direction: direction,
+	}
+}
+func (o UserIdentitiesIdentityIdOrder) Value() string {
+	return "identity_id"
+}
+func (o UserIdentitiesIdentityIdOrder) Direction() string {
+	return o.direction
+}
+func NewUserIdentitiesIdentityIdOrder() UserIdentitiesIdentityIdOrder {
+	return UserIdentitiesIdentityIdOrder{}
+}
+
+type UserIdentitiesUserFkidOrder struct {
+	direction string
+}
+
+func (o UserIdentitiesUserFkidOrder) SetDirection(direction string) UserIdentitiesUserFkidOrder {
+	return UserIdentitiesUserFkidOrder{
+		direction: direction,
+	}
+}
+func (o UserIdentitiesUserFkidOrder) Value() string {
+	return "user_fkid"
+}
+func (o UserIdentitiesUserFkidOrder) Direction() string {
+	return o.direction
+}
+func NewUserIdentitiesUserFkidOrder() UserIdentitiesUserFkidOrder {
+	return UserIdentitiesUserFkidOrder{}
+}
+
+type UserIdentitiesProviderOrder struct {
+	direction string
+}
+
+func (o UserIdentitiesProviderOrder) SetDirection(direction string) UserIdentitiesProviderOrder {
+	return UserIdentitiesProviderOrder{
+		direction: direction,
+	}
+}
+func (o UserIdentitiesProviderOrder) Value() string {
+	return "provider"
+}
+func (o UserIdentitiesProviderOrder) Direction() string {
+	return o.direction
+}
+func NewUserIdentitiesProviderOrder() UserIdentitiesProviderOrder {
+	return UserIdentitiesProviderOrder{}
+}
+
+type UserIdentitiesSubjectOrder struct {
+	direction string
+}
+
+func (o UserIdentitiesSubjectOrder) SetDirection(direction string) UserIdentitiesSubjectOrder {
+	return UserIdentitiesSubjectOrder{
+		direction: direction,
+	}
+}
+func (o UserIdentitiesSubjectOrder) Value() string {
+	return "subject"
+}
+func (o UserIdentitiesSubjectOrder) Direction() string {
+	return o.direction
+}
+func NewUserIdentitiesSubjectOrder() UserIdentitiesSubjectOrder {
+	return UserIdentitiesSubjectOrder{}
+}
+
+type UserIdentitiesEmailOrder struct {
+	direction string
+}
+
+func (o UserIdentitiesEmailOrder) SetDirection(direction string) UserIdentitiesEmailOrder {
+	return UserIdentitiesEmailOrder{
+		direction: direction,
+	}
+}
+func (o UserIdentitiesEmailOrder) Value() string {
+	return "email"
+}
+func (o UserIdentitiesEmailOrder) Direction() string {
+	return o.direction
+}
+func NewUserIdentitiesEmailOrder() UserIdentitiesEmailOrder {
+	return UserIdentitiesEmailOrder{}
+}
+
+type UserIdentitiesCreatedAtOrder struct {
+	direction string
+}
+
+func (o UserIdentitiesCreatedAtOrder) SetDirection(direction string) UserIdentitiesCreatedAtOrder {
+	return UserIdentitiesCreatedAtOrder{
+		direction: direction,
+	}
+}
+func (o UserIdentitiesCreatedAtOrder) Value() string {
+	return "created_at"
+}
+func (o UserIdentitiesCreatedAtOrder) Direction() string {
+	return o.direction
+}
+func NewUserIdentitiesCreatedAtOrder() UserIdentitiesCreatedAtOrder {
+	return UserIdentitiesCreatedAtOrder{}
+}
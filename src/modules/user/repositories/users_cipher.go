@@ -0,0 +1,193 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"golang-starter/internal/utils/encryption"
+	usersmodel "golang-starter/src/modules/user/entities"
+	"strings"
+
+	"github.com/guregu/null"
+)
+
+// ErrEncryptedFilter is returned when an encrypted column is filtered by anything else than its blind index
+var ErrEncryptedFilter = errors.New("the encrypted users columns can only be filtered by email with = or IN")
+
+// ErrEncryptedIdentitiesFilter is returned when the encrypted email of user_identities is filtered, it has no blind index
+var ErrEncryptedIdentitiesFilter = errors.New("the encrypted email of user_identities cannot be filtered")
+
+// UsersCipher encrypt the PII columns of users, email and name, and the email of user_identities.
+// the email of users is looked up by its blind index email_bidx, the one of the identities is never looked up
+// so it has no index. the rows that aren't backfilled yet are still plaintext, they are read as is until
+// `make backfill` encrypts them
+type UsersCipher struct {
+	encrypter    *encryption.Encrypter
+	blindIndexer *encryption.BlindIndexer
+}
+
+func NewUsersCipher(encrypter *encryption.Encrypter, blindIndexer *encryption.BlindIndexer) *UsersCipher {
+	return &UsersCipher{
+		encrypter:    encrypter,
+		blindIndexer: blindIndexer,
+	}
+}
+
+// EncryptUsers return a copy of users with email and name encrypted and email_bidx computed
+func (c *UsersCipher) EncryptUsers(users *usersmodel.Users) (*usersmodel.Users, error) {
+	encrypted := *users
+
+	var err error
+	if users.Email != "" {
+		if encrypted.Email, err = c.encrypter.Encrypt(users.Email); err != nil {
+			return nil, err
+		}
+		encrypted.EmailBidx = null.StringFrom(c.blindIndexer.Index(normalizeEmail(users.Email)))
+	}
+	if users.Name != "" {
+		if encrypted.Name, err = c.encrypter.Encrypt(users.Name); err != nil {
+			return nil, err
+		}
+	}
+	return &encrypted, nil
+}
+
+// DecryptUsers decrypt email and name of users in place
+func (c *UsersCipher) DecryptUsers(users *usersmodel.Users) error {
+	var err error
+	if users.Email, err = c.decrypt(users.Email); err != nil {
+		return fmt.Errorf("cannot decrypt the email of user %d: %w", users.UserId, err)
+	}
+	if users.Name, err = c.decrypt(users.Name); err != nil {
+		return fmt.Errorf("cannot decrypt the name of user %d: %w", users.UserId, err)
+	}
+	return nil
+}
+
+// NeedsBackfill tell whether the stored users isn't encrypted or indexed by the active keys
+func (c *UsersCipher) NeedsBackfill(users *usersmodel.Users) bool {
+	if users.Email != "" && (c.encrypter.NeedsReencrypt(users.Email) || !users.EmailBidx.Valid ||
+		c.blindIndexer.NeedsReindex(users.EmailBidx.String)) {
+		return true
+	}
+	return users.Name != "" && c.encrypter.NeedsReencrypt(users.Name)
+}
+
+// EncryptUserIdentities return a copy of userIdentities with email encrypted
+func (c *UsersCipher) EncryptUserIdentities(userIdentities *usersmodel.UserIdentities) (*usersmodel.UserIdentities, error) {
+	encrypted := *userIdentities
+
+	var err error
+	if userIdentities.Email != "" {
+		if encrypted.Email, err = c.encrypter.Encrypt(userIdentities.Email); err != nil {
+			return nil, err
+		}
+	}
+	return &encrypted, nil
+}
+
+// DecryptUserIdentities decrypt email of userIdentities in place
+func (c *UsersCipher) DecryptUserIdentities(userIdentities *usersmodel.UserIdentities) error {
+	var err error
+	if userIdentities.Email, err = c.decrypt(userIdentities.Email); err != nil {
+		return fmt.Errorf("cannot decrypt the email of identity %d: %w", userIdentities.IdentityId, err)
+	}
+	return nil
+}
+
+// NeedsIdentityBackfill tell whether the stored userIdentities isn't encrypted by the active key
+func (c *UsersCipher) NeedsIdentityBackfill(userIdentities *usersmodel.UserIdentities) bool {
+	return userIdentities.Email != "" && c.encrypter.NeedsReencrypt(userIdentities.Email)
+}
+
+func (c *UsersCipher) decrypt(value string) (string, error) {
+	if !encryption.IsEnvelope(value) {
+		return value, nil
+	}
+	return c.encrypter.Decrypt(value)
+}
+
+// encryptFields add email_bidx to the updated fields whenever email is updated
+func (c *UsersCipher) encryptFields(fields []UsersField) []UsersField {
+	for _, field := range fields {
+		if field == NewUsersSelectFields().Email() {
+			return append(fields, NewUsersSelectFields().EmailBidx())
+		}
+	}
+	return fields
+}
+
+// filter rewrite the email filter of = and IN into the lookup of its blind index by every key,
+// the plaintext email is still matched for the rows that aren't backfilled yet
+func (c *UsersCipher) filter(filter Filter) (Filter, error) {
+	f, ok := filter.(UsersFilter)
+	if !ok {
+		return filter, nil
+	}
+
+	rewritten := UsersFilter{operator: f.operator}
+	values := f.values
+	for _, query := range f.query {
+		count := strings.Count(query, "?")
+		if count > len(values) {
+			return nil, fmt.Errorf("users filter %q has not enough values", query)
+		}
+		queryValues := values[:count]
+		values = values[count:]
+
+		column := strings.SplitN(query, " ", 2)[0]
+		switch column {
+		case string(NewUsersSelectFields().Name()):
+			return nil, ErrEncryptedFilter
+		case string(NewUsersSelectFields().Email()):
+		default:
+			rewritten.query = append(rewritten.query, query)
+			rewritten.values = append(rewritten.values, queryValues...)
+			continue
+		}
+
+		operator := strings.ToUpper(strings.TrimSpace(strings.SplitN(strings.TrimPrefix(query, column), "(", 2)[0]))
+		if (operator != "=" && operator != "IN") || count == 0 {
+			return nil, ErrEncryptedFilter
+		}
+
+		var indexes []interface{}
+		for _, value := range queryValues {
+			email, ok := value.(string)
+			if !ok {
+				return nil, ErrEncryptedFilter
+			}
+			for _, index := range c.blindIndexer.Indexes(normalizeEmail(email)) {
+				indexes = append(indexes, index)
+			}
+		}
+
+		rewritten.query = append(rewritten.query, fmt.Sprintf("(email_bidx IN (%s) OR email IN (%s))",
+			placeholders(len(indexes)), placeholders(count)))
+		rewritten.values = append(append(rewritten.values, indexes...), queryValues...)
+	}
+
+	return rewritten, nil
+}
+
+// identitiesFilter refuse the filters of the encrypted email of user_identities
+func (c *UsersCipher) identitiesFilter(filter Filter) (Filter, error) {
+	f, ok := filter.(UserIdentitiesFilter)
+	if !ok {
+		return filter, nil
+	}
+	for _, query := range f.query {
+		if strings.SplitN(query, " ", 2)[0] == string(NewUserIdentitiesSelectFields().Email()) {
+			return nil, ErrEncryptedIdentitiesFilter
+		}
+	}
+	return filter, nil
+}
+
+// normalizeEmail make the blind index case insensitive, like the collation of the plaintext column
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?,", n), ",")
+}
@@ -0,0 +1,149 @@
+package repositories
+
+import (
+	"context"
+	usersmodel "golang-starter/src/modules/user/entities"
+)
+
+// RepositoryUsersEncryptedCommandImpl encrypt the users before they're written by the generated command
+type RepositoryUsersEncryptedCommandImpl struct {
+	command RepositoryUsersCommand
+	cipher  *UsersCipher
+}
+
+func NewRepoUsersEncryptedCommand(command RepositoryUsersCommand, cipher *UsersCipher) *RepositoryUsersEncryptedCommandImpl {
+	return &RepositoryUsersEncryptedCommandImpl{
+		command: command,
+		cipher:  cipher,
+	}
+}
+
+func (repo *RepositoryUsersEncryptedCommandImpl) InsertUsersList(ctx context.Context, usersList usersmodel.UsersList) (*InsertResult, error) {
+	encryptedList := make(usersmodel.UsersList, 0, len(usersList))
+	for _, users := range usersList {
+		encrypted, err := repo.cipher.EncryptUsers(users)
+		if err != nil {
+			return nil, err
+		}
+		encryptedList = append(encryptedList, encrypted)
+	}
+	return repo.command.InsertUsersList(ctx, encryptedList)
+}
+
+func (repo *RepositoryUsersEncryptedCommandImpl) InsertUsers(ctx context.Context, users *usersmodel.Users) (*InsertResult, error) {
+	return repo.InsertUsersList(ctx, usersmodel.UsersList{users})
+}
+
+func (repo *RepositoryUsersEncryptedCommandImpl) UpdateUsersByFilter(ctx context.Context, users *usersmodel.Users, filter Filter, updatedFields ...UsersField) error {
+	encrypted, err := repo.cipher.EncryptUsers(users)
+	if err != nil {
+		return err
+	}
+	filter, err = repo.cipher.filter(filter)
+	if err != nil {
+		return err
+	}
+	return repo.command.UpdateUsersByFilter(ctx, encrypted, filter, repo.cipher.encryptFields(updatedFields)...)
+}
+
+func (repo *RepositoryUsersEncryptedCommandImpl) UpdateUsers(ctx context.Context, users *usersmodel.Users, userid int32, updatedFields ...UsersField) error {
+	encrypted, err := repo.cipher.EncryptUsers(users)
+	if err != nil {
+		return err
+	}
+	return repo.command.UpdateUsers(ctx, encrypted, userid, repo.cipher.encryptFields(updatedFields)...)
+}
+
+func (repo *RepositoryUsersEncryptedCommandImpl) DeleteUsersList(ctx context.Context, filter Filter) error {
+	filter, err := repo.cipher.filter(filter)
+	if err != nil {
+		return err
+	}
+	return repo.command.DeleteUsersList(ctx, filter)
+}
+
+func (repo *RepositoryUsersEncryptedCommandImpl) DeleteUsers(ctx context.Context, userid int32) error {
+	return repo.command.DeleteUsers(ctx, userid)
+}
+
+// RepositoryUsersEncryptedQueryImpl decrypt the users read by the generated query, the error of an
+// encrypted column filter is returned once the query is executed
+type RepositoryUsersEncryptedQueryImpl struct {
+	query  RepositoryUsersQuery
+	cipher *UsersCipher
+	err    error
+}
+
+func NewRepoUsersEncryptedQuery(query RepositoryUsersQuery, cipher *UsersCipher) *RepositoryUsersEncryptedQueryImpl {
+	return &RepositoryUsersEncryptedQueryImpl{
+		query:  query,
+		cipher: cipher,
+	}
+}
+
+func (repo *RepositoryUsersEncryptedQueryImpl) SelectUsers(fields ...UsersField) RepositoryUsersQuery {
+	return repo.with(repo.query.SelectUsers(fields...), repo.err)
+}
+
+func (repo *RepositoryUsersEncryptedQueryImpl) ExcludeUsers(excludedFields ...UsersField) RepositoryUsersQuery {
+	return repo.with(repo.query.ExcludeUsers(excludedFields...), repo.err)
+}
+
+func (repo *RepositoryUsersEncryptedQueryImpl) FilterUsers(filter Filter) RepositoryUsersQuery {
+	filter, err := repo.cipher.filter(filter)
+	if err != nil {
+		return repo.with(repo.query, err)
+	}
+	return repo.with(repo.query.FilterUsers(filter), repo.err)
+}
+
+func (repo *RepositoryUsersEncryptedQueryImpl) PaginationUsers(pagination Pagination) RepositoryUsersQuery {
+	return repo.with(repo.query.PaginationUsers(pagination), repo.err)
+}
+
+func (repo *RepositoryUsersEncryptedQueryImpl) OrderByUsers(orderBy []Order) RepositoryUsersQuery {
+	return repo.with(repo.query.OrderByUsers(orderBy), repo.err)
+}
+
+func (repo *RepositoryUsersEncryptedQueryImpl) GetUsersCount(ctx context.Context) (int, error) {
+	if repo.err != nil {
+		return 0, repo.err
+	}
+	return repo.query.GetUsersCount(ctx)
+}
+
+// GetUsers return ErrNotFound when no row matches, the generated one has no error to check
+func (repo *RepositoryUsersEncryptedQueryImpl) GetUsers(ctx context.Context) (*usersmodel.Users, error) {
+	usersList, err := repo.GetUsersList(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(usersList) == 0 {
+		return nil, ErrNotFound
+	}
+	return usersList[0], nil
+}
+
+func (repo *RepositoryUsersEncryptedQueryImpl) GetUsersList(ctx context.Context) (usersmodel.UsersList, error) {
+	if repo.err != nil {
+		return nil, repo.err
+	}
+	usersList, err := repo.query.GetUsersList(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, users := range usersList {
+		if err := repo.cipher.DecryptUsers(users); err != nil {
+			return nil, err
+		}
+	}
+	return usersList, nil
+}
+
+func (repo *RepositoryUsersEncryptedQueryImpl) with(query RepositoryUsersQuery, err error) *RepositoryUsersEncryptedQueryImpl {
+	return &RepositoryUsersEncryptedQueryImpl{
+		query:  query,
+		cipher: repo.cipher,
+		err:    err,
+	}
+}
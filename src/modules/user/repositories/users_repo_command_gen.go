@@ -28,10 +28,14 @@ func (repo *RepositoryUsersCommandImpl) InsertUsersList(ctx context.Context, use
 	command := `INSERT INTO users (photo,
 	username,
 	email,
+	email_bidx,
 	password,
 	name,
+	role,
 	created_at,
-	updated_at) VALUES
+	updated_at,
+	disabled_at,
+	password_reset_at) VALUES
 		`
 
 	var (
@@ -45,15 +49,23 @@ func (repo *RepositoryUsersCommandImpl) InsertUsersList(ctx context.Context, use
 	?,
 	?,
 	?,
+	?,
+	?,
+	?,
+	?,
 	?)`)
 		args = append(args,
 			users.Photo,
 			users.Username,
 			users.Email,
+			users.EmailBidx,
 			users.Password,
 			users.Name,
+			users.Role,
 			users.CreatedAt,
 			users.UpdatedAt,
+			users.DisabledAt,
+			users.PasswordResetAt,
 		)
 	}
 	command += strings.Join(placeholders, ",")
@@ -144,18 +156,30 @@ func buildUpdateFieldsUsersQuery(updatedFields UsersFieldList, users *usersmodel
 		case "email":
 			updatedFieldsQuery = append(updatedFieldsQuery, "email = ?")
 			args = append(args, users.Email)
+		case "email_bidx":
+			updatedFieldsQuery = append(updatedFieldsQuery, "email_bidx = ?")
+			args = append(args, users.EmailBidx)
 		case "password":
 			updatedFieldsQuery = append(updatedFieldsQuery, "password = ?")
 			args = append(args, users.Password)
 		case "name":
 			updatedFieldsQuery = append(updatedFieldsQuery, "name = ?")
 			args = append(args, users.Name)
+		case "role":
+			updatedFieldsQuery = append(updatedFieldsQuery, "role = ?")
+			args = append(args, users.Role)
 		case "created_at":
 			updatedFieldsQuery = append(updatedFieldsQuery, "created_at = ?")
 			args = append(args, users.CreatedAt)
 		case "updated_at":
 			updatedFieldsQuery = append(updatedFieldsQuery, "updated_at = ?")
 			args = append(args, users.UpdatedAt)
+		case "disabled_at":
+			updatedFieldsQuery = append(updatedFieldsQuery, "disabled_at = ?")
+			args = append(args, users.DisabledAt)
+		case "password_reset_at":
+			updatedFieldsQuery = append(updatedFieldsQuery, "password_reset_at = ?")
+			args = append(args, users.PasswordResetAt)
 		}
 	}
 
@@ -183,18 +183,30 @@ func (UsersSelectFields) Username() UsersField {
 func (UsersSelectFields) Email() UsersField {
 	return UsersField("email")
 }
+func (UsersSelectFields) EmailBidx() UsersField {
+	return UsersField("email_bidx")
+}
 func (UsersSelectFields) Password() UsersField {
 	return UsersField("password")
 }
 func (UsersSelectFields) Name() UsersField {
 	return UsersField("name")
 }
+func (UsersSelectFields) Role() UsersField {
+	return UsersField("role")
+}
 func (UsersSelectFields) CreatedAt() UsersField {
 	return UsersField("created_at")
 }
 func (UsersSelectFields) UpdatedAt() UsersField {
 	return UsersField("updated_at")
 }
+func (UsersSelectFields) DisabledAt() UsersField {
+	return UsersField("disabled_at")
+}
+func (UsersSelectFields) PasswordResetAt() UsersField {
+	return UsersField("password_reset_at")
+}
 
 func (UsersSelectFields) All() UsersFieldList {
 	return []UsersField{
@@ -202,10 +214,14 @@ func (UsersSelectFields) All() UsersFieldList {
 		UsersField("photo"),
 		UsersField("username"),
 		UsersField("email"),
+		UsersField("email_bidx"),
 		UsersField("password"),
 		UsersField("name"),
+		UsersField("role"),
 		UsersField("created_at"),
 		UsersField("updated_at"),
+		UsersField("disabled_at"),
+		UsersField("password_reset_at"),
 	}
 }
 
@@ -304,6 +320,25 @@ func (f UsersFilter) SetFilterByEmail(value interface{}, operator string) UsersF
 		values:   append(f.values, values...),
 	}
 }
+func (f UsersFilter) SetFilterByEmailBidx(value interface{}, operator string) UsersFilter {
+	query := "email_bidx " + operator + " (?)"
+	var values []interface{}
+	if value == nil {
+		query = "email_bidx " + operator
+	} else {
+		switch strings.ToUpper(operator) {
+		case "IN", "NOT IN":
+			query, values, _ = sqlx.In(query, value)
+		default:
+			values = append(values, value)
+		}
+	}
+	return UsersFilter{
+		operator: f.operator,
+		query:    append(f.query, query),
+		values:   append(f.values, values...),
+	}
+}
 func (f UsersFilter) SetFilterByPassword(value interface{}, operator string) UsersFilter {
 	query := "password " + operator + " (?)"
 	var values []interface{}
@@ -342,6 +377,25 @@ func (f UsersFilter) SetFilterByName(value interface{}, operator string) UsersFi
 		values:   append(f.values, values...),
 	}
 }
+func (f UsersFilter) SetFilterByRole(value interface{}, operator string) UsersFilter {
+	query := "role " + operator + " (?)"
+	var values []interface{}
+	if value == nil {
+		query = "role " + operator
+	} else {
+		switch strings.ToUpper(operator) {
+		case "IN", "NOT IN":
+			query, values, _ = sqlx.In(query, value)
+		default:
+			values = append(values, value)
+		}
+	}
+	return UsersFilter{
+		operator: f.operator,
+		query:    append(f.query, query),
+		values:   append(f.values, values...),
+	}
+}
 func (f UsersFilter) SetFilterByCreatedAt(value interface{}, operator string) UsersFilter {
 	query := "created_at " + operator + " (?)"
 	var values []interface{}
@@ -380,6 +434,44 @@ func (f UsersFilter) SetFilterByUpdatedAt(value interface{}, operator string) Us
 		values:   append(f.values, values...),
 	}
 }
+func (f UsersFilter) SetFilterByDisabledAt(value interface{}, operator string) UsersFilter {
+	query := "disabled_at " + operator + " (?)"
+	var values []interface{}
+	if value == nil {
+		query = "disabled_at " + operator
+	} else {
+		switch strings.ToUpper(operator) {
+		case "IN", "NOT IN":
+			query, values, _ = sqlx.In(query, value)
+		default:
+			values = append(values, value)
+		}
+	}
+	return UsersFilter{
+		operator: f.operator,
+		query:    append(f.query, query),
+		values:   append(f.values, values...),
+	}
+}
+func (f UsersFilter) SetFilterByPasswordResetAt(value interface{}, operator string) UsersFilter {
+	query := "password_reset_at " + operator + " (?)"
+	var values []interface{}
+	if value == nil {
+		query = "password_reset_at " + operator
+	} else {
+		switch strings.ToUpper(operator) {
+		case "IN", "NOT IN":
+			query, values, _ = sqlx.In(query, value)
+		default:
+			values = append(values, value)
+		}
+	}
+	return UsersFilter{
+		operator: f.operator,
+		query:    append(f.query, query),
+		values:   append(f.values, values...),
+	}
+}
 
 func (f UsersFilter) Query() string {
 	return strings.Join(f.query, " "+f.operator+" ")
@@ -465,6 +557,25 @@ func NewUsersEmailOrder() UsersEmailOrder {
 	return UsersEmailOrder{}
 }
 
+type UsersEmailBidxOrder struct {
+	direction string
+}
+
+func (o UsersEmailBidxOrder) SetDirection(direction string) UsersEmailBidxOrder {
+	return UsersEmailBidxOrder{
+		direction: direction,
+	}
+}
+func (o UsersEmailBidxOrder) Value() string {
+	return "email_bidx"
+}
+func (o UsersEmailBidxOrder) Direction() string {
+	return o.direction
+}
+func NewUsersEmailBidxOrder() UsersEmailBidxOrder {
+	return UsersEmailBidxOrder{}
+}
+
 type UsersPasswordOrder struct {
 	direction string
 }
@@ -503,6 +614,25 @@ func NewUsersNameOrder() UsersNameOrder {
 	return UsersNameOrder{}
 }
 
+type UsersRoleOrder struct {
+	direction string
+}
+
+func (o UsersRoleOrder) SetDirection(direction string) UsersRoleOrder {
+	return UsersRoleOrder{
+		direction: direction,
+	}
+}
+func (o UsersRoleOrder) Value() string {
+	return "role"
+}
+func (o UsersRoleOrder) Direction() string {
+	return o.direction
+}
+func NewUsersRoleOrder() UsersRoleOrder {
+	return UsersRoleOrder{}
+}
+
 type UsersCreatedAtOrder struct {
 	direction string
 }
@@ -540,3 +670,41 @@ func (o UsersUpdatedAtOrder) Direction() string {
 func NewUsersUpdatedAtOrder() UsersUpdatedAtOrder {
 	return UsersUpdatedAtOrder{}
 }
+
+type UsersDisabledAtOrder struct {
+	direction string
+}
+
+func (o UsersDisabledAtOrder) SetDirection(direction string) UsersDisabledAtOrder {
+	return UsersDisabledAtOrder{
+		direction: direction,
+	}
+}
+func (o UsersDisabledAtOrder) Value() string {
+	return "disabled_at"
+}
+func (o UsersDisabledAtOrder) Direction() string {
+	return o.direction
+}
+func NewUsersDisabledAtOrder() UsersDisabledAtOrder {
+	return UsersDisabledAtOrder{}
+}
+
+type UsersPasswordResetAtOrder struct {
+	direction string
+}
+
+func (o UsersPasswordResetAtOrder) SetDirection(direction string) UsersPasswordResetAtOrder {
+	return UsersPasswordResetAtOrder{
+		direction: direction,
+	}
+}
+func (o UsersPasswordResetAtOrder) Value() string {
+	return "password_reset_at"
+}
+func (o UsersPasswordResetAtOrder) Direction() string {
+	return o.direction
+}
+func NewUsersPasswordResetAtOrder() UsersPasswordResetAtOrder {
+	return UsersPasswordResetAtOrder{}
+}
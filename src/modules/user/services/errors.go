@@ -0,0 +1,38 @@
+package services
+
+import (
+	"golang-starter/internal/protocols/http/errors"
+	"golang-starter/src/modules/user/repositories"
+	"net/http"
+)
+
+var (
+	ErrUserNotFound       = errors.NewRespError(http.StatusNotFound, "USER_NOT_FOUND", "user not found")
+	ErrUserDisabled       = errors.NewRespError(http.StatusForbidden, "USER_DISABLED", "user is disabled")
+	ErrUserAlreadyExists  = errors.NewRespError(http.StatusConflict, "USER_ALREADY_EXISTS", "email or username is already used")
+	ErrUsernameUsed       = errors.NewRespError(http.StatusConflict, "USERNAME_ALREADY_USED", "username is already used")
+	ErrInvalidCredentials = errors.NewRespError(http.StatusUnauthorized, "INVALID_CREDENTIALS", "email and password didn't match")
+	ErrPasswordMismatch   = errors.NewRespError(http.StatusUnauthorized, "PASSWORD_MISMATCH", "current password didn't match")
+	ErrPasswordTooShort   = errors.NewRespError(http.StatusBadRequest, "PASSWORD_TOO_SHORT", "password is too short")
+	ErrLoginLocked        = errors.NewRespError(http.StatusTooManyRequests, "LOGIN_LOCKED", "too many failed login attempts, try again later")
+	ErrLoginRateLimited   = errors.NewRespError(http.StatusTooManyRequests, "LOGIN_RATE_LIMITED", "too many login attempts, try again later")
+
+	ErrApiKeyNotFound       = errors.NewRespError(http.StatusNotFound, "API_KEY_NOT_FOUND", "api key not found")
+	ErrApiKeyInvalid        = errors.NewRespError(http.StatusUnauthorized, "API_KEY_INVALID", "api key is not valid")
+	ErrApiKeyRevoked        = errors.NewRespError(http.StatusUnauthorized, "API_KEY_REVOKED", "api key is revoked")
+	ErrApiKeyExpired        = errors.NewRespError(http.StatusUnauthorized, "API_KEY_EXPIRED", "api key has expired")
+	ErrApiKeyAlreadyRevoked = errors.NewRespError(http.StatusConflict, "API_KEY_ALREADY_REVOKED", "api key is revoked")
+	ErrApiKeyLimit          = errors.NewRespError(http.StatusConflict, "API_KEY_LIMIT_REACHED", "too many api keys, revoke the unused ones first")
+
+	ErrOidcProviderNotFound = errors.NewRespError(http.StatusNotFound, "OIDC_PROVIDER_NOT_FOUND", "oidc provider not found")
+	ErrOidcStateInvalid     = errors.NewRespError(http.StatusBadRequest, "OIDC_STATE_INVALID", "state is not valid")
+	ErrOidcSignInFailed     = errors.NewRespError(http.StatusUnauthorized, "OIDC_SIGN_IN_FAILED", "failed to sign in with the provider")
+)
+
+// notFoundOr return notFound when err is a row that isn't found, any other error is an internal one
+func notFoundOr(err error, notFound *errors.RespError) error {
+	if errors.Is(err, repositories.ErrNotFound) {
+		return notFound
+	}
+	return errors.Internal(err)
+}
@@ -0,0 +1,425 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"golang-starter/infrastructures/db/transaction"
+	"golang-starter/internal/logger"
+	"golang-starter/internal/protocols/http/errors"
+	"golang-starter/internal/utils/auth"
+	"golang-starter/internal/utils/password"
+	"golang-starter/internal/utils/tracing"
+	"golang-starter/src/modules/user/dto"
+	"golang-starter/src/modules/user/entities"
+	"golang-starter/src/modules/user/repositories"
+	"strings"
+
+	"github.com/guregu/null"
+)
+
+const (
+	defaultUserListSize = 20
+	maxUserListSize     = 100
+)
+
+// UserAdminService is the user management for admin, every action that change a user
+// is recorded in user_audit_logs together with the admin that run it
+type UserAdminService interface {
+	ListUsers(ctx context.Context, params dto.UserRequestListParams) (*dto.UserListRespBody, error)
+	CreateUser(ctx context.Context, actorId uint, req dto.UserRequestCreateBody) (*dto.UserAdminRespBody, error)
+	DisableUser(ctx context.Context, actorId, userId uint) error
+	EnableUser(ctx context.Context, actorId, userId uint) error
+	ResetUserPassword(ctx context.Context, actorId, userId uint) (*dto.UserPasswordResetRespBody, error)
+	DeleteUser(ctx context.Context, actorId, userId uint) error
+	UnlockUser(ctx context.Context, actorId, userId uint) error
+	ListUserAuditLogs(ctx context.Context, userId uint) ([]dto.UserAuditLogRespBody, error)
+}
+
+type UserAdminServiceImpl struct {
+	userRepository         repositories.Repositories
+	loginAttemptRepository repositories.LoginAttemptRepository
+	transaction            *transaction.TransactionImpl
+	passwordHasher         password.Hasher
+	tokenRevoker           auth.TokenRevoker
+	tokenStore             auth.TokenStore
+}
+
+func NewUserAdminService(
+	userRepository repositories.Repositories,
+	loginAttemptRepository repositories.LoginAttemptRepository,
+	transaction *transaction.TransactionImpl,
+	passwordHasher password.Hasher,
+	tokenRevoker auth.TokenRevoker,
+	tokenStore auth.TokenStore,
+) *UserAdminServiceImpl {
+	return &UserAdminServiceImpl{
+		userRepository:         userRepository,
+		loginAttemptRepository: loginAttemptRepository,
+		transaction:            transaction,
+		passwordHasher:         passwordHasher,
+		tokenRevoker:           tokenRevoker,
+		tokenStore:             tokenStore,
+	}
+}
+
+func (s UserAdminServiceImpl) ListUsers(ctx context.Context, params dto.UserRequestListParams) (*dto.UserListRespBody, error) {
+	ctx, span := tracing.Start(ctx, "UserAdminService.ListUsers")
+	defer span.End()
+
+	if params.Page < 1 {
+		params.Page = 1
+	}
+	if params.Size < 1 {
+		params.Size = defaultUserListSize
+	}
+	if params.Size > maxUserListSize {
+		params.Size = maxUserListSize
+	}
+
+	filter := repositories.NewUsersFilter("AND")
+	var hasFilter bool
+	if params.Email != "" {
+		// the email is encrypted, it can only be looked up as a whole by its blind index
+		filter = filter.SetFilterByEmail(params.Email, "=")
+		hasFilter = true
+	}
+	if params.Username != "" {
+		filter = filter.SetFilterByUsername("%"+params.Username+"%", "LIKE")
+		hasFilter = true
+	}
+	if params.CreatedFrom > 0 {
+		filter = filter.SetFilterByCreatedAt(params.CreatedFrom, ">=")
+		hasFilter = true
+	}
+	if params.CreatedTo > 0 {
+		filter = filter.SetFilterByCreatedAt(params.CreatedTo, "<=")
+		hasFilter = true
+	}
+	if params.Disabled != nil {
+		if *params.Disabled {
+			filter = filter.SetFilterByDisabledAt(nil, "IS NOT NULL")
+		} else {
+			filter = filter.SetFilterByDisabledAt(nil, "IS NULL")
+		}
+		hasFilter = true
+	}
+
+	query := s.userRepository.SelectUsers()
+	if hasFilter {
+		query = query.FilterUsers(filter)
+	}
+
+	total, err := query.GetUsersCount(ctx)
+	if err != nil {
+		logger.Ctx(ctx).Err(err).Msg("error count users")
+		return nil, errors.Internal(err)
+	}
+
+	users, err := query.
+		OrderByUsers([]repositories.Order{repositories.NewUsersUserIdOrder().SetDirection("ASC")}).
+		PaginationUsers(repositories.PaginationData{Page: params.Page, Size: params.Size}).
+		GetUsersList(ctx)
+	if err != nil {
+		logger.Ctx(ctx).Err(err).Msg("error fetch users")
+		return nil, errors.Internal(err)
+	}
+
+	usersResp := dto.CreateUserListResp(users, params.Page, params.Size, total)
+
+	return &usersResp, nil
+}
+
+func (s UserAdminServiceImpl) CreateUser(ctx context.Context, actorId uint, req dto.UserRequestCreateBody) (*dto.UserAdminRespBody, error) {
+	ctx, span := tracing.Start(ctx, "UserAdminService.CreateUser")
+	defer span.End()
+
+	if strings.TrimSpace(req.Email) == "" || strings.TrimSpace(req.Username) == "" || strings.TrimSpace(req.Name) == "" {
+		return nil, errors.BadRequest("email, username and name are required")
+	}
+	if len(req.Password) < minPasswordLength {
+		return nil, ErrPasswordTooShort.WithMessage(fmt.Sprintf("password must have at least %d characters", minPasswordLength))
+	}
+	if req.Role == "" {
+		req.Role = entities.RoleUser
+	}
+	if req.Role != entities.RoleUser && req.Role != entities.RoleAdmin {
+		return nil, errors.BadRequest("role must be user or admin")
+	}
+
+	count, err := s.userRepository.
+		FilterUsers(repositories.NewUsersFilter("OR").
+			SetFilterByEmail(req.Email, "=").
+			SetFilterByUsername(req.Username, "=")).
+		GetUsersCount(ctx)
+	if err != nil {
+		logger.Ctx(ctx).Err(err).Msg("error count user by email and username")
+		return nil, errors.Internal(err)
+	}
+	if count > 0 {
+		return nil, ErrUserAlreadyExists
+	}
+
+	hashedPassword, err := s.passwordHasher.Hash(req.Password)
+	if err != nil {
+		logger.Ctx(ctx).Err(err).Msg("error hash password")
+		return nil, errors.Internal(err)
+	}
+
+	user := &entities.Users{
+		Email:     req.Email,
+		Username:  req.Username,
+		Name:      req.Name,
+		Photo:     req.Photo,
+		Role:      req.Role,
+		Password:  hashedPassword,
+		CreatedAt: unixMilli(),
+	}
+
+	err = s.transaction.RunWithTransaction(ctx, func() error {
+		res, err := s.userRepository.InsertUsers(ctx, user)
+		if err != nil {
+			return err
+		}
+
+		lastInsertedId, err := res.LastInsertId()
+		if err != nil {
+			return err
+		}
+		user.UserId = int32(lastInsertedId)
+
+		return s.recordAudit(ctx, actorId, user.UserId, entities.AuditActionUserCreate, "role: "+user.Role)
+	})
+	if err != nil {
+		logger.Ctx(ctx).Err(err).Msg("error create user")
+		return nil, errors.Internal(err)
+	}
+
+	userResp := dto.CreateUserAdminResp(*user)
+
+	return &userResp, nil
+}
+
+// DisableUser block the user from login and refreshing its token, its current sessions are revoked
+func (s UserAdminServiceImpl) DisableUser(ctx context.Context, actorId, userId uint) error {
+	ctx, span := tracing.Start(ctx, "UserAdminService.DisableUser")
+	defer span.End()
+
+	if actorId == userId {
+		return errors.BadRequest("you cannot disable yourself")
+	}
+
+	user, err := s.findUser(ctx, userId)
+	if err != nil {
+		return err
+	}
+	if user.DisabledAt.Valid {
+		return nil
+	}
+
+	user.DisabledAt = null.IntFrom(unixMilli())
+	err = s.transaction.RunWithTransaction(ctx, func() error {
+		err := s.userRepository.UpdateUsers(ctx, user, user.UserId, repositories.NewUsersSelectFields().DisabledAt())
+		if err != nil {
+			return err
+		}
+
+		return s.recordAudit(ctx, actorId, user.UserId, entities.AuditActionUserDisable, "")
+	})
+	if err != nil {
+		logger.Ctx(ctx).Err(err).Msg("error disable user")
+		return errors.Internal(err)
+	}
+
+	return s.revokeSessions(ctx, user)
+}
+
+func (s UserAdminServiceImpl) EnableUser(ctx context.Context, actorId, userId uint) error {
+	ctx, span := tracing.Start(ctx, "UserAdminService.EnableUser")
+	defer span.End()
+
+	user, err := s.findUser(ctx, userId)
+	if err != nil {
+		return err
+	}
+	if !user.DisabledAt.Valid {
+		return nil
+	}
+
+	user.DisabledAt = null.Int{}
+	err = s.transaction.RunWithTransaction(ctx, func() error {
+		err := s.userRepository.UpdateUsers(ctx, user, user.UserId, repositories.NewUsersSelectFields().DisabledAt())
+		if err != nil {
+			return err
+		}
+
+		return s.recordAudit(ctx, actorId, user.UserId, entities.AuditActionUserEnable, "")
+	})
+	if err != nil {
+		logger.Ctx(ctx).Err(err).Msg("error enable user")
+		return errors.Internal(err)
+	}
+
+	return nil
+}
+
+// ResetUserPassword replace the password with a temporary one and revoke every session of the user,
+// the user is asked to change the password after login with the temporary password
+func (s UserAdminServiceImpl) ResetUserPassword(ctx context.Context, actorId, userId uint) (*dto.UserPasswordResetRespBody, error) {
+	ctx, span := tracing.Start(ctx, "UserAdminService.ResetUserPassword")
+	defer span.End()
+
+	user, err := s.findUser(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	temporaryPassword, err := generateTemporaryPassword()
+	if err != nil {
+		logger.Ctx(ctx).Err(err).Msg("error generate temporary password")
+		return nil, errors.Internal(err)
+	}
+
+	hashedPassword, err := s.passwordHasher.Hash(temporaryPassword)
+	if err != nil {
+		logger.Ctx(ctx).Err(err).Msg("error hash password")
+		return nil, errors.Internal(err)
+	}
+
+	user.Password = hashedPassword
+	user.PasswordResetAt = null.IntFrom(unixMilli())
+	user.UpdatedAt = null.IntFrom(unixMilli())
+	err = s.transaction.RunWithTransaction(ctx, func() error {
+		fields := repositories.NewUsersSelectFields()
+		err := s.userRepository.UpdateUsers(ctx, user, user.UserId, fields.Password(), fields.PasswordResetAt(), fields.UpdatedAt())
+		if err != nil {
+			return err
+		}
+
+		return s.recordAudit(ctx, actorId, user.UserId, entities.AuditActionUserPasswordReset, "")
+	})
+	if err != nil {
+		logger.Ctx(ctx).Err(err).Msg("error reset user password")
+		return nil, errors.Internal(err)
+	}
+
+	if err := s.revokeSessions(ctx, user); err != nil {
+		return nil, err
+	}
+
+	return &dto.UserPasswordResetRespBody{TemporaryPassword: temporaryPassword}, nil
+}
+
+func (s UserAdminServiceImpl) DeleteUser(ctx context.Context, actorId, userId uint) error {
+	ctx, span := tracing.Start(ctx, "UserAdminService.DeleteUser")
+	defer span.End()
+
+	if actorId == userId {
+		return errors.BadRequest("you cannot delete yourself")
+	}
+
+	user, err := s.findUser(ctx, userId)
+	if err != nil {
+		return err
+	}
+
+	err = s.transaction.RunWithTransaction(ctx, func() error {
+		err := s.userRepository.DeleteUsers(ctx, user.UserId)
+		if err != nil {
+			return err
+		}
+
+		return s.recordAudit(ctx, actorId, user.UserId, entities.AuditActionUserDelete, "")
+	})
+	if err != nil {
+		logger.Ctx(ctx).Err(err).Msg("error delete user")
+		return errors.Internal(err)
+	}
+
+	return s.revokeSessions(ctx, user)
+}
+
+// UnlockUser remove the lock and the failed login counter of the user
+func (s UserAdminServiceImpl) UnlockUser(ctx context.Context, actorId, userId uint) error {
+	ctx, span := tracing.Start(ctx, "UserAdminService.UnlockUser")
+	defer span.End()
+
+	user, err := s.findUser(ctx, userId)
+	if err != nil {
+		return err
+	}
+
+	err = s.loginAttemptRepository.ResetLoginAttempt(ctx, loginAttemptAccountKey(user.Email))
+	if err != nil {
+		logger.Ctx(ctx).Err(err).Msg("error reset login attempt")
+		return errors.InternalServerError("failed to unlock user")
+	}
+
+	err = s.recordAudit(ctx, actorId, user.UserId, entities.AuditActionUserUnlock, "")
+	if err != nil {
+		logger.Ctx(ctx).Err(err).Msg("error record audit log")
+		return errors.Internal(err)
+	}
+
+	return nil
+}
+
+func (s UserAdminServiceImpl) ListUserAuditLogs(ctx context.Context, userId uint) ([]dto.UserAuditLogRespBody, error) {
+	ctx, span := tracing.Start(ctx, "UserAdminService.ListUserAuditLogs")
+	defer span.End()
+
+	auditLogs, err := s.userRepository.
+		FilterUserAuditLogs(repositories.NewUserAuditLogsFilter("AND").SetFilterByTargetUserFkid(userId, "=")).
+		OrderByUserAuditLogs([]repositories.Order{repositories.NewUserAuditLogsAuditLogIdOrder().SetDirection("DESC")}).
+		GetUserAuditLogsList(ctx)
+	if err != nil {
+		logger.Ctx(ctx).Err(err).Msg("error fetch user audit logs")
+		return nil, errors.Internal(err)
+	}
+
+	return dto.CreateUserAuditLogListResp(auditLogs), nil
+}
+
+func (s UserAdminServiceImpl) findUser(ctx context.Context, userId uint) (*entities.Users, error) {
+	user, err := s.userRepository.
+		FilterUsers(repositories.NewUsersFilter("AND").SetFilterByUserId(userId, "=")).
+		GetUsers(ctx)
+	if err != nil {
+		logger.Ctx(ctx).Err(err).Msg("error fetch user data")
+		return nil, notFoundOr(err, ErrUserNotFound)
+	}
+	return user, nil
+}
+
+func (s UserAdminServiceImpl) recordAudit(ctx context.Context, actorId uint, targetId int32, action, detail string) error {
+	_, err := s.userRepository.InsertUserAuditLogs(ctx, &entities.UserAuditLogs{
+		ActorUserFkid:  int32(actorId),
+		TargetUserFkid: targetId,
+		Action:         action,
+		Detail:         detail,
+		CreatedAt:      unixMilli(),
+	})
+	return err
+}
+
+// revokeSessions remove the refresh tokens of the user and deny the access tokens that are still alive
+func (s UserAdminServiceImpl) revokeSessions(ctx context.Context, user *entities.Users) error {
+	sessionIds, err := s.tokenStore.DeleteUserRefreshTokens(ctx, fmt.Sprintf("%d", user.UserId), "")
+	if err == nil {
+		err = s.tokenRevoker.RevokeSessions(ctx, sessionIds...)
+	}
+	if err != nil {
+		logger.Ctx(ctx).Err(err).Msg("error revoke user sessions")
+		return errors.Internal(err)
+	}
+	return nil
+}
+
+func generateTemporaryPassword() (string, error) {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,331 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"golang-starter/internal/logger"
+	"golang-starter/internal/protocols/http/errors"
+	"golang-starter/internal/utils/auth"
+	"golang-starter/internal/utils/tracing"
+	"golang-starter/src/modules/user/dto"
+	"golang-starter/src/modules/user/entities"
+	"golang-starter/src/modules/user/repositories"
+	"strings"
+	"time"
+
+	"github.com/guregu/null"
+)
+
+const (
+	// the api key is formatted as gsk_<prefix>_<secret>, the prefix is used to look the key up
+	// and the secret never leaves the client, only the sha256 of the whole key is stored
+	apiKeyPrefix       = "gsk_"
+	apiKeyPrefixBytes  = 8
+	apiKeySecretBytes  = 32
+	apiKeyNameMaxLen   = 100
+	maxApiKeysPerUser  = 20
+	apiKeyLastUsedStep = time.Minute
+)
+
+// UserApiKeyService manage the api keys of machine to machine clients, a key act as its owner limited to its scopes
+type UserApiKeyService interface {
+	CreateApiKey(ctx context.Context, userId uint, req dto.ApiKeyRequestCreateBody) (*dto.ApiKeySecretRespBody, error)
+	ListApiKeys(ctx context.Context, userId uint) ([]dto.ApiKeyRespBody, error)
+	RotateApiKey(ctx context.Context, userId, apiKeyId uint) (*dto.ApiKeySecretRespBody, error)
+	RevokeApiKey(ctx context.Context, userId, apiKeyId uint) error
+	AuthenticateApiKey(ctx context.Context, apiKey string) (*auth.ApiKeyIdentity, error)
+}
+
+type UserApiKeyServiceImpl struct {
+	userRepository repositories.Repositories
+}
+
+func NewUserApiKeyService(
+	userRepository repositories.Repositories,
+) *UserApiKeyServiceImpl {
+	return &UserApiKeyServiceImpl{
+		userRepository: userRepository,
+	}
+}
+
+func (s UserApiKeyServiceImpl) CreateApiKey(ctx context.Context, userId uint, req dto.ApiKeyRequestCreateBody) (*dto.ApiKeySecretRespBody, error) {
+	ctx, span := tracing.Start(ctx, "UserApiKeyService.CreateApiKey")
+	defer span.End()
+
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" || len(req.Name) > apiKeyNameMaxLen {
+		return nil, errors.BadRequest("name is required and must be at most 100 characters")
+	}
+	if len(req.Scopes) == 0 {
+		return nil, errors.BadRequest("at least one scope is required")
+	}
+	for _, scope := range req.Scopes {
+		if !validApiKeyScope(scope) {
+			return nil, errors.BadRequest("scope " + scope + " is not valid, the scopes are " + strings.Join(entities.ApiKeyScopes, ", "))
+		}
+	}
+	if req.ExpiredAt != nil && *req.ExpiredAt <= unixMilli() {
+		return nil, errors.BadRequest("expired_at must be in the future")
+	}
+
+	user, err := s.userRepository.
+		FilterUsers(repositories.NewUsersFilter("AND").SetFilterByUserId(userId, "=")).
+		GetUsers(ctx)
+	if err != nil {
+		logger.Ctx(ctx).Err(err).Msg("error fetch user data")
+		return nil, notFoundOr(err, ErrUserNotFound)
+	}
+	for _, scope := range req.Scopes {
+		if isApiKeyAdminScope(scope) && user.Role != entities.RoleAdmin {
+			return nil, errors.Forbidden("only admin can create a key with the " + scope + " scope")
+		}
+	}
+
+	count, err := s.userRepository.
+		FilterApiKeys(repositories.NewApiKeysFilter("AND").
+			SetFilterByUserFkid(userId, "=").
+			SetFilterByRevokedAt(nil, "IS NULL")).
+		GetApiKeysCount(ctx)
+	if err != nil {
+		logger.Ctx(ctx).Err(err).Msg("error count api keys")
+		return nil, errors.Internal(err)
+	}
+	if count >= maxApiKeysPerUser {
+		return nil, ErrApiKeyLimit
+	}
+
+	prefix, key, err := generateApiKey()
+	if err != nil {
+		return nil, errors.Internal(err)
+	}
+
+	apiKey := &entities.ApiKeys{
+		UserFkid:  user.UserId,
+		Name:      req.Name,
+		Prefix:    prefix,
+		KeyHash:   hashApiKey(key),
+		Scopes:    strings.Join(req.Scopes, " "),
+		ExpiredAt: null.IntFromPtr(req.ExpiredAt),
+		CreatedAt: unixMilli(),
+	}
+
+	res, err := s.userRepository.InsertApiKeys(ctx, apiKey)
+	if err != nil {
+		logger.Ctx(ctx).Err(err).Msg("error insert api key")
+		return nil, errors.Internal(err)
+	}
+	lastInsertedId, err := res.LastInsertId()
+	if err != nil {
+		return nil, errors.Internal(err)
+	}
+	apiKey.ApiKeyId = int32(lastInsertedId)
+
+	return &dto.ApiKeySecretRespBody{
+		ApiKeyRespBody: dto.CreateApiKeyResp(*apiKey),
+		Key:            key,
+	}, nil
+}
+
+func (s UserApiKeyServiceImpl) ListApiKeys(ctx context.Context, userId uint) ([]dto.ApiKeyRespBody, error) {
+	ctx, span := tracing.Start(ctx, "UserApiKeyService.ListApiKeys")
+	defer span.End()
+
+	apiKeys, err := s.userRepository.
+		FilterApiKeys(repositories.NewApiKeysFilter("AND").SetFilterByUserFkid(userId, "=")).
+		OrderByApiKeys([]repositories.Order{repositories.NewApiKeysApiKeyIdOrder().SetDirection("DESC")}).
+		GetApiKeysList(ctx)
+	if err != nil {
+		logger.Ctx(ctx).Err(err).Msg("error fetch api keys")
+		return nil, errors.Internal(err)
+	}
+
+	return dto.CreateApiKeyListResp(apiKeys), nil
+}
+
+// RotateApiKey replace the secret of the key, the old secret stops working immediately
+func (s UserApiKeyServiceImpl) RotateApiKey(ctx context.Context, userId, apiKeyId uint) (*dto.ApiKeySecretRespBody, error) {
+	ctx, span := tracing.Start(ctx, "UserApiKeyService.RotateApiKey")
+	defer span.End()
+
+	apiKey, err := s.findApiKey(ctx, userId, apiKeyId)
+	if err != nil {
+		return nil, err
+	}
+	if apiKey.RevokedAt.Valid {
+		return nil, ErrApiKeyAlreadyRevoked
+	}
+
+	prefix, key, err := generateApiKey()
+	if err != nil {
+		return nil, errors.Internal(err)
+	}
+
+	apiKey.Prefix = prefix
+	apiKey.KeyHash = hashApiKey(key)
+	apiKey.UpdatedAt = null.IntFrom(unixMilli())
+
+	fields := repositories.NewApiKeysSelectFields()
+	err = s.userRepository.UpdateApiKeys(ctx, apiKey, apiKey.ApiKeyId, fields.Prefix(), fields.KeyHash(), fields.UpdatedAt())
+	if err != nil {
+		logger.Ctx(ctx).Err(err).Msg("error rotate api key")
+		return nil, errors.Internal(err)
+	}
+
+	return &dto.ApiKeySecretRespBody{
+		ApiKeyRespBody: dto.CreateApiKeyResp(*apiKey),
+		Key:            key,
+	}, nil
+}
+
+// RevokeApiKey keep the key for the history, but it can't be used anymore
+func (s UserApiKeyServiceImpl) RevokeApiKey(ctx context.Context, userId, apiKeyId uint) error {
+	ctx, span := tracing.Start(ctx, "UserApiKeyService.RevokeApiKey")
+	defer span.End()
+
+	apiKey, err := s.findApiKey(ctx, userId, apiKeyId)
+	if err != nil {
+		return err
+	}
+	if apiKey.RevokedAt.Valid {
+		return nil
+	}
+
+	apiKey.RevokedAt = null.IntFrom(unixMilli())
+	apiKey.UpdatedAt = null.IntFrom(unixMilli())
+
+	fields := repositories.NewApiKeysSelectFields()
+	err = s.userRepository.UpdateApiKeys(ctx, apiKey, apiKey.ApiKeyId, fields.RevokedAt(), fields.UpdatedAt())
+	if err != nil {
+		logger.Ctx(ctx).Err(err).Msg("error revoke api key")
+		return errors.Internal(err)
+	}
+
+	return nil
+}
+
+// AuthenticateApiKey return the owner of the key when the key is valid, it's used by the JwtOrApiKey middleware
+func (s UserApiKeyServiceImpl) AuthenticateApiKey(ctx context.Context, key string) (*auth.ApiKeyIdentity, error) {
+	ctx, span := tracing.Start(ctx, "UserApiKeyService.AuthenticateApiKey")
+	defer span.End()
+
+	prefix, ok := parseApiKey(key)
+	if !ok {
+		return nil, ErrApiKeyInvalid
+	}
+
+	apiKey, err := s.userRepository.
+		FilterApiKeys(repositories.NewApiKeysFilter("AND").SetFilterByPrefix(prefix, "=")).
+		GetApiKeys(ctx)
+	if err != nil {
+		return nil, notFoundOr(err, ErrApiKeyInvalid)
+	}
+	if subtle.ConstantTimeCompare([]byte(apiKey.KeyHash), []byte(hashApiKey(key))) != 1 {
+		return nil, ErrApiKeyInvalid
+	}
+
+	now := unixMilli()
+	if apiKey.RevokedAt.Valid {
+		return nil, ErrApiKeyRevoked
+	}
+	if apiKey.ExpiredAt.Valid && apiKey.ExpiredAt.Int64 <= now {
+		return nil, ErrApiKeyExpired
+	}
+
+	user, err := s.userRepository.
+		FilterUsers(repositories.NewUsersFilter("AND").SetFilterByUserId(apiKey.UserFkid, "=")).
+		GetUsers(ctx)
+	if err != nil {
+		return nil, notFoundOr(err, ErrApiKeyInvalid)
+	}
+	if user.DisabledAt.Valid {
+		return nil, ErrUserDisabled
+	}
+
+	// the last used time is only written once per step, so a busy client doesn't update the row on every request
+	if !apiKey.LastUsedAt.Valid || now-apiKey.LastUsedAt.Int64 >= apiKeyLastUsedStep.Milliseconds() {
+		apiKey.LastUsedAt = null.IntFrom(now)
+		err = s.userRepository.UpdateApiKeys(ctx, apiKey, apiKey.ApiKeyId, repositories.NewApiKeysSelectFields().LastUsedAt())
+		if err != nil {
+			logger.Ctx(ctx).Err(err).Msg("error update api key last used")
+		}
+	}
+
+	return &auth.ApiKeyIdentity{
+		ApiKeyId: apiKey.ApiKeyId,
+		UserId:   user.UserId,
+		Role:     user.Role,
+		Scopes:   strings.Fields(apiKey.Scopes),
+	}, nil
+}
+
+func (s UserApiKeyServiceImpl) findApiKey(ctx context.Context, userId, apiKeyId uint) (*entities.ApiKeys, error) {
+	apiKey, err := s.userRepository.
+		FilterApiKeys(repositories.NewApiKeysFilter("AND").
+			SetFilterByApiKeyId(apiKeyId, "=").
+			SetFilterByUserFkid(userId, "=")).
+		GetApiKeys(ctx)
+	if err != nil {
+		logger.Ctx(ctx).Err(err).Msg("error fetch api key")
+		return nil, notFoundOr(err, ErrApiKeyNotFound)
+	}
+	return apiKey, nil
+}
+
+func validApiKeyScope(scope string) bool {
+	for _, valid := range entities.ApiKeyScopes {
+		if scope == valid {
+			return true
+		}
+	}
+	return false
+}
+
+func isApiKeyAdminScope(scope string) bool {
+	for _, adminScope := range entities.ApiKeyAdminScopes {
+		if scope == adminScope {
+			return true
+		}
+	}
+	return false
+}
+
+// generateApiKey return the lookup prefix and the whole key
+func generateApiKey() (string, string, error) {
+	prefixBytes := make([]byte, apiKeyPrefixBytes)
+	if _, err := rand.Read(prefixBytes); err != nil {
+		return "", "", err
+	}
+	secretBytes := make([]byte, apiKeySecretBytes)
+	if _, err := rand.Read(secretBytes); err != nil {
+		return "", "", err
+	}
+
+	prefix := hex.EncodeToString(prefixBytes)
+	return prefix, apiKeyPrefix + prefix + "_" + base64.RawURLEncoding.EncodeToString(secretBytes), nil
+}
+
+func parseApiKey(key string) (string, bool) {
+	prefixLen := apiKeyPrefixBytes * 2
+	if !strings.HasPrefix(key, apiKeyPrefix) || len(key) < len(apiKeyPrefix)+prefixLen+2 {
+		return "", false
+	}
+
+	rest := key[len(apiKeyPrefix):]
+	if rest[prefixLen] != '_' {
+		return "", false
+	}
+	prefix := rest[:prefixLen]
+	if _, err := hex.DecodeString(prefix); err != nil {
+		return "", false
+	}
+	return prefix, true
+}
+
+func hashApiKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
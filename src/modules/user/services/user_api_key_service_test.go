@@ -0,0 +1,62 @@
+package services
+
+import (
+	"context"
+	"golang-starter/internal/protocols/http/errors"
+	"golang-starter/src/modules/user/dto"
+	"golang-starter/src/modules/user/entities"
+	"golang-starter/src/modules/user/repositories"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeRepository return user and count it already has maxApiKeysPerUser api keys, the other calls aren't expected
+type fakeRepository struct {
+	repositories.Repositories
+	user *entities.Users
+}
+
+func (r fakeRepository) FilterUsers(repositories.Filter) repositories.RepositoryUsersQuery {
+	return r
+}
+
+func (r fakeRepository) GetUsers(context.Context) (*entities.Users, error) { return r.user, nil }
+
+func (r fakeRepository) FilterApiKeys(repositories.Filter) repositories.RepositoryApiKeysQuery {
+	return r
+}
+
+func (r fakeRepository) GetApiKeysCount(context.Context) (int, error) {
+	return maxApiKeysPerUser, nil
+}
+
+func TestCreateApiKeyAdminScopes(t *testing.T) {
+	tests := []struct {
+		name   string
+		role   string
+		scope  string
+		status int
+	}{
+		{"user with the introspect scope", entities.RoleUser, entities.ApiKeyScopeTokensIntrospect, http.StatusForbidden},
+		{"user with the admin scope", entities.RoleUser, entities.ApiKeyScopeAdmin, http.StatusForbidden},
+		// the request goes on to the limit of the keys once the scopes are allowed
+		{"user with a user scope", entities.RoleUser, entities.ApiKeyScopeUsersRead, http.StatusConflict},
+		{"admin with the introspect scope", entities.RoleAdmin, entities.ApiKeyScopeTokensIntrospect, http.StatusConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewUserApiKeyService(fakeRepository{user: &entities.Users{UserId: 1, Role: tt.role}})
+
+			_, err := s.CreateApiKey(context.Background(), 1, dto.ApiKeyRequestCreateBody{
+				Name:   "ci",
+				Scopes: []string{tt.scope},
+			})
+			var respErr *errors.RespError
+			if assert.True(t, errors.As(err, &respErr), "%v", err) {
+				assert.Equal(t, tt.status, respErr.Code)
+			}
+		})
+	}
+}
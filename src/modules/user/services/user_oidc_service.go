@@ -0,0 +1,323 @@
+package services
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"golang-starter/config"
+	"golang-starter/infrastructures/db/transaction"
+	"golang-starter/internal/logger"
+	"golang-starter/internal/protocols/http/errors"
+	"golang-starter/internal/utils/auth"
+	"golang-starter/internal/utils/oidc"
+	"golang-starter/internal/utils/password"
+	"golang-starter/internal/utils/tracing"
+	"golang-starter/src/modules/user/dto"
+	"golang-starter/src/modules/user/entities"
+	"golang-starter/src/modules/user/repositories"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	oidcRandomLength        = 32
+	defaultOidcStateExpired = 10 * time.Minute
+)
+
+// the state is the key of the stored oidc state, only the states of oidc.RandomString are looked up
+var oidcStatePattern = regexp.MustCompile(`^[A-Za-z0-9_-]{43}$`)
+
+var usernameSanitizer = regexp.MustCompile(`[^a-z0-9._]`)
+
+// UserOidcService sign in the user with an external openid connect provider
+type UserOidcService interface {
+	OidcAuthURL(ctx context.Context, provider string) (*dto.UserOidcAuth, error)
+	OidcCallback(ctx context.Context, provider, state, stateHash, code string) (*dto.UserTokenRespBody, error)
+}
+
+type UserOidcServiceImpl struct {
+	userRepository      repositories.Repositories
+	oidcStateRepository repositories.OidcStateRepository
+	oidcRegistry        *oidc.Registry
+	jwtAuth             auth.JwtToken
+	transaction         *transaction.TransactionImpl
+	passwordHasher      password.Hasher
+}
+
+func NewUserOidcService(
+	userRepository repositories.Repositories,
+	oidcStateRepository repositories.OidcStateRepository,
+	oidcRegistry *oidc.Registry,
+	jwtAuth auth.JwtToken,
+	transaction *transaction.TransactionImpl,
+	passwordHasher password.Hasher,
+) *UserOidcServiceImpl {
+	return &UserOidcServiceImpl{
+		userRepository:      userRepository,
+		oidcStateRepository: oidcStateRepository,
+		oidcRegistry:        oidcRegistry,
+		jwtAuth:             jwtAuth,
+		transaction:         transaction,
+		passwordHasher:      passwordHasher,
+	}
+}
+
+// OidcAuthURL start the authorization code flow, the state, nonce and PKCE verifier are saved until the callback.
+// the hash of the state must be kept by the browser and sent back to the callback
+func (s UserOidcServiceImpl) OidcAuthURL(ctx context.Context, providerName string) (*dto.UserOidcAuth, error) {
+	ctx, span := tracing.Start(ctx, "UserOidcService.OidcAuthURL")
+	defer span.End()
+
+	if !s.oidcRegistry.Has(providerName) {
+		return nil, ErrOidcProviderNotFound
+	}
+
+	provider, err := s.oidcRegistry.Provider(ctx, providerName)
+	if err != nil {
+		logger.Ctx(ctx).Err(err).Msg("error discover oidc provider")
+		return nil, errors.InternalServerError("oidc provider is not available")
+	}
+
+	state, err := oidc.RandomString(oidcRandomLength)
+	if err != nil {
+		return nil, errors.Internal(err)
+	}
+	nonce, err := oidc.RandomString(oidcRandomLength)
+	if err != nil {
+		return nil, errors.Internal(err)
+	}
+	codeVerifier, err := oidc.RandomString(oidcRandomLength)
+	if err != nil {
+		return nil, errors.Internal(err)
+	}
+
+	stateExpired := config.Get().Auth.Oidc.StateExpired
+	if stateExpired <= 0 {
+		stateExpired = defaultOidcStateExpired
+	}
+	expired := time.Now().Add(stateExpired).Unix()
+
+	err = s.oidcStateRepository.SaveOidcState(ctx, state, entities.OidcState{
+		Provider:     providerName,
+		Nonce:        nonce,
+		CodeVerifier: codeVerifier,
+		Expired:      expired,
+	}, stateExpired)
+	if err != nil {
+		logger.Ctx(ctx).Err(err).Msg("error save oidc state")
+		return nil, errors.Internal(err)
+	}
+
+	return &dto.UserOidcAuth{
+		AuthURL:   provider.AuthCodeURL(state, nonce, oidc.CodeChallenge(codeVerifier)),
+		StateHash: oidc.StateHash(state),
+		Expired:   expired,
+	}, nil
+}
+
+// OidcCallback finish the authorization code flow, then sign in the user that is linked to the provider subject.
+// when there is no link yet, the user with the same verified email is linked or a new user is created.
+// stateHash is the one the browser kept since OidcAuthURL, so a state can't be finished by another browser
+func (s UserOidcServiceImpl) OidcCallback(ctx context.Context, providerName, state, stateHash, code string) (*dto.UserTokenRespBody, error) {
+	ctx, span := tracing.Start(ctx, "UserOidcService.OidcCallback")
+	defer span.End()
+
+	if !oidcStatePattern.MatchString(state) || code == "" {
+		return nil, ErrOidcStateInvalid.WithMessage("state or code is not valid")
+	}
+	if subtle.ConstantTimeCompare([]byte(oidc.StateHash(state)), []byte(stateHash)) != 1 {
+		return nil, ErrOidcStateInvalid.WithMessage("state isn't the one of this browser")
+	}
+
+	oidcState, err := s.oidcStateRepository.TakeOidcState(ctx, state)
+	if err != nil {
+		if !errors.Is(err, repositories.ErrNotFound) {
+			logger.Ctx(ctx).Err(err).Msg("error take oidc state")
+		}
+		return nil, ErrOidcStateInvalid
+	}
+	if oidcState.Provider != providerName || oidcState.Expired < time.Now().Unix() {
+		return nil, ErrOidcStateInvalid
+	}
+
+	provider, err := s.oidcRegistry.Provider(ctx, providerName)
+	if err != nil {
+		logger.Ctx(ctx).Err(err).Msg("error discover oidc provider")
+		return nil, errors.InternalServerError("oidc provider is not available")
+	}
+
+	token, err := provider.Exchange(ctx, code, oidcState.CodeVerifier)
+	if err != nil {
+		logger.Ctx(ctx).Err(err).Msg("error exchange oidc code")
+		return nil, ErrOidcSignInFailed.WithMessage("failed to sign in with " + providerName)
+	}
+
+	claims, err := provider.VerifyIDToken(ctx, token.IDToken, oidcState.Nonce)
+	if err != nil {
+		logger.Ctx(ctx).Err(err).Msg("error verify oidc id token")
+		return nil, ErrOidcSignInFailed.WithMessage("failed to sign in with " + providerName)
+	}
+
+	user, err := s.findOrLinkUser(ctx, providerName, claims)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.DisabledAt.Valid {
+		return nil, ErrUserDisabled
+	}
+
+	userToken, err := s.jwtAuth.Sign(ctx, auth.Claims{
+		Subject: strconv.Itoa(int(user.UserId)),
+		Role:    user.Role,
+	})
+	if err != nil {
+		logger.Ctx(ctx).Err(err).Msg("error sign token")
+		return nil, errors.InternalServerError("cannot sign the token")
+	}
+
+	userTokenResp := dto.CreateUserTokenResp(userToken, *user)
+
+	return &userTokenResp, nil
+}
+
+func (s UserOidcServiceImpl) findOrLinkUser(ctx context.Context, providerName string, claims *oidc.IDTokenClaims) (*entities.Users, error) {
+	identity, err := s.userRepository.
+		FilterUserIdentities(repositories.NewUserIdentitiesFilter("AND").
+			SetFilterByProvider(providerName, "=").
+			SetFilterBySubject(claims.Subject, "=")).
+		GetUserIdentities(ctx)
+	if err != nil && !errors.Is(err, repositories.ErrNotFound) {
+		logger.Ctx(ctx).Err(err).Msg("error fetch user identity")
+		return nil, errors.Internal(err)
+	}
+	if err == nil {
+		user, err := s.userRepository.
+			FilterUsers(repositories.NewUsersFilter("AND").SetFilterByUserId(identity.UserFkid, "=")).
+			GetUsers(ctx)
+		if err != nil {
+			logger.Ctx(ctx).Err(err).Msg("error fetch linked user")
+			return nil, notFoundOr(err, ErrUserNotFound)
+		}
+		return user, nil
+	}
+
+	// only a verified email can be trusted to link an account, otherwise anyone could take over it
+	if claims.Email == "" || !claims.EmailVerified {
+		return nil, ErrOidcSignInFailed.WithMessage("the provider didn't return a verified email")
+	}
+
+	users, err := s.userRepository.
+		FilterUsers(repositories.NewUsersFilter("AND").SetFilterByEmail(claims.Email, "=")).
+		GetUsersList(ctx)
+	if err != nil {
+		logger.Ctx(ctx).Err(err).Msg("error fetch user data")
+		return nil, errors.Internal(err)
+	}
+
+	var user *entities.Users
+	err = s.transaction.RunWithTransaction(ctx, func() error {
+		if len(users) > 0 {
+			user = users[0]
+		} else {
+			newUser, err := s.newOidcUser(ctx, claims)
+			if err != nil {
+				return err
+			}
+			user = newUser
+		}
+
+		_, err := s.userRepository.InsertUserIdentities(ctx, &entities.UserIdentities{
+			UserFkid:  user.UserId,
+			Provider:  providerName,
+			Subject:   claims.Subject,
+			Email:     claims.Email,
+			CreatedAt: unixMilli(),
+		})
+		return err
+	})
+	if err != nil {
+		logger.Ctx(ctx).Err(err).Msg("error link oidc identity")
+		return nil, errors.Internal(err)
+	}
+
+	return user, nil
+}
+
+// newOidcUser create a user that can only sign in with the provider, its password is random and never shown
+func (s UserOidcServiceImpl) newOidcUser(ctx context.Context, claims *oidc.IDTokenClaims) (*entities.Users, error) {
+	username, err := s.availableUsername(ctx, claims.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	randomPassword, err := oidc.RandomString(oidcRandomLength)
+	if err != nil {
+		return nil, err
+	}
+	hashedPassword, err := s.passwordHasher.Hash(randomPassword)
+	if err != nil {
+		return nil, err
+	}
+
+	name := claims.Name
+	if name == "" {
+		name = username
+	}
+
+	user := &entities.Users{
+		Email:     claims.Email,
+		Username:  username,
+		Name:      name,
+		Photo:     claims.Picture,
+		Role:      entities.RoleUser,
+		Password:  hashedPassword,
+		CreatedAt: unixMilli(),
+	}
+
+	res, err := s.userRepository.InsertUsers(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	lastInsertedId, err := res.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+	user.UserId = int32(lastInsertedId)
+
+	return user, nil
+}
+
+// availableUsername derive the username from the email, a random suffix is added when it's already used
+func (s UserOidcServiceImpl) availableUsername(ctx context.Context, email string) (string, error) {
+	base := usernameSanitizer.ReplaceAllString(strings.ToLower(strings.SplitN(email, "@", 2)[0]), "")
+	if base == "" {
+		base = "user"
+	}
+	if len(base) > 50 {
+		base = base[:50]
+	}
+
+	username := base
+	for i := 0; i < 5; i++ {
+		count, err := s.userRepository.
+			FilterUsers(repositories.NewUsersFilter("AND").SetFilterByUsername(username, "=")).
+			GetUsersCount(ctx)
+		if err != nil {
+			return "", err
+		}
+		if count == 0 {
+			return username, nil
+		}
+
+		suffix, err := oidc.RandomString(3)
+		if err != nil {
+			return "", err
+		}
+		username = fmt.Sprintf("%s_%s", base, usernameSanitizer.ReplaceAllString(strings.ToLower(suffix), "0"))
+	}
+
+	return "", fmt.Errorf("cannot find an available username for %s", base)
+}
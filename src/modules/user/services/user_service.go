@@ -2,47 +2,81 @@ package services
 
 import (
 	"context"
+	"fmt"
+	"golang-starter/config"
+	"golang-starter/internal/logger"
 	"golang-starter/internal/protocols/http/errors"
 	"golang-starter/internal/utils/auth"
+	"golang-starter/internal/utils/password"
+	"golang-starter/internal/utils/tracing"
 	"golang-starter/src/modules/user/dto"
+	"golang-starter/src/modules/user/entities"
 	"golang-starter/src/modules/user/repositories"
+	"strconv"
+	"strings"
 	"time"
 
-	"github.com/golang-jwt/jwt"
+	"github.com/guregu/null"
 	"github.com/rs/zerolog/log"
-	"golang.org/x/crypto/bcrypt"
 )
 
+const minPasswordLength = 8
+
 type UserService interface {
 	FindByID(ctx context.Context, id uint) (*dto.UserRespBody, error)
 	UserLogin(ctx context.Context, req dto.UserRequestLoginBody) (*dto.UserTokenRespBody, error)
-	UserRefreshToken(ctx context.Context, userId string) (*dto.UserTokenRespBody, error)
+	UserRefreshToken(ctx context.Context, userId uint, sessionId, tokenId string) (*dto.UserTokenRespBody, error)
+	UpdateProfile(ctx context.Context, userId uint, req dto.UserRequestUpdateBody) (*dto.UserRespBody, error)
+	ChangePassword(ctx context.Context, userId uint, sessionId string, req dto.UserRequestChangePasswordBody) error
 }
 
 type UserServiceImpl struct {
-	userRepository repositories.Repositories
-	jwtAuth        auth.JwtToken
+	userRepository         repositories.Repositories
+	loginAttemptRepository repositories.LoginAttemptRepository
+	jwtAuth                auth.JwtToken
+	passwordHasher         password.Hasher
+	tokenRevoker           auth.TokenRevoker
+	tokenStore             auth.TokenStore
+	// dummyPasswordHash is verified when the email of a login isn't found,
+	// so the response time doesn't tell whether the email is registered
+	dummyPasswordHash string
 }
 
 func NewUserService(
 	jwtAuth auth.JwtToken,
 	userRepository repositories.Repositories,
-	userScribleRepository repositories.UserScribleRepository,
+	loginAttemptRepository repositories.LoginAttemptRepository,
+	passwordHasher password.Hasher,
+	tokenRevoker auth.TokenRevoker,
+	tokenStore auth.TokenStore,
 ) *UserServiceImpl {
+	dummyPasswordHash, err := passwordHasher.Hash("dummy password")
+	if err != nil {
+		log.Err(err).Msg("error hash dummy password")
+	}
+
 	return &UserServiceImpl{
-		userRepository: userRepository,
-		jwtAuth:        jwtAuth,
+		userRepository:         userRepository,
+		loginAttemptRepository: loginAttemptRepository,
+		jwtAuth:                jwtAuth,
+		passwordHasher:         passwordHasher,
+		tokenRevoker:           tokenRevoker,
+		tokenStore:             tokenStore,
+		dummyPasswordHash:      dummyPasswordHash,
 	}
 }
 
 func (s UserServiceImpl) FindByID(ctx context.Context, userId uint) (*dto.UserRespBody, error) {
+	ctx, span := tracing.Start(ctx, "UserService.FindByID")
+	defer span.End()
+
 	user, err := s.userRepository.
 		FilterUsers(repositories.NewUsersFilter("AND").SetFilterByUserId(userId, "=")).
 		GetUsers(ctx)
 
 	if err != nil {
-		log.Err(err).Msg("error fetch user data")
-		return nil, errors.FindErrorType(err)
+		logger.Ctx(ctx).Err(err).Msg("error fetch user data")
+		return nil, notFoundOr(err, ErrUserNotFound)
 	}
 
 	userResp := dto.CreateUserResp(*user)
@@ -51,43 +85,329 @@ func (s UserServiceImpl) FindByID(ctx context.Context, userId uint) (*dto.UserRe
 }
 
 func (s UserServiceImpl) UserLogin(ctx context.Context, req dto.UserRequestLoginBody) (*dto.UserTokenRespBody, error) {
+	ctx, span := tracing.Start(ctx, "UserService.UserLogin")
+	defer span.End()
+
+	accountKey, ipKey := loginAttemptAccountKey(req.Email), loginAttemptIpKey(req.IP)
+	if err := s.checkLoginAttempt(ctx, accountKey, ipKey); err != nil {
+		return nil, err
+	}
 
 	user, err := s.userRepository.
 		FilterUsers(repositories.NewUsersFilter("AND").SetFilterByEmail(req.Email, "=")).
 		GetUsers(ctx)
+	if err != nil && !errors.Is(err, repositories.ErrNotFound) {
+		logger.Ctx(ctx).Err(err).Msg("error fetch user data")
+		return nil, errors.Internal(err)
+	}
 	if err != nil {
-		log.Err(err).Msg("error fetch user data")
-		return nil, errors.FindErrorType(err)
+		// an unknown email take as long as a wrong password
+		s.passwordHasher.Verify(s.dummyPasswordHash, req.Password)
+		return nil, s.recordLoginFailure(ctx, accountKey, ipKey, ErrInvalidCredentials)
 	}
-	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password))
+	match, err := s.passwordHasher.Verify(user.Password, req.Password)
 	if err != nil {
-		return nil, errors.Unauthorization("email and password didn't match")
+		logger.Ctx(ctx).Err(err).Msg("error verify user password")
+	}
+	if !match {
+		return nil, s.recordLoginFailure(ctx, accountKey, ipKey, ErrInvalidCredentials)
+	}
+
+	if err := s.loginAttemptRepository.ResetLoginAttempt(ctx, accountKey); err != nil {
+		logger.Ctx(ctx).Err(err).Msg("error reset login attempt")
 	}
 
-	userToken := s.jwtAuth.SignRSA(jwt.MapClaims{
-		"id": user.UserId,
+	if user.DisabledAt.Valid {
+		return nil, ErrUserDisabled
+	}
+
+	s.rehashPassword(ctx, user, req.Password)
+
+	userToken, err := s.jwtAuth.Sign(ctx, auth.Claims{
+		Subject: strconv.Itoa(int(user.UserId)),
+		Role:    user.Role,
 	})
+	if err != nil {
+		logger.Ctx(ctx).Err(err).Msg("error sign token")
+		return nil, errors.InternalServerError("cannot sign the token")
+	}
 
-	token := dto.UserTokenRespBody(userToken)
+	token := dto.CreateUserTokenResp(userToken, *user)
 
 	return &token, nil
 }
 
-func (s UserServiceImpl) UserRefreshToken(ctx context.Context, userId string) (*dto.UserTokenRespBody, error) {
-	refreshToken, err := s.userRepository.FindUserRefreshToken(userId)
+// UserRefreshToken exchange the refresh token tokenId of the session for new tokens, the refresh token can only be
+// exchanged once. the session is revoked when an exchanged refresh token is presented again
+func (s UserServiceImpl) UserRefreshToken(ctx context.Context, userId uint, sessionId, tokenId string) (*dto.UserTokenRespBody, error) {
+	ctx, span := tracing.Start(ctx, "UserService.UserRefreshToken")
+	defer span.End()
+
+	err := s.jwtAuth.VerifyRefreshToken(ctx, fmt.Sprintf("%d", userId), sessionId, tokenId)
+	switch {
+	case err == nil:
+	case errors.Is(err, auth.ErrRefreshTokenReused):
+		logger.Ctx(ctx).Warn().Str("session_id", sessionId).Msg("refresh token is reused, the session is revoked")
+		if err := s.tokenRevoker.RevokeSessions(ctx, sessionId); err != nil {
+			logger.Ctx(ctx).Err(err).Msg("error revoke reused session")
+			return nil, errors.Internal(err)
+		}
+		return nil, errors.ErrTokenInvalid.WithMessage("refresh token is already used")
+	case errors.Is(err, auth.ErrTokenExpired):
+		return nil, errors.ErrTokenExpired
+	case errors.Is(err, auth.ErrRefreshTokenNotFound):
+		return nil, errors.ErrTokenInvalid
+	default:
+		logger.Ctx(ctx).Err(err).Msg("error verify refresh token")
+		return nil, errors.Internal(err)
+	}
+
+	user, err := s.userRepository.
+		FilterUsers(repositories.NewUsersFilter("AND").SetFilterByUserId(userId, "=")).
+		GetUsers(ctx)
 	if err != nil {
-		return nil, errors.Unauthorization("token is not valid")
+		logger.Ctx(ctx).Err(err).Msg("error fetch user data")
+		return nil, errors.ErrTokenInvalid
 	}
 
-	if refreshToken.Expired < time.Now().Unix() {
-		return nil, errors.Unauthorization("token is expired")
+	if user.DisabledAt.Valid {
+		return nil, ErrUserDisabled
 	}
 
-	userToken := s.jwtAuth.SignRSA(jwt.MapClaims{
-		"id": userId,
+	userToken, err := s.jwtAuth.Sign(ctx, auth.Claims{
+		Subject:   strconv.Itoa(int(user.UserId)),
+		Role:      user.Role,
+		SessionID: sessionId,
 	})
+	if err != nil {
+		logger.Ctx(ctx).Err(err).Msg("error sign token")
+		return nil, errors.InternalServerError("cannot sign the token")
+	}
 
-	token := dto.UserTokenRespBody(userToken)
+	token := dto.CreateUserTokenResp(userToken, *user)
 
 	return &token, nil
 }
+
+func (s UserServiceImpl) UpdateProfile(ctx context.Context, userId uint, req dto.UserRequestUpdateBody) (*dto.UserRespBody, error) {
+	ctx, span := tracing.Start(ctx, "UserService.UpdateProfile")
+	defer span.End()
+
+	user, err := s.userRepository.
+		FilterUsers(repositories.NewUsersFilter("AND").SetFilterByUserId(userId, "=")).
+		GetUsers(ctx)
+	if err != nil {
+		logger.Ctx(ctx).Err(err).Msg("error fetch user data")
+		return nil, notFoundOr(err, ErrUserNotFound)
+	}
+
+	fields := repositories.NewUsersSelectFields()
+	var updatedFields []repositories.UsersField
+	if req.Name != nil {
+		if strings.TrimSpace(*req.Name) == "" {
+			return nil, errors.BadRequest("name cannot be empty")
+		}
+		user.Name = *req.Name
+		updatedFields = append(updatedFields, fields.Name())
+	}
+	if req.Username != nil && *req.Username != user.Username {
+		if strings.TrimSpace(*req.Username) == "" {
+			return nil, errors.BadRequest("username cannot be empty")
+		}
+		count, err := s.userRepository.
+			FilterUsers(repositories.NewUsersFilter("AND").
+				SetFilterByUsername(*req.Username, "=").
+				SetFilterByUserId(userId, "!=")).
+			GetUsersCount(ctx)
+		if err != nil {
+			logger.Ctx(ctx).Err(err).Msg("error count user by username")
+			return nil, errors.Internal(err)
+		}
+		if count > 0 {
+			return nil, ErrUsernameUsed
+		}
+		user.Username = *req.Username
+		updatedFields = append(updatedFields, fields.Username())
+	}
+	if req.Photo != nil {
+		user.Photo = *req.Photo
+		updatedFields = append(updatedFields, fields.Photo())
+	}
+
+	if len(updatedFields) > 0 {
+		user.UpdatedAt = null.IntFrom(unixMilli())
+		updatedFields = append(updatedFields, fields.UpdatedAt())
+
+		err = s.userRepository.UpdateUsers(ctx, user, user.UserId, updatedFields...)
+		if err != nil {
+			logger.Ctx(ctx).Err(err).Msg("error update user data")
+			return nil, errors.Internal(err)
+		}
+	}
+
+	userResp := dto.CreateUserResp(*user)
+
+	return &userResp, nil
+}
+
+// ChangePassword replace the password after checking the current one,
+// then revoke every session of the user except the one that change the password
+func (s UserServiceImpl) ChangePassword(ctx context.Context, userId uint, sessionId string, req dto.UserRequestChangePasswordBody) error {
+	ctx, span := tracing.Start(ctx, "UserService.ChangePassword")
+	defer span.End()
+
+	user, err := s.userRepository.
+		FilterUsers(repositories.NewUsersFilter("AND").SetFilterByUserId(userId, "=")).
+		GetUsers(ctx)
+	if err != nil {
+		logger.Ctx(ctx).Err(err).Msg("error fetch user data")
+		return notFoundOr(err, ErrUserNotFound)
+	}
+
+	match, err := s.passwordHasher.Verify(user.Password, req.CurrentPassword)
+	if err != nil {
+		logger.Ctx(ctx).Err(err).Msg("error verify user password")
+	}
+	if !match {
+		return ErrPasswordMismatch
+	}
+
+	if len(req.NewPassword) < minPasswordLength {
+		return ErrPasswordTooShort.WithMessage(fmt.Sprintf("new password must have at least %d characters", minPasswordLength))
+	}
+
+	hashedPassword, err := s.passwordHasher.Hash(req.NewPassword)
+	if err != nil {
+		logger.Ctx(ctx).Err(err).Msg("error hash password")
+		return errors.Internal(err)
+	}
+
+	// a new password also fulfill the reset that is forced by admin
+	user.Password = hashedPassword
+	user.UpdatedAt = null.IntFrom(unixMilli())
+	user.PasswordResetAt = null.Int{}
+	fields := repositories.NewUsersSelectFields()
+	err = s.userRepository.UpdateUsers(ctx, user, user.UserId, fields.Password(), fields.UpdatedAt(), fields.PasswordResetAt())
+	if err != nil {
+		logger.Ctx(ctx).Err(err).Msg("error update user password")
+		return errors.Internal(err)
+	}
+
+	sessionIds, err := s.tokenStore.DeleteUserRefreshTokens(ctx, fmt.Sprintf("%d", user.UserId), sessionId)
+	if err == nil {
+		err = s.tokenRevoker.RevokeSessions(ctx, sessionIds...)
+	}
+	if err != nil {
+		logger.Ctx(ctx).Err(err).Msg("error revoke user sessions")
+		return errors.Internal(err)
+	}
+
+	return nil
+}
+
+// rehashPassword upgrade the stored hash to the current algorithm and parameters, the plain password
+// is only known on login. a failure is only logged, the user can still login with the old hash
+func (s UserServiceImpl) rehashPassword(ctx context.Context, user *entities.Users, plainPassword string) {
+	if !s.passwordHasher.NeedsRehash(user.Password) {
+		return
+	}
+
+	hashedPassword, err := s.passwordHasher.Hash(plainPassword)
+	if err != nil {
+		logger.Ctx(ctx).Err(err).Msg("error rehash user password")
+		return
+	}
+
+	user.Password = hashedPassword
+	err = s.userRepository.UpdateUsers(ctx, user, user.UserId, repositories.NewUsersSelectFields().Password())
+	if err != nil {
+		logger.Ctx(ctx).Err(err).Msg("error update rehashed user password")
+	}
+}
+
+func loginAttemptAccountKey(email string) string {
+	return "account:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+func loginAttemptIpKey(ip string) string {
+	return "ip:" + ip
+}
+
+// checkLoginAttempt reject the login when the account is still locked or the ip has used its budget of failures
+func (s UserServiceImpl) checkLoginAttempt(ctx context.Context, accountKey, ipKey string) error {
+	cfg := config.Get().Auth.LoginAttempt
+
+	locked, err := s.loginAttemptRepository.GetLoginLock(ctx, accountKey)
+	if err != nil {
+		logger.Ctx(ctx).Err(err).Msg("error get login lock")
+		return errors.InternalServerError("failed to check login attempt")
+	}
+	if locked > 0 {
+		return ErrLoginLocked.WithRetryAfter(locked)
+	}
+
+	if cfg.IpMaxAttempts <= 0 {
+		return nil
+	}
+
+	failures, ttl, err := s.loginAttemptRepository.GetLoginAttempt(ctx, ipKey)
+	if err != nil {
+		logger.Ctx(ctx).Err(err).Msg("error get login attempt")
+		return errors.InternalServerError("failed to check login attempt")
+	}
+	if failures >= cfg.IpMaxAttempts {
+		return ErrLoginRateLimited.WithRetryAfter(ttl)
+	}
+
+	return nil
+}
+
+// recordLoginFailure count the failure to the budget of the ip, then lock the account for a delay that doubles
+// on every failure and lock it for the whole lockout once it reach the max failures. it return loginErr back
+func (s UserServiceImpl) recordLoginFailure(ctx context.Context, accountKey, ipKey string, loginErr error) error {
+	cfg := config.Get().Auth.LoginAttempt
+	if cfg.IpMaxAttempts > 0 {
+		if _, err := s.loginAttemptRepository.IncrLoginAttempt(ctx, ipKey, cfg.IpWindow); err != nil {
+			logger.Ctx(ctx).Err(err).Msg("error count ip login failure")
+		}
+	}
+	if cfg.MaxFailures <= 0 {
+		return loginErr
+	}
+
+	failures, err := s.loginAttemptRepository.IncrLoginAttempt(ctx, accountKey, cfg.FailureWindow)
+	if err != nil {
+		logger.Ctx(ctx).Err(err).Msg("error count login failure")
+		return loginErr
+	}
+
+	lock := cfg.Lockout
+	if failures < cfg.MaxFailures {
+		lock = progressiveDelay(cfg.Delay, cfg.MaxDelay, failures)
+	}
+	if lock <= 0 {
+		return loginErr
+	}
+
+	if err := s.loginAttemptRepository.LockLoginAttempt(ctx, accountKey, lock); err != nil {
+		logger.Ctx(ctx).Err(err).Msg("error lock login attempt")
+	}
+
+	return loginErr
+}
+
+// progressiveDelay return delay * 2^(failures-1) capped to maxDelay
+func progressiveDelay(delay, maxDelay time.Duration, failures int64) time.Duration {
+	for i := int64(1); i < failures && delay < maxDelay; i++ {
+		delay *= 2
+	}
+	if maxDelay > 0 && delay > maxDelay {
+		return maxDelay
+	}
+	return delay
+}
+
+// unixMilli return the current time in millisecond, the same unit as created_at and updated_at
+func unixMilli() int64 {
+	return time.Now().UnixNano() / int64(time.Millisecond)
+}
@@ -0,0 +1,135 @@
+package services
+
+import (
+	"context"
+	"golang-starter/internal/logger"
+	"golang-starter/internal/protocols/http/errors"
+	"golang-starter/internal/utils/auth"
+	"golang-starter/internal/utils/tracing"
+	"golang-starter/src/modules/user/dto"
+	"golang-starter/src/modules/user/repositories"
+)
+
+type UserTokenService interface {
+	// IntrospectToken tell whether token is active, an invalid token isn't an error but an inactive token
+	IntrospectToken(ctx context.Context, token, tokenTypeHint string) (*dto.TokenIntrospectRespBody, error)
+	// RevokeToken revoke token, an invalid token isn't an error since it can't be used anyway.
+	// revoking a refresh token end its session, hence the access tokens of the session are revoked as well
+	RevokeToken(ctx context.Context, token, tokenTypeHint string) error
+}
+
+type UserTokenServiceImpl struct {
+	userRepository repositories.Repositories
+	tokenVerifier  auth.TokenVerifier
+	tokenStore     auth.TokenStore
+	jwtAuth        auth.JwtToken
+}
+
+func NewUserTokenService(
+	userRepository repositories.Repositories,
+	tokenVerifier auth.TokenVerifier,
+	tokenStore auth.TokenStore,
+	jwtAuth auth.JwtToken,
+) *UserTokenServiceImpl {
+	return &UserTokenServiceImpl{
+		userRepository: userRepository,
+		tokenVerifier:  tokenVerifier,
+		tokenStore:     tokenStore,
+		jwtAuth:        jwtAuth,
+	}
+}
+
+func (s UserTokenServiceImpl) IntrospectToken(ctx context.Context, token, tokenTypeHint string) (*dto.TokenIntrospectRespBody, error) {
+	ctx, span := tracing.Start(ctx, "UserTokenService.IntrospectToken")
+	defer span.End()
+
+	inactive := &dto.TokenIntrospectRespBody{Active: false}
+
+	claims, err := s.verify(ctx, token, tokenTypeHint)
+	if err != nil {
+		return nil, err
+	}
+	if claims == nil {
+		return inactive, nil
+	}
+
+	// a refresh token is only active while it's the one stored for its session, a refresh replace it
+	if claims.TokenType == auth.TokenTypeRefresh {
+		active, err := s.jwtAuth.RefreshTokenActive(ctx, claims.Subject, claims.SessionID, claims.ID)
+		if err != nil {
+			logger.Ctx(ctx).Err(err).Msg("error find refresh token")
+			return nil, errors.Internal(err)
+		}
+		if !active {
+			return inactive, nil
+		}
+	}
+
+	user, err := s.userRepository.
+		FilterUsers(repositories.NewUsersFilter("AND").SetFilterByUserId(claims.Subject, "=")).
+		GetUsers(ctx)
+	if err != nil || user.DisabledAt.Valid {
+		return inactive, nil
+	}
+
+	resp := dto.CreateTokenIntrospectResp(claims, *user)
+	return &resp, nil
+}
+
+func (s UserTokenServiceImpl) RevokeToken(ctx context.Context, token, tokenTypeHint string) error {
+	ctx, span := tracing.Start(ctx, "UserTokenService.RevokeToken")
+	defer span.End()
+
+	claims, err := s.verify(ctx, token, tokenTypeHint)
+	if err != nil {
+		return err
+	}
+	if claims == nil {
+		return nil
+	}
+
+	if err := s.tokenVerifier.RevokeToken(ctx, claims); err != nil {
+		logger.Ctx(ctx).Err(err).Msg("error revoke token")
+		return errors.Internal(err)
+	}
+
+	if claims.TokenType == auth.TokenTypeRefresh {
+		if err := s.tokenStore.DeleteRefreshToken(ctx, claims.Subject, claims.SessionID); err != nil {
+			logger.Ctx(ctx).Err(err).Msg("error delete refresh token")
+			return errors.Internal(err)
+		}
+		if err := s.tokenVerifier.RevokeSessions(ctx, claims.SessionID); err != nil {
+			logger.Ctx(ctx).Err(err).Msg("error revoke session")
+			return errors.Internal(err)
+		}
+	}
+
+	return nil
+}
+
+// verify try the hinted token type first, as RFC 7009 and RFC 7662 suggest. the claims are nil
+// when the token isn't valid, an error is only returned when the token can't be checked
+func (s UserTokenServiceImpl) verify(ctx context.Context, token, tokenTypeHint string) (*auth.Claims, error) {
+	tokenTypes := []string{auth.TokenTypeAccess, auth.TokenTypeRefresh}
+	if tokenTypeHint == auth.TokenTypeRefresh {
+		tokenTypes = []string{auth.TokenTypeRefresh, auth.TokenTypeAccess}
+	}
+
+	for _, tokenType := range tokenTypes {
+		claims, err := s.tokenVerifier.Verify(ctx, token, tokenType)
+		if err == nil {
+			return claims, nil
+		}
+		if err == auth.ErrTokenType {
+			continue
+		}
+		if _, ok := auth.TokenError(err); ok {
+			return nil, nil
+		}
+
+		logger.Ctx(ctx).Err(err).Msg("error verify token")
+		return nil, errors.Internal(err)
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,62 @@
+package services
+
+import (
+	"context"
+	"golang-starter/internal/utils/auth"
+	authdto "golang-starter/internal/utils/auth/dto"
+	"golang-starter/src/modules/user/entities"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// refreshVerifier accept the refresh tokens named after their jti
+type refreshVerifier struct{}
+
+func (refreshVerifier) Verify(_ context.Context, tokenString, tokenType string) (*auth.Claims, error) {
+	if tokenType != auth.TokenTypeRefresh {
+		return nil, auth.ErrTokenType
+	}
+	return &auth.Claims{Subject: "1", ID: tokenString, SessionID: "session", TokenType: tokenType}, nil
+}
+
+func (refreshVerifier) RevokeToken(context.Context, *auth.Claims) error { return nil }
+
+func (refreshVerifier) RevokeSessions(context.Context, ...string) error { return nil }
+
+// rotatedJwt is a session whose refresh token was rotated to storedTokenId
+type rotatedJwt struct {
+	storedTokenId string
+}
+
+func (rotatedJwt) Sign(context.Context, auth.Claims) (authdto.Token, error) {
+	return authdto.Token{}, nil
+}
+
+func (j rotatedJwt) VerifyRefreshToken(_ context.Context, _, _, tokenId string) error {
+	if tokenId != j.storedTokenId {
+		return auth.ErrRefreshTokenReused
+	}
+	return nil
+}
+
+func (j rotatedJwt) RefreshTokenActive(_ context.Context, _, _, tokenId string) (bool, error) {
+	return tokenId == j.storedTokenId, nil
+}
+
+func TestIntrospectRotatedRefreshToken(t *testing.T) {
+	s := NewUserTokenService(fakeRepository{user: &entities.Users{UserId: 1, Username: "john", Role: entities.RoleUser}},
+		refreshVerifier{}, nil, rotatedJwt{storedTokenId: "second"})
+
+	resp, err := s.IntrospectToken(context.Background(), "second", auth.TokenTypeRefresh)
+	require.NoError(t, err)
+	assert.True(t, resp.Active)
+	assert.Equal(t, "second", resp.Jti)
+
+	// the first refresh token of the session was exchanged for the second one
+	resp, err = s.IntrospectToken(context.Background(), "first", auth.TokenTypeRefresh)
+	require.NoError(t, err)
+	assert.False(t, resp.Active)
+	assert.Empty(t, resp.Sub)
+}
@@ -4,12 +4,19 @@
 package main
 
 import (
+	"golang-starter/infrastructures/cached"
 	"golang-starter/infrastructures/db"
 	"golang-starter/infrastructures/db/transaction"
 	"golang-starter/infrastructures/localdb"
 	"golang-starter/internal/protocols/http"
 	httprouter "golang-starter/internal/protocols/http/router"
 	jwtauth "golang-starter/internal/utils/auth"
+	"golang-starter/internal/utils/encryption"
+	"golang-starter/internal/utils/health"
+	"golang-starter/internal/utils/keyring"
+	"golang-starter/internal/utils/oidc"
+	"golang-starter/internal/utils/password"
+	"golang-starter/internal/utils/ratelimit"
 	httphandler "golang-starter/src/handlers/http"
 	productrepo "golang-starter/src/modules/product/repositories"
 	productsvc "golang-starter/src/modules/product/services"
@@ -26,6 +33,7 @@ import (
 
 // wiring jwt auth
 var jwtAuth = wire.NewSet(
+	jwtauth.NewTokenStore,
 	jwtauth.NewJwt,
 	wire.Bind(
 		new(jwtauth.JwtToken),
@@ -33,6 +41,26 @@ var jwtAuth = wire.NewSet(
 	),
 )
 
+// wiring token verification and revocation
+var tokenVerifier = wire.NewSet(
+	jwtauth.NewDenylist,
+	jwtauth.NewVerifier,
+	wire.Bind(
+		new(jwtauth.TokenVerifier),
+		new(*jwtauth.Verifier),
+	),
+	wire.Bind(
+		new(jwtauth.TokenRevoker),
+		new(*jwtauth.Verifier),
+	),
+)
+
+// wiring rate limit
+var rateLimiter = wire.NewSet(
+	ratelimit.NewStore,
+	ratelimit.NewLimiter,
+)
+
 // Wiring for domain
 
 // product
@@ -60,12 +88,13 @@ var userMysqlRepo = wire.NewSet(
 		new(*userrepo.RepositoriesImpl),
 	),
 )
-var userScribleRepo = wire.NewSet(
-	userrepo.NewUserScribleRepository,
-	wire.Bind(
-		new(userrepo.UserScribleRepository),
-		new(*userrepo.UserScribleRepositoryImpl),
-	),
+
+var loginAttemptRepo = wire.NewSet(
+	userrepo.NewLoginAttemptRepository,
+)
+
+var oidcStateRepo = wire.NewSet(
+	userrepo.NewOidcStateRepository,
 )
 
 var userSvc = wire.NewSet(
@@ -76,6 +105,38 @@ var userSvc = wire.NewSet(
 	),
 )
 
+var userAdminSvc = wire.NewSet(
+	usersvc.NewUserAdminService,
+	wire.Bind(
+		new(usersvc.UserAdminService),
+		new(*usersvc.UserAdminServiceImpl),
+	),
+)
+
+var userOidcSvc = wire.NewSet(
+	usersvc.NewUserOidcService,
+	wire.Bind(
+		new(usersvc.UserOidcService),
+		new(*usersvc.UserOidcServiceImpl),
+	),
+)
+
+var userTokenSvc = wire.NewSet(
+	usersvc.NewUserTokenService,
+	wire.Bind(
+		new(usersvc.UserTokenService),
+		new(*usersvc.UserTokenServiceImpl),
+	),
+)
+
+var userApiKeySvc = wire.NewSet(
+	usersvc.NewUserApiKeyService,
+	wire.Bind(
+		new(usersvc.UserApiKeyService),
+		new(*usersvc.UserApiKeyServiceImpl),
+	),
+)
+
 // Wiring for http protocol
 var httpHandler = wire.NewSet(
 	httphandler.NewHttpHandler,
@@ -90,15 +151,30 @@ func InitHttpProtocol() *http.HttpImpl {
 	wire.Build(
 		db.NewMysqlClient,
 		localdb.NewScribleClient,
+		cached.NewRedisClient,
 		transaction.NewTransaction,
 		productRepo,
 		userMysqlRepo,
-		userScribleRepo,
+		loginAttemptRepo,
+		oidcStateRepo,
+		keyring.NewProvider,
+		encryption.NewEncrypter,
+		encryption.NewBlindIndexer,
+		jwtauth.NewSigner,
 		jwtAuth,
+		tokenVerifier,
+		oidc.NewRegistry,
+		password.NewHasher,
+		rateLimiter,
 		productSvc,
 		userSvc,
+		userAdminSvc,
+		userOidcSvc,
+		userApiKeySvc,
+		userTokenSvc,
 		httpHandler,
 		httpRouter,
+		health.NewDependencyChecker,
 		http.NewHttpProtocol,
 	)
 	return &http.HttpImpl{}